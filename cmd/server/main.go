@@ -1,16 +1,17 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"github.com/gin-gonic/gin"
-	"net/http"
 	"os"
+	"os/signal"
 	"strings"
-	"time"
+	"syscall"
 
+	"github.com/mammadmodi/detective/internal/app"
 	"github.com/mammadmodi/detective/internal/config"
 	"github.com/mammadmodi/detective/internal/handler"
-	"github.com/mammadmodi/detective/pkg/htmlanalysis"
 	"github.com/mammadmodi/detective/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -36,68 +37,59 @@ var (
 	BuildDate     string
 )
 
-var c *config.AppConfig
-var l *zap.Logger
-var hc *http.Client
-var h *handler.HTTPHandler
-var r *gin.Engine
+func main() {
+	fmt.Println(
+		strings.NewReplacer(
+			"__commit_ref_name__", CommitRefName,
+			"__commit_sha__", CommitSHA,
+			"__build_date__", BuildDate,
+		).Replace(AsciiArt))
 
-func init() {
-	var err error
-	// Initialize application configuration.
-	c, err = config.NewAppConfig()
+	// Initialize application configuration, the environment variables override the values of the config file.
+	configFile := flag.String("config", os.Getenv("DETECTIVE_CONFIG_FILE"), "path of a yaml or json config file")
+	flag.Parse()
+	c, err := config.Load(*configFile)
 	if err != nil {
 		panic(err)
 	}
 
-	// Initialize application logger.
-	l, err = logger.NewZapLogger("detective", c.LoggerConfig)
+	// Initialize application logger, its level can be changed by reloading the configuration.
+	l, level, err := logger.NewLeveledZapLogger("detective", c.LoggerConfig)
 	if err != nil {
 		panic(err)
 	}
 
-	// Initialize application HTTP client.
-	// We should reduce the IdleConnTimeout because the requests that are being performed
-	// by this HTTPClient target different hosts and there is no meaning to have an idle connection
-	// for a long time.
-	hc = &http.Client{
-		Timeout: c.HTTPTimeout,
-		Transport: &http.Transport{
-			IdleConnTimeout: 5 * time.Second,
-		},
-	}
-
-	h = &handler.HTTPHandler{
-		HTTPClient:      hc,
-		Logger:          l.Named("http_handler"),
-		HTMLAnalyzeFunc: htmlanalysis.Analyze,
+	a, err := app.New(c, l, handler.BuildInfo{
+		CommitSHA:     CommitSHA,
+		CommitRefName: CommitRefName,
+		BuildDate:     BuildDate,
+	})
+	if err != nil {
+		l.With(zap.Error(err)).Panic("error while initializing application")
 	}
-
-	// Setup package level dependencies.
-	hcClone := *hc
-	htmlanalysis.SetGlobalLogger(l.Named("html_analyzer"))
-	htmlanalysis.SetGlobalHTTPClient(&hcClone)
-
-	// Create application router.
-	r = gin.New()
-	r.Static("/", "./web/static/")
-	r.POST("/analyze-url", h.AnalyzeURL)
-
-	l.With(zap.Any("configs", c)).Info("application initialized successfully")
-}
-
-func main() {
-	fmt.Println(
-		strings.NewReplacer(
-			"__commit_ref_name__", CommitRefName,
-			"__commit_sha__", CommitSHA,
-			"__build_date__", BuildDate,
-		).Replace(AsciiArt))
-
-	// Launch server and listen to application port.
-	if err := http.ListenAndServe(c.Addr, r); err != nil && err != http.ErrServerClosed {
-		l.With(zap.Error(err)).Panic("error while running gin http server")
+	// The secrets of the configs are redacted because the logs may be shipped to other systems.
+	l.With(zap.Any("configs", c.Redacted())).Info("application initialized successfully")
+
+	// The configuration is reloaded on SIGHUP, an invalid configuration is logged and ignored.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			nc, err := config.Load(*configFile)
+			if err != nil {
+				l.With(zap.Error(err)).Error("error while reloading configuration")
+				continue
+			}
+			a.Reload(nc, level)
+		}
+	}()
+
+	// The application is shut down gracefully on SIGINT and SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := a.Run(ctx); err != nil {
+		l.With(zap.Error(err)).Panic("error while running application")
 	}
-
-	os.Exit(0)
+	l.Info("application stopped")
 }
@@ -0,0 +1,79 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSaturated is returned when an analysis can't be admitted because all the slots are taken and either the
+// waiting queue is full or the wait timed out.
+var ErrSaturated = errors.New("server is saturated")
+
+// Controller limits the number of analyses which run at the same time across all the requests.
+// The analyses which can't get a slot wait in a bounded queue for at most the max wait time.
+// It's safe for concurrent use.
+type Controller struct {
+	slots     chan struct{}
+	maxQueued int64
+	maxWait   time.Duration
+	waiting   int64
+}
+
+// NewController creates a Controller which runs at most maxConcurrent analyses and keeps at most maxQueued analyses
+// waiting for maxWait. A Controller with maxConcurrent < 1 admits all the analyses.
+func NewController(maxConcurrent, maxQueued int, maxWait time.Duration) *Controller {
+	c := &Controller{maxQueued: int64(maxQueued), maxWait: maxWait}
+	if maxConcurrent > 0 {
+		c.slots = make(chan struct{}, maxConcurrent)
+	}
+	return c
+}
+
+// Acquire takes a slot for an analysis and returns the function which releases it.
+// It returns ErrSaturated when the analysis is rejected and the error of ctx when ctx gets done while waiting.
+func (c *Controller) Acquire(ctx context.Context) (release func(), err error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+	release = func() { <-c.slots }
+
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if atomic.AddInt64(&c.waiting, 1) > c.maxQueued {
+		atomic.AddInt64(&c.waiting, -1)
+		return nil, ErrSaturated
+	}
+	defer atomic.AddInt64(&c.waiting, -1)
+
+	timer := time.NewTimer(c.maxWait)
+	defer timer.Stop()
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrSaturated
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Saturated reports whether all the slots are taken and the waiting queue is full.
+func (c *Controller) Saturated() bool {
+	return c.slots != nil && len(c.slots) == cap(c.slots) && atomic.LoadInt64(&c.waiting) >= c.maxQueued
+}
+
+// Running returns the number of analyses which hold a slot.
+func (c *Controller) Running() int {
+	return len(c.slots)
+}
+
+// Waiting returns the number of analyses which wait for a slot.
+func (c *Controller) Waiting() int {
+	return int(atomic.LoadInt64(&c.waiting))
+}
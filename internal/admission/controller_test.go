@@ -0,0 +1,69 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestController_Acquire(t *testing.T) {
+	c := NewController(1, 1, time.Second)
+	release, err := c.Acquire(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, c.Running())
+	assert.False(t, c.Saturated())
+
+	// The second analysis waits in the queue until the first one releases its slot.
+	acquired := make(chan error, 1)
+	go func() {
+		r, err := c.Acquire(context.Background())
+		if err == nil {
+			r()
+		}
+		acquired <- err
+	}()
+	for c.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, c.Saturated())
+
+	// The queue is full so the third analysis is rejected immediately.
+	_, err = c.Acquire(context.Background())
+	assert.True(t, errors.Is(err, ErrSaturated))
+
+	release()
+	assert.NoError(t, <-acquired)
+	assert.Equal(t, 0, c.Running())
+	assert.Equal(t, 0, c.Waiting())
+}
+
+func TestController_AcquireTimeout(t *testing.T) {
+	c := NewController(1, 1, 10*time.Millisecond)
+	release, _ := c.Acquire(context.Background())
+	defer release()
+
+	_, err := c.Acquire(context.Background())
+	assert.True(t, errors.Is(err, ErrSaturated))
+
+	c = NewController(1, 1, time.Minute)
+	release, _ = c.Acquire(context.Background())
+	defer release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Acquire(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestController_Unlimited(t *testing.T) {
+	c := NewController(0, 0, 0)
+	for i := 0; i < 10; i++ {
+		_, err := c.Acquire(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.False(t, c.Saturated())
+}
@@ -0,0 +1,444 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/config"
+	"github.com/mammadmodi/detective/internal/grpcserver"
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/internal/job"
+	"github.com/mammadmodi/detective/internal/metrics"
+	"github.com/mammadmodi/detective/internal/monitor"
+	"github.com/mammadmodi/detective/internal/openapi"
+	"github.com/mammadmodi/detective/internal/tracing"
+	"github.com/mammadmodi/detective/internal/webhook"
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/logger"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// tracerShutdownTimeout is the time which the tracer provider gets to export the remaining spans on shutdown.
+const tracerShutdownTimeout = 5 * time.Second
+
+// App holds the dependencies of the application and serves its http endpoints.
+type App struct {
+	Config  *config.AppConfig
+	Logger  *zap.Logger
+	Handler *handler.HTTPHandler
+	Router  *gin.Engine
+
+	jobManager       *job.Manager
+	historyStore     *history.BoltStore
+	monitorScheduler *monitor.Scheduler
+	notifier         *webhook.Notifier
+	tracerProvider   *sdktrace.TracerProvider
+	grpcServer       *grpc.Server
+}
+
+// New wires the dependencies of the application based on c, the build information is returned by the version
+// endpoint. The background workers of the application are not started until Serve is called.
+func New(c *config.AppConfig, l *zap.Logger, buildInfo handler.BuildInfo) (*App, error) {
+	a := &App{Config: c, Logger: l}
+
+	// Initialize the tracer provider, spans are only exported when an exporter is configured.
+	exporter, err := tracing.NewExporter(
+		context.Background(),
+		c.TracingConfig.Exporter,
+		c.TracingConfig.Endpoint,
+		c.TracingConfig.Insecure,
+		os.Stdout,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating span exporter: %w", err)
+	}
+	if exporter != nil {
+		a.tracerProvider = tracing.NewTracerProvider(exporter, c.TracingConfig.ServiceName, buildInfo.CommitRefName, c.TracingConfig.SampleRatio)
+	}
+
+	// Initialize application HTTP client which goes through the proxies and trusts the CAs of the egress config.
+	// The transport is instrumented to create a client span for each outgoing request.
+	transport, err := newTransport(c.EgressConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating http transport: %w", err)
+	}
+	if c.EgressConfig.InsecureSkipVerify {
+		l.Warn("verification of the certificates of the fetched pages and the checked links is disabled")
+	}
+	hc := &http.Client{
+		Timeout:   c.HTTPTimeout,
+		Transport: otelhttp.NewTransport(transport),
+	}
+
+	h := &handler.HTTPHandler{
+		HTTPClient: hc,
+		Logger:     l.Named("http_handler"),
+		CrawlOptions: crawler.Options{
+			MaxDepth:    c.CrawlConfig.MaxDepth,
+			MaxPages:    c.CrawlConfig.MaxPages,
+			Concurrency: c.CrawlConfig.Concurrency,
+		},
+		SitemapOptions: sitemap.Options{
+			MaxURLs:     c.SitemapConfig.MaxUrls,
+			Concurrency: c.SitemapConfig.Concurrency,
+		},
+		BuildInfo: buildInfo,
+		Admission: admission.NewController(
+			c.AdmissionConfig.MaxConcurrentAnalyses,
+			c.AdmissionConfig.MaxQueuedAnalyses,
+			c.AdmissionConfig.MaxQueueWait,
+		),
+		RetryAfter:      c.AdmissionConfig.RetryAfter,
+		MaxDocumentSize: c.MaxDocumentSize,
+	}
+	a.Handler = h
+
+	// Initialize the validator of the versioned API.
+	h.Validator, err = openapi.NewValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the keyring of API keys.
+	if c.AuthConfig.Enabled {
+		h.Keyring, err = newKeyring(c.AuthConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Load the rules which the analyzed pages are evaluated against.
+	if c.RulesFile != "" {
+		h.Rules, err = rules.Load(c.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize robots.txt checker which is shared by page fetcher and link checker.
+	if c.RobotsConfig.Enabled {
+		h.RobotsChecker = robots.NewChecker(hc, c.RobotsConfig.UserAgent, c.RobotsConfig.CacheTTL, l.Named("robots"))
+	}
+
+	// Initialize prometheus metrics.
+	var mt *metrics.Metrics
+	if c.MetricsConfig.Enabled {
+		mt = metrics.New()
+		mt.RegisterRobotsChecker(h.RobotsChecker)
+		h.Metrics = mt
+	}
+
+	// Initialize the webhook notifier, the deliveries use a separate HTTP client with a shorter timeout.
+	if len(c.WebhookConfig.Urls) > 0 {
+		events := make([]webhook.EventType, 0, len(c.WebhookConfig.Events))
+		for _, e := range c.WebhookConfig.Events {
+			events = append(events, webhook.EventType(e))
+		}
+		targets := make([]webhook.Target, 0, len(c.WebhookConfig.Urls))
+		for _, u := range c.WebhookConfig.Urls {
+			targets = append(targets, webhook.Target{URL: u, Secret: c.WebhookConfig.Secret, Events: events})
+		}
+		a.notifier = webhook.NewNotifier(
+			targets,
+			&http.Client{Timeout: c.WebhookConfig.Timeout},
+			webhook.Options{MaxRetries: c.WebhookConfig.MaxRetries, Backoff: c.WebhookConfig.Backoff},
+			l.Named("webhook"),
+		)
+		h.Notifier = a.notifier
+	}
+
+	// Initialize the store of analysis history.
+	if c.HistoryConfig.Enabled {
+		a.historyStore, err = history.NewBoltStore(c.HistoryConfig.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error while opening history store: %w", err)
+		}
+		a.historyStore.SetRetention(history.Retention{
+			MaxAge:     c.HistoryConfig.MaxAge,
+			MaxRecords: c.HistoryConfig.MaxRecords,
+		})
+		h.HistoryStore = a.historyStore
+	}
+
+	// Initialize the manager of asynchronous analysis jobs.
+	a.jobManager = job.NewManager(c.JobWorkers, c.JobQueueSize, h.Analyze, l.Named("job_manager"))
+	a.jobManager.SetTTL(c.JobTTL)
+	a.jobManager.OnFinish(func(j *job.Job) { a.notifier.Notify(webhook.EventJobCompleted, j) })
+	h.JobManager = a.jobManager
+
+	// Initialize the scheduler of monitors.
+	if c.MonitorConfig.Enabled {
+		a.monitorScheduler = monitor.NewScheduler(
+			c.MonitorConfig.Concurrency,
+			c.MonitorConfig.MinInterval,
+			h.RecordAnalysis,
+			l.Named("monitor_scheduler"),
+		)
+		a.monitorScheduler.OnDegraded(func(m *monitor.Monitor) { a.notifier.Notify(webhook.EventMonitorDegraded, m) })
+		// The monitors are persisted in the history store, so they are restored after restarts.
+		if a.historyStore != nil {
+			if err := a.monitorScheduler.Load(a.historyStore); err != nil {
+				return nil, fmt.Errorf("error while loading monitors: %w", err)
+			}
+		}
+		h.MonitorScheduler = a.monitorScheduler
+	}
+
+	// Initialize the analyzer of html documents, its link probe limit is shared by all of the analyses and its clock
+	// is shared with the handler which evaluates the certificates of the fetched pages.
+	hcClone := *hc
+	h.Now = time.Now
+	analyzerOpts := []htmlanalysis.Option{
+		htmlanalysis.WithLogger(l.Named("html_analyzer")),
+		htmlanalysis.WithHTTPClient(&hcClone),
+		htmlanalysis.WithLinkProbeLimit(c.AdmissionConfig.MaxLinkProbes),
+		htmlanalysis.WithClock(h.Now),
+	}
+	if h.RobotsChecker != nil {
+		analyzerOpts = append(analyzerOpts, htmlanalysis.WithRobotsChecker(h.RobotsChecker))
+	}
+	h.HTMLAnalyzeFunc = htmlanalysis.NewAnalyzer(analyzerOpts...).Analyze
+
+	a.Router = newRouter(h, mt, a.tracerProvider != nil, c.TracingConfig.ServiceName)
+
+	// Initialize the gRPC server, its calls are traced like the http requests.
+	if c.GRPCConfig.Enabled {
+		var opts []grpc.ServerOption
+		if a.tracerProvider != nil {
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+				grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()),
+			)
+		}
+		a.grpcServer = grpcserver.New(h, l.Named("grpc_server"), opts...)
+	}
+	return a, nil
+}
+
+// newKeyring creates a Keyring with the API keys of c and the clients of its keys file.
+func newKeyring(c *config.AuthConfig) (*auth.Keyring, error) {
+	clients, err := auth.ParseClients(c.Keys)
+	if err != nil {
+		return nil, err
+	}
+	if c.KeysFile != "" {
+		fileClients, err := auth.LoadClients(c.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, fileClients...)
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("authentication is enabled but no api keys are configured")
+	}
+	return auth.NewKeyring(clients, authLimits(c))
+}
+
+// authLimits returns the default limits of the API key clients of c.
+func authLimits(c *config.AuthConfig) auth.Limits {
+	return auth.Limits{
+		RateLimit:  c.RateLimit,
+		Burst:      c.Burst,
+		DailyQuota: c.DailyQuota,
+	}
+}
+
+// Reload applies the settings of c which can be changed while the application is running, the log level of c is
+// applied to level and the default limits of c to the API key clients. The other settings take effect after a
+// restart, so a warning is logged when they differ from the settings which the application was started with.
+func (a *App) Reload(c *config.AppConfig, level zap.AtomicLevel) {
+	logger.SetLevel(level, c.LoggerConfig.Level)
+	if a.Handler.Keyring != nil {
+		a.Handler.Keyring.SetDefaults(authLimits(c.AuthConfig))
+	}
+	if requiresRestart(a.Config, c) {
+		a.Logger.Warn("configuration changes other than the log level and the rate limits take effect after a restart")
+	}
+	a.Logger.With(zap.String("log_level", c.LoggerConfig.Level)).Info("configuration reloaded")
+}
+
+// requiresRestart returns true if c differs from running in the settings which are not applied by Reload.
+func requiresRestart(running, c *config.AppConfig) bool {
+	r, n := *running, *c
+	rl, nl := *r.LoggerConfig, *n.LoggerConfig
+	nl.Level = rl.Level
+	ra, na := *r.AuthConfig, *n.AuthConfig
+	na.RateLimit, na.Burst, na.DailyQuota = ra.RateLimit, ra.Burst, ra.DailyQuota
+	r.LoggerConfig, r.AuthConfig = &rl, &ra
+	n.LoggerConfig, n.AuthConfig = &nl, &na
+	return !reflect.DeepEqual(&r, &n)
+}
+
+// newRouter creates the router of the application.
+// Static files are served on the NoRoute handler because a catch-all route on "/" conflicts with other GET routes.
+// The API routes are served under the versioned base path and their requests are validated against the OpenAPI
+// document, the unversioned routes are kept as deprecated aliases which behave as before.
+// The API routes require an API key when authentication is enabled and the routes which run analyses are charged
+// from the daily quota of the client after they are admitted by the admission controller.
+func newRouter(h *handler.HTTPHandler, mt *metrics.Metrics, tracingEnabled bool, serviceName string) *gin.Engine {
+	r := gin.New()
+	if tracingEnabled {
+		r.Use(otelgin.Middleware(serviceName))
+	}
+	if mt != nil {
+		r.Use(mt.Middleware())
+		r.GET("/metrics", gin.WrapH(mt.Handler()))
+	}
+	r.NoRoute(gin.WrapH(http.FileServer(http.Dir("./web/static/"))))
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/version", h.Version)
+	r.GET(openapi.BasePath+"/openapi.json", h.OpenAPIDocument)
+
+	registerAPIRoutes(r.Group(openapi.BasePath, h.Authenticate, h.ValidateRequest), h)
+	registerAPIRoutes(r.Group("/", h.Deprecate, h.Authenticate), h)
+	return r
+}
+
+// registerAPIRoutes registers the routes of the API on api.
+func registerAPIRoutes(api *gin.RouterGroup, h *handler.HTTPHandler) {
+	api.POST("/analyze-url", h.Admit, h.ChargeQuota, h.AnalyzeURL)
+	api.GET("/analyze-url/stream", h.Admit, h.ChargeQuota, h.AnalyzeURLStream)
+	api.POST("/crawl", h.Admit, h.ChargeQuota, h.CrawlURL)
+	api.POST("/sitemap", h.Admit, h.ChargeQuota, h.AuditSitemap)
+	api.POST("/jobs", h.Admit, h.ChargeQuota, h.CreateJob)
+	api.GET("/jobs/:id", h.GetJob)
+	api.DELETE("/jobs/:id", h.DeleteJob)
+	api.GET("/history", h.GetHistory)
+	api.GET("/analyses/:id", h.GetAnalysis)
+	api.POST("/diff", h.Admit, h.ChargeQuota, h.DiffAnalyses)
+	api.POST("/monitors", h.ChargeQuota, h.CreateMonitor)
+	api.GET("/monitors", h.ListMonitors)
+	api.GET("/monitors/:id", h.GetMonitor)
+	api.PUT("/monitors/:id", h.UpdateMonitor)
+	api.DELETE("/monitors/:id", h.DeleteMonitor)
+	api.POST("/monitors/:id/baseline", h.ResetMonitorBaseline)
+}
+
+// Run listens on the configured addresses and serves the application until ctx is done.
+func (a *App) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", a.Config.Addr)
+	if err != nil {
+		return fmt.Errorf("error while listening on %s: %w", a.Config.Addr, err)
+	}
+	var grpcLn net.Listener
+	if a.grpcServer != nil {
+		grpcLn, err = net.Listen("tcp", a.Config.GRPCConfig.Addr)
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("error while listening on %s: %w", a.Config.GRPCConfig.Addr, err)
+		}
+	}
+	return a.Serve(ctx, ln, grpcLn)
+}
+
+// Serve starts the background workers of the application and serves its http endpoints on ln and its gRPC service
+// on grpcLn until ctx is done, grpcLn is ignored when the gRPC service is disabled.
+// Then it stops accepting new connections and gives the in-flight requests, calls and jobs the configured shutdown
+// timeout to finish, the contexts of the analyses which are still running after that are canceled.
+// The application must not be used after Serve returns.
+func (a *App) Serve(ctx context.Context, ln, grpcLn net.Listener) error {
+	// The contexts of requests are derived from baseCtx, so canceling it cancels their analyses.
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	srv := &http.Server{
+		Handler:     a.Router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	a.jobManager.Start()
+	if a.monitorScheduler != nil {
+		a.monitorScheduler.Start()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+	grpcServeErr := make(chan error, 1)
+	if a.grpcServer != nil {
+		go func() { grpcServeErr <- a.grpcServer.Serve(grpcLn) }()
+	}
+
+	var err error
+	select {
+	case err = <-serveErr:
+		a.Logger.With(zap.Error(err)).Error("error while running http server")
+	case err = <-grpcServeErr:
+		a.Logger.With(zap.Error(err)).Error("error while running grpc server")
+	case <-ctx.Done():
+		a.Logger.Info("shutting down the application")
+	}
+	a.Handler.SetShuttingDown()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), a.Config.ShutdownTimeout)
+	defer cancel()
+	if shutdownErr := srv.Shutdown(drainCtx); shutdownErr != nil {
+		a.Logger.With(zap.Error(shutdownErr)).Warn("in-flight requests didn't finish in time, canceling them")
+		cancelBase()
+		_ = srv.Close()
+	}
+	if a.grpcServer != nil {
+		a.stopGRPCServer(drainCtx)
+	}
+	if a.monitorScheduler != nil {
+		a.monitorScheduler.Stop()
+	}
+	if jobErr := a.jobManager.Shutdown(drainCtx); jobErr != nil {
+		a.Logger.With(zap.Error(jobErr)).Warn("jobs didn't finish in time, canceled the unfinished ones")
+	}
+	a.close()
+	return err
+}
+
+// stopGRPCServer stops the gRPC server gracefully, the calls which are still running when ctx is done are canceled.
+func (a *App) stopGRPCServer(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		a.Logger.Warn("in-flight grpc calls didn't finish in time, canceling them")
+		a.grpcServer.Stop()
+		<-stopped
+	}
+}
+
+// close flushes the pending webhook deliveries and spans and closes the history store.
+func (a *App) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.Config.WebhookConfig.Timeout)
+	a.notifier.Stop(ctx)
+	cancel()
+	if a.tracerProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+		if err := a.tracerProvider.Shutdown(ctx); err != nil {
+			a.Logger.With(zap.Error(err)).Error("error while shutting down tracer provider")
+		}
+		cancel()
+	}
+	if a.historyStore != nil {
+		if err := a.historyStore.Close(); err != nil {
+			a.Logger.With(zap.Error(err)).Error("error while closing history store")
+		}
+	}
+}
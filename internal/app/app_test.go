@@ -0,0 +1,362 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/config"
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/mammadmodi/detective/internal/openapi"
+	"github.com/mammadmodi/detective/pkg/logger"
+	detectivev1 "github.com/mammadmodi/detective/pkg/pb/detective/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+// newTestConfig creates an AppConfig which keeps the analysis history in a temporary directory.
+func newTestConfig(t *testing.T) *config.AppConfig {
+	return &config.AppConfig{
+		LoggerConfig:    &logger.Config{},
+		Addr:            "127.0.0.1:0",
+		HTTPTimeout:     5 * time.Second,
+		JobWorkers:      1,
+		JobQueueSize:    1,
+		JobTTL:          time.Hour,
+		ShutdownTimeout: 5 * time.Second,
+		CrawlConfig:     &config.CrawlConfig{MaxDepth: 1, MaxPages: 1, Concurrency: 1},
+		SitemapConfig:   &config.SitemapConfig{MaxUrls: 1, Concurrency: 1},
+		RobotsConfig:    &config.RobotsConfig{},
+		HistoryConfig:   &config.HistoryConfig{Enabled: true, Path: filepath.Join(t.TempDir(), "detective.db")},
+		MonitorConfig:   &config.MonitorConfig{Enabled: true, Concurrency: 1, MinInterval: time.Minute},
+		WebhookConfig:   &config.WebhookConfig{Timeout: time.Second},
+		MetricsConfig:   &config.MetricsConfig{Enabled: true},
+		TracingConfig:   &config.TracingConfig{Exporter: "none"},
+		AuthConfig:      &config.AuthConfig{RateLimit: 10, Burst: 10, DailyQuota: 10},
+		AdmissionConfig: &config.AdmissionConfig{MaxConcurrentAnalyses: 2, MaxQueuedAnalyses: 2, MaxQueueWait: time.Second},
+		GRPCConfig:      &config.GRPCConfig{Addr: "127.0.0.1:0"},
+		EgressConfig:    &config.EgressConfig{},
+	}
+}
+
+// serveTestApp creates an App based on c and serves it on a random port until the returned cancel func is called.
+// The error of Serve is sent to the returned channel.
+func serveTestApp(t *testing.T, c *config.AppConfig) (string, context.CancelFunc, <-chan error) {
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{})
+	if err != nil {
+		t.Fatalf("error while creating app: %v", err)
+	}
+	ln, err := net.Listen("tcp", c.Addr)
+	if err != nil {
+		t.Fatalf("error while listening: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Serve(ctx, ln, nil) }()
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+// postAnalyzeURL requests an analysis of target from the application at base.
+func postAnalyzeURL(base, target string) (*http.Response, error) {
+	body, _ := json.Marshal(&handler.URLRequest{URL: target})
+	return http.Post(base+"/api/v1/analyze-url", "application/json", bytes.NewReader(body))
+}
+
+func TestNew(t *testing.T) {
+	c := newTestConfig(t)
+	c.WebhookConfig.Urls = []string{"http://hooks.local"}
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{CommitSHA: "c0ffee"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.close()
+
+	assert.NotNil(t, a.Handler.HistoryStore)
+	assert.NotNil(t, a.Handler.MonitorScheduler)
+	assert.NotNil(t, a.Handler.Notifier)
+	assert.NotNil(t, a.Handler.Metrics)
+	assert.NotNil(t, a.Handler.JobManager)
+	assert.Nil(t, a.Handler.RobotsChecker)
+
+	for _, target := range []string{"/healthz", "/readyz", "/version", "/metrics", "/api/v1/openapi.json"} {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		a.Router.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code, target)
+	}
+}
+
+func TestNewVersionedRoutes(t *testing.T) {
+	a, err := New(newTestConfig(t), zap.NewNop(), handler.BuildInfo{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.close()
+
+	// Every versioned route must be described by the OpenAPI document and have a deprecated alias.
+	routes := make(map[string]bool)
+	for _, route := range a.Router.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	for _, route := range a.Router.Routes() {
+		if !strings.HasPrefix(route.Path, openapi.BasePath) || strings.HasSuffix(route.Path, "/openapi.json") {
+			continue
+		}
+		req, _ := http.NewRequest(route.Method, route.Path, nil)
+		err := a.Handler.Validator.Validate(req, route.Path, nil)
+		assert.False(t, errors.Is(err, openapi.ErrOperationNotFound), "%s %s", route.Method, route.Path)
+		assert.True(t, routes[route.Method+" "+strings.TrimPrefix(route.Path, openapi.BasePath)], "%s %s", route.Method, route.Path)
+	}
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(`{"url": 10}`))
+		req.Header.Set("Content-Type", "application/json")
+		a.Router.ServeHTTP(res, req)
+		return res
+	}
+	res := serve("/api/v1/jobs")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), `"error_code":"INVALID_REQUEST"`)
+	assert.Empty(t, res.Header().Get("Deprecation"))
+
+	res = serve("/jobs")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "true", res.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/jobs>; rel="successor-version"`, res.Header().Get("Link"))
+}
+
+func TestNewFailure(t *testing.T) {
+	c := newTestConfig(t)
+	c.TracingConfig.Exporter = "unknown"
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{})
+	assert.Nil(t, a)
+	assert.Error(t, err)
+
+	c = newTestConfig(t)
+	c.HistoryConfig.Path = t.TempDir()
+	a, err = New(c, zap.NewNop(), handler.BuildInfo{})
+	assert.Nil(t, a)
+	assert.Error(t, err)
+
+	c = newTestConfig(t)
+	c.AuthConfig.Enabled = true
+	a, err = New(c, zap.NewNop(), handler.BuildInfo{})
+	assert.Nil(t, a)
+	assert.Error(t, err)
+
+	c = newTestConfig(t)
+	c.RulesFile = filepath.Join(t.TempDir(), "rules.yaml")
+	a, err = New(c, zap.NewNop(), handler.BuildInfo{})
+	assert.Nil(t, a)
+	assert.Error(t, err)
+
+	c = newTestConfig(t)
+	c.EgressConfig.CAFile = filepath.Join(t.TempDir(), "ca.pem")
+	a, err = New(c, zap.NewNop(), handler.BuildInfo{})
+	assert.Nil(t, a)
+	assert.Error(t, err)
+}
+
+func TestNewWithAuth(t *testing.T) {
+	c := newTestConfig(t)
+	c.AuthConfig.Enabled = true
+	c.AuthConfig.Keys = []string{"team-a:key-a"}
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.close()
+
+	serve := func(target, key string) int {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if key != "" {
+			req.Header.Set(handler.HeaderAPIKey, key)
+		}
+		a.Router.ServeHTTP(res, req)
+		return res.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("/healthz", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve("/monitors", ""))
+	assert.Equal(t, http.StatusOK, serve("/monitors", "key-a"))
+}
+
+func TestNewGuardsJobsAndMonitors(t *testing.T) {
+	c := newTestConfig(t)
+	c.AuthConfig.Enabled = true
+	c.AuthConfig.Keys = []string{"team-a:key-a"}
+	c.AuthConfig.DailyQuota = 1
+	c.AdmissionConfig = &config.AdmissionConfig{MaxConcurrentAnalyses: 1, MaxQueueWait: time.Millisecond}
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.close()
+
+	serve := func(target, body string) int {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(handler.HeaderAPIKey, "key-a")
+		a.Router.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	// Jobs are not queued while the server is saturated, and the rejected job doesn't use the quota.
+	release, _ := a.Handler.Admission.Acquire(context.Background())
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/jobs", `{"url": "http://example.com"}`))
+	release()
+
+	// Monitors are charged from the quota.
+	monitor := `{"url": "http://example.com", "schedule": "1h"}`
+	assert.Equal(t, http.StatusCreated, serve("/monitors", monitor))
+	assert.Equal(t, http.StatusTooManyRequests, serve("/monitors", monitor))
+}
+
+func TestApp_Reload(t *testing.T) {
+	c := newTestConfig(t)
+	c.AuthConfig.Enabled = true
+	c.AuthConfig.Keys = []string{"team-a:key-a"}
+	core, logs := observer.New(zap.InfoLevel)
+	a, err := New(c, zap.New(core), handler.BuildInfo{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.close()
+
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	nc := *c
+	nc.LoggerConfig = &logger.Config{Level: "debug"}
+	nc.AuthConfig = &config.AuthConfig{Enabled: true, Keys: c.AuthConfig.Keys, RateLimit: 1, Burst: 1, DailyQuota: 7}
+	a.Reload(&nc, level)
+	assert.Equal(t, zap.DebugLevel, level.Level())
+	client, _ := a.Handler.Keyring.Authenticate("key-a")
+	assert.Equal(t, 7, client.DailyQuota)
+	assert.Equal(t, 0, logs.FilterMessageSnippet("after a restart").Len())
+
+	nc.Addr = ":9999"
+	a.Reload(&nc, level)
+	assert.Equal(t, 1, logs.FilterMessageSnippet("after a restart").Len())
+}
+
+func TestApp_ServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	page := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title>")
+	}))
+	defer page.Close()
+
+	base, cancel, done := serveTestApp(t, newTestConfig(t))
+	resCh := make(chan *http.Response, 1)
+	go func() {
+		res, err := postAnalyzeURL(base, page.URL)
+		assert.NoError(t, err)
+		resCh <- res
+	}()
+
+	<-started
+	cancel()
+	res := <-resCh
+	if assert.NotNil(t, res) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		_ = res.Body.Close()
+	}
+	assert.NoError(t, <-done)
+
+	_, err := http.Get(base + "/healthz")
+	assert.Error(t, err)
+}
+
+func TestApp_ServeCancelsAnalysesAfterDeadline(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	page := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer page.Close()
+
+	c := newTestConfig(t)
+	c.ShutdownTimeout = 50 * time.Millisecond
+	base, cancel, done := serveTestApp(t, c)
+	go func() {
+		res, err := postAnalyzeURL(base, page.URL)
+		if err == nil {
+			_ = res.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("app didn't stop after the shutdown timeout")
+	}
+	select {
+	case <-canceled:
+	case <-time.After(3 * time.Second):
+		t.Fatal("in-flight analysis has not been canceled")
+	}
+}
+
+func TestApp_ServeGRPC(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title>")
+	}))
+	defer page.Close()
+
+	c := newTestConfig(t)
+	c.GRPCConfig.Enabled = true
+	a, err := New(c, zap.NewNop(), handler.BuildInfo{})
+	if err != nil {
+		t.Fatalf("error while creating app: %v", err)
+	}
+	ln, err := net.Listen("tcp", c.Addr)
+	if err != nil {
+		t.Fatalf("error while listening: %v", err)
+	}
+	grpcLn, err := net.Listen("tcp", c.GRPCConfig.Addr)
+	if err != nil {
+		t.Fatalf("error while listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Serve(ctx, ln, grpcLn) }()
+
+	conn, err := grpc.Dial(grpcLn.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("error while dialing grpc server: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	res, err := detectivev1.NewAnalysisServiceClient(conn).AnalyzeURL(ctx, &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Detective", res.GetResult().GetPageTitle())
+		assert.NotEmpty(t, res.GetAnalysisId())
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
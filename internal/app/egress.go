@@ -0,0 +1,79 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newTransport creates the transport of the outgoing requests of the page fetcher and the link checker based on c.
+// We should reduce the IdleConnTimeout because the requests that are being performed by this transport target
+// different hosts and there is no meaning to have an idle connection for a long time.
+func newTransport(c *config.EgressConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Transport{
+		Proxy:           proxyFunc(c),
+		TLSClientConfig: tlsConfig,
+		// A custom TLS config disables HTTP/2 unless it's forced.
+		ForceAttemptHTTP2: true,
+		IdleConnTimeout:   5 * time.Second,
+	}, nil
+}
+
+// proxyFunc returns the proxy of the requests based on the proxies of c, the requests are not proxied when no proxy
+// is set. Like the NO_PROXY environment variable the requests to localhost are never proxied.
+func proxyFunc(c *config.EgressConfig) func(*http.Request) (*url.URL, error) {
+	if c.HTTPProxy == "" && c.HTTPSProxy == "" {
+		return nil
+	}
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  c.HTTPProxy,
+		HTTPSProxy: c.HTTPSProxy,
+		NoProxy:    strings.Join(c.NoProxy, ","),
+	}).ProxyFunc()
+	return func(r *http.Request) (*url.URL, error) {
+		return proxy(r.URL)
+	}
+}
+
+// newTLSConfig creates the TLS config of the outgoing requests, the root CAs of c are trusted besides the system
+// roots and the client certificate of c is presented to the servers which request it.
+func newTLSConfig(c *config.EgressConfig) (*tls.Config, error) {
+	tc := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("ca file has no PEM encoded certificates")
+		}
+		tc.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error while loading client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mammadmodi/detective/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// writePEMFile writes the PEM blocks of blockType and ders to a file and returns its path.
+func writePEMFile(t *testing.T, name, blockType string, ders ...[]byte) string {
+	var content []byte
+	for _, der := range ders {
+		content = append(content, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})...)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("error while writing pem file: %v", err)
+	}
+	return path
+}
+
+func TestProxyFunc(t *testing.T) {
+	assert.Nil(t, proxyFunc(&config.EgressConfig{}))
+
+	proxy := proxyFunc(&config.EgressConfig{
+		HTTPProxy:  "http://proxy.local:3128",
+		HTTPSProxy: "socks5://proxy.local:1080",
+		NoProxy:    []string{"internal.local", "10.0.0.0/8"},
+	})
+	tests := []struct {
+		url           string
+		expectedProxy string
+	}{
+		{url: "http://example.com", expectedProxy: "http://proxy.local:3128"},
+		{url: "https://example.com", expectedProxy: "socks5://proxy.local:1080"},
+		{url: "https://internal.local/a", expectedProxy: ""},
+		{url: "http://api.internal.local", expectedProxy: ""},
+		{url: "http://10.1.2.3", expectedProxy: ""},
+		{url: "http://localhost:8000", expectedProxy: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", test.url, nil)
+			u, err := proxy(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if test.expectedProxy == "" {
+				assert.Nil(t, u)
+				return
+			}
+			assert.Equal(t, test.expectedProxy, u.String())
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	var clientCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		clientCerts = len(req.TLS.PeerCertificates)
+		res.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+	serverCert := server.TLS.Certificates[0]
+
+	get := func(c *config.EgressConfig) error {
+		transport, err := newTransport(c)
+		if err != nil {
+			return err
+		}
+		res, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		return err
+	}
+
+	// The certificate of the test server is not trusted by default.
+	var uae x509.UnknownAuthorityError
+	assert.True(t, errors.As(get(&config.EgressConfig{}), &uae))
+
+	// It's trusted when its CA is in the CA file or when verification is skipped.
+	caFile := writePEMFile(t, "ca.pem", "CERTIFICATE", serverCert.Certificate[0])
+	assert.NoError(t, get(&config.EgressConfig{CAFile: caFile}))
+	assert.NoError(t, get(&config.EgressConfig{InsecureSkipVerify: true}))
+	assert.Equal(t, 0, clientCerts)
+
+	// The client certificate is presented to the servers which request it.
+	key, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	c := &config.EgressConfig{
+		CAFile:   caFile,
+		CertFile: caFile,
+		KeyFile:  writePEMFile(t, "client-key.pem", "PRIVATE KEY", key),
+	}
+	if assert.NoError(t, get(c)) {
+		assert.Equal(t, 1, clientCerts)
+	}
+}
+
+func TestNewTransportFailures(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := writePEMFile(t, "invalid.pem", "CERTIFICATE")
+
+	tests := []struct {
+		name          string
+		config        *config.EgressConfig
+		expectedError string
+	}{
+		{
+			name:          "missing ca file",
+			config:        &config.EgressConfig{CAFile: filepath.Join(dir, "ca.pem")},
+			expectedError: "error while reading ca file",
+		},
+		{
+			name:          "invalid ca file",
+			config:        &config.EgressConfig{CAFile: invalidFile},
+			expectedError: "ca file has no PEM encoded certificates",
+		},
+		{
+			name:          "invalid client certificate",
+			config:        &config.EgressConfig{CertFile: invalidFile, KeyFile: invalidFile},
+			expectedError: "error while loading client certificate",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport, err := newTransport(test.config)
+			assert.Nil(t, transport)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.expectedError)
+			}
+		})
+	}
+}
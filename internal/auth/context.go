@@ -0,0 +1,26 @@
+package auth
+
+import "context"
+
+// clientContextKey is the key of the authenticated client in contexts.
+type clientContextKey struct{}
+
+// NewContext returns a copy of ctx which holds the authenticated client c.
+func NewContext(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, c)
+}
+
+// FromContext returns the authenticated client of ctx, it returns nil when ctx has no client.
+func FromContext(ctx context.Context) *Client {
+	c, _ := ctx.Value(clientContextKey{}).(*Client)
+	return c
+}
+
+// Owner returns the name of the authenticated client of ctx which owns the jobs and analyses created with ctx,
+// it returns an empty string when ctx has no client.
+func Owner(ctx context.Context) string {
+	if c := FromContext(ctx); c != nil {
+		return c.Name
+	}
+	return ""
+}
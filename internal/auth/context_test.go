@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+	assert.Equal(t, "", Owner(ctx))
+
+	c := &Client{Name: "team-a", Key: "key-a"}
+	ctx = NewContext(ctx, c)
+	assert.Equal(t, c, FromContext(ctx))
+	assert.Equal(t, "team-a", Owner(ctx))
+}
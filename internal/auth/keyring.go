@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrInvalidKey is returned when an API key is missing or doesn't belong to any client.
+	ErrInvalidKey = errors.New("api key is missing or invalid")
+	// ErrRateLimited is returned when a client sends requests faster than its rate limit.
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrQuotaExceeded is returned when a client has used all of its analyses of the day.
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// Client is the owner of an API key.
+// RateLimit is the number of requests per second which the client may send with bursts of at most Burst requests
+// and DailyQuota is the number of analyses which the client may run in a UTC day, a negative DailyQuota means no
+// quota. Zero values are replaced by the default Limits of the Keyring.
+type Client struct {
+	Name       string  `json:"name"`
+	Key        string  `json:"key"`
+	RateLimit  float64 `json:"rate_limit"`
+	Burst      int     `json:"burst"`
+	DailyQuota int     `json:"daily_quota"`
+}
+
+// Limits holds the default limits of clients.
+type Limits struct {
+	RateLimit  float64
+	Burst      int
+	DailyQuota int
+}
+
+// ParseClients parses the list of clients from specs which are in the `name:key` format.
+func ParseClients(specs []string) ([]Client, error) {
+	clients := make([]Client, 0, len(specs))
+	for _, spec := range specs {
+		i := strings.Index(spec, ":")
+		if i < 1 || i == len(spec)-1 {
+			return nil, fmt.Errorf("api key `%s` is not in the name:key format", spec)
+		}
+		clients = append(clients, Client{Name: spec[:i], Key: spec[i+1:]})
+	}
+	return clients, nil
+}
+
+// LoadClients reads the json array of clients from the file at path.
+func LoadClients(path string) ([]Client, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading api keys file: %w", err)
+	}
+	var clients []Client
+	if err := json.Unmarshal(b, &clients); err != nil {
+		return nil, fmt.Errorf("error while decoding api keys file: %w", err)
+	}
+	return clients, nil
+}
+
+// Keyring authenticates API keys and enforces the rate limits and daily quotas of their clients.
+// It's safe for concurrent use.
+type Keyring struct {
+	clients map[[sha256.Size]byte]*entry
+	now     func() time.Time
+}
+
+// entry holds the state of the limits of a client.
+// spec is the client as it's given to the keyring and client is spec with the default limits.
+type entry struct {
+	spec    Client
+	limiter *rate.Limiter
+
+	mu     sync.Mutex
+	client Client
+	day    string
+	used   int
+}
+
+// NewKeyring creates a Keyring for clients, the zero limits of clients are replaced by defaults.
+// It returns an error when a client has no name or key or two clients share the same key.
+func NewKeyring(clients []Client, defaults Limits) (*Keyring, error) {
+	k := &Keyring{clients: map[[sha256.Size]byte]*entry{}, now: time.Now}
+	for _, c := range clients {
+		if c.Name == "" || c.Key == "" {
+			return nil, errors.New("api key clients must have a name and a key")
+		}
+		h := sha256.Sum256([]byte(c.Key))
+		if _, ok := k.clients[h]; ok {
+			return nil, fmt.Errorf("api key of client `%s` is duplicated", c.Name)
+		}
+		e := &entry{spec: c, client: c.withDefaults(defaults)}
+		e.limiter = rate.NewLimiter(rate.Limit(e.client.RateLimit), e.client.Burst)
+		k.clients[h] = e
+	}
+	return k, nil
+}
+
+// SetDefaults replaces the default limits of the keyring, the clients which have their own limits are not changed.
+// The used analyses of the day are kept.
+func (k *Keyring) SetDefaults(defaults Limits) {
+	now := k.now()
+	for _, e := range k.clients {
+		e.mu.Lock()
+		e.client = e.spec.withDefaults(defaults)
+		e.limiter.SetLimitAt(now, rate.Limit(e.client.RateLimit))
+		e.limiter.SetBurstAt(now, e.client.Burst)
+		e.mu.Unlock()
+	}
+}
+
+// withDefaults returns a copy of c whose zero limits are replaced by defaults.
+func (c Client) withDefaults(defaults Limits) Client {
+	if c.RateLimit == 0 {
+		c.RateLimit = defaults.RateLimit
+	}
+	if c.Burst == 0 {
+		c.Burst = defaults.Burst
+	}
+	if c.DailyQuota == 0 {
+		c.DailyQuota = defaults.DailyQuota
+	}
+	return c
+}
+
+// Authenticate returns the client of key, the keys are looked up by their hashes so the lookup time doesn't
+// depend on how much of a key is correct.
+func (k *Keyring) Authenticate(key string) (*Client, error) {
+	e, ok := k.clients[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	e.mu.Lock()
+	c := e.client
+	e.mu.Unlock()
+	return &c, nil
+}
+
+// Allow takes a request from the rate limit of the client of key.
+// It returns ErrRateLimited and the time after which the request is allowed when the rate limit is exceeded.
+func (k *Keyring) Allow(key string) (time.Duration, error) {
+	e, ok := k.clients[sha256.Sum256([]byte(key))]
+	if !ok {
+		return 0, ErrInvalidKey
+	}
+	now := k.now()
+	r := e.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return time.Second, ErrRateLimited
+	}
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return d, ErrRateLimited
+	}
+	return 0, nil
+}
+
+// Charge takes an analysis from the daily quota of the client of key and returns the remaining analyses of the day,
+// the quota is reset at midnight UTC. It returns ErrQuotaExceeded and the time until the reset when the quota
+// has been used up. A client without quota has -1 remaining analyses.
+func (k *Keyring) Charge(key string) (int, time.Duration, error) {
+	return k.ChargeN(key, 1)
+}
+
+// ChargeN takes n analyses from the daily quota of the client of key like Charge, nothing is taken when less than
+// n analyses are remained.
+func (k *Keyring) ChargeN(key string, n int) (int, time.Duration, error) {
+	e, ok := k.clients[sha256.Sum256([]byte(key))]
+	if !ok {
+		return 0, 0, ErrInvalidKey
+	}
+
+	now := k.now().UTC()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client.DailyQuota < 0 {
+		return -1, 0, nil
+	}
+	if day := now.Format("2006-01-02"); e.day != day {
+		e.day = day
+		e.used = 0
+	}
+	if e.used+n > e.client.DailyQuota {
+		reset := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return 0, reset.Sub(now), ErrQuotaExceeded
+	}
+	e.used += n
+	return e.client.DailyQuota - e.used, 0, nil
+}
+
+// Refund gives n analyses which have been charged but not run back to the daily quota of the client of key.
+func (k *Keyring) Refund(key string, n int) {
+	e, ok := k.clients[sha256.Sum256([]byte(key))]
+	if !ok || n < 1 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used -= n
+	if e.used < 0 {
+		e.used = 0
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClients(t *testing.T) {
+	clients, err := ParseClients([]string{"team-a:key-a", "team-b:key:b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Client{{Name: "team-a", Key: "key-a"}, {Name: "team-b", Key: "key:b"}}, clients)
+
+	for _, spec := range []string{"key", ":key", "team:"} {
+		_, err := ParseClients([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestLoadClients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	_ = ioutil.WriteFile(path, []byte(`[{"name": "team-a", "key": "key-a", "rate_limit": 2, "daily_quota": -1}]`), 0600)
+	clients, err := LoadClients(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Client{{Name: "team-a", Key: "key-a", RateLimit: 2, DailyQuota: -1}}, clients)
+
+	_ = ioutil.WriteFile(path, []byte(`{`), 0600)
+	_, err = LoadClients(path)
+	assert.Error(t, err)
+
+	_, err = LoadClients(filepath.Join(t.TempDir(), "unknown.json"))
+	assert.Error(t, err)
+}
+
+func TestNewKeyring(t *testing.T) {
+	k, err := NewKeyring([]Client{{Name: "team-a", Key: "key-a", Burst: 3}}, Limits{RateLimit: 1, Burst: 1, DailyQuota: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	c, err := k.Authenticate("key-a")
+	assert.NoError(t, err)
+	assert.Equal(t, &Client{Name: "team-a", Key: "key-a", RateLimit: 1, Burst: 3, DailyQuota: 10}, c)
+
+	_, err = k.Authenticate("key-b")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = k.Authenticate("")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	_, err = NewKeyring([]Client{{Name: "team-a", Key: "key"}, {Name: "team-b", Key: "key"}}, Limits{})
+	assert.Error(t, err)
+	_, err = NewKeyring([]Client{{Key: "key"}}, Limits{})
+	assert.Error(t, err)
+}
+
+func TestKeyring_Allow(t *testing.T) {
+	now := time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC)
+	k, _ := NewKeyring([]Client{{Name: "team-a", Key: "key-a"}}, Limits{RateLimit: 1, Burst: 2})
+	k.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := k.Allow("key-a")
+		assert.NoError(t, err)
+	}
+	wait, err := k.Allow("key-a")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, time.Second, wait)
+
+	now = now.Add(time.Second)
+	_, err = k.Allow("key-a")
+	assert.NoError(t, err)
+
+	_, err = k.Allow("key-b")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestKeyring_Charge(t *testing.T) {
+	now := time.Date(2021, 7, 1, 23, 0, 0, 0, time.UTC)
+	k, _ := NewKeyring([]Client{
+		{Name: "team-a", Key: "key-a"},
+		{Name: "team-b", Key: "key-b", DailyQuota: -1},
+	}, Limits{DailyQuota: 2})
+	k.now = func() time.Time { return now }
+
+	remaining, _, err := k.Charge("key-a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+	remaining, _, err = k.Charge("key-a")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+	_, wait, err := k.Charge("key-a")
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, time.Hour, wait)
+
+	// The quota is reset on the next day.
+	now = now.Add(time.Hour)
+	remaining, _, err = k.Charge("key-a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+
+	remaining, _, err = k.Charge("key-b")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, remaining)
+
+	_, _, err = k.Charge("key-c")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestKeyring_ChargeN(t *testing.T) {
+	now := time.Date(2021, 7, 1, 23, 0, 0, 0, time.UTC)
+	k, _ := NewKeyring([]Client{{Name: "team-a", Key: "key-a"}}, Limits{DailyQuota: 5})
+	k.now = func() time.Time { return now }
+
+	remaining, _, err := k.ChargeN("key-a", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+
+	// Nothing is taken when the quota is not enough.
+	_, wait, err := k.ChargeN("key-a", 3)
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, time.Hour, wait)
+
+	k.Refund("key-a", 2)
+	remaining, _, err = k.ChargeN("key-a", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+
+	// The refunds can not raise the quota over its daily limit.
+	k.Refund("key-a", 10)
+	remaining, _, err = k.ChargeN("key-a", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+}
+
+func TestKeyring_SetDefaults(t *testing.T) {
+	k, _ := NewKeyring([]Client{
+		{Name: "team-a", Key: "key-a"},
+		{Name: "team-b", Key: "key-b", RateLimit: 1, Burst: 1, DailyQuota: 5},
+	}, Limits{RateLimit: 1, Burst: 1, DailyQuota: 1})
+	now := time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC)
+	k.now = func() time.Time { return now }
+
+	_, _, err := k.Charge("key-a")
+	assert.NoError(t, err)
+	k.SetDefaults(Limits{RateLimit: 10, Burst: 3, DailyQuota: 3})
+
+	c, _ := k.Authenticate("key-a")
+	assert.Equal(t, &Client{Name: "team-a", Key: "key-a", RateLimit: 10, Burst: 3, DailyQuota: 3}, c)
+	c, _ = k.Authenticate("key-b")
+	assert.Equal(t, &Client{Name: "team-b", Key: "key-b", RateLimit: 1, Burst: 1, DailyQuota: 5}, c)
+
+	// The new burst applies and the used analyses of the day are kept.
+	now = now.Add(time.Second)
+	for i := 0; i < 3; i++ {
+		_, err := k.Allow("key-a")
+		assert.NoError(t, err)
+	}
+	remaining, _, err := k.Charge("key-a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+}
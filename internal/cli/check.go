@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/mammadmodi/detective/pkg/rules"
+)
+
+// Check is the name of a part of the analysis which can be selected to run.
+type Check string
+
+// List of the checks of an analysis.
+const (
+	CheckHTMLVersion       Check = "html_version"
+	CheckTitle             Check = "title"
+	CheckHeadings          Check = "headings"
+	CheckLinks             Check = "links"
+	CheckInaccessibleLinks Check = "inaccessible_links"
+	CheckLoginForm         Check = "login_form"
+	CheckCanonicalURL      Check = "canonical_url"
+)
+
+// Checks is the list of all the checks in the order which they run.
+var Checks = []Check{
+	CheckHTMLVersion,
+	CheckTitle,
+	CheckHeadings,
+	CheckLinks,
+	CheckInaccessibleLinks,
+	CheckLoginForm,
+	CheckCanonicalURL,
+}
+
+// metricChecks maps the metrics of rules to the checks which produce them.
+var metricChecks = map[rules.Metric]Check{
+	rules.MetricHTMLVersion:               CheckHTMLVersion,
+	rules.MetricTitle:                     CheckTitle,
+	rules.MetricTitleLength:               CheckTitle,
+	rules.MetricHeadingsH1:                CheckHeadings,
+	rules.MetricHeadingsH2:                CheckHeadings,
+	rules.MetricHeadingsH3:                CheckHeadings,
+	rules.MetricHeadingsH4:                CheckHeadings,
+	rules.MetricHeadingsH5:                CheckHeadings,
+	rules.MetricHeadingsH6:                CheckHeadings,
+	rules.MetricInternalLinks:             CheckLinks,
+	rules.MetricExternalLinks:             CheckLinks,
+	rules.MetricInaccessibleLinks:         CheckInaccessibleLinks,
+	rules.MetricInaccessibleInternalLinks: CheckInaccessibleLinks,
+	rules.MetricInaccessibleExternalLinks: CheckInaccessibleLinks,
+	rules.MetricRobotsSkippedLinks:        CheckInaccessibleLinks,
+	rules.MetricHasLoginForm:              CheckLoginForm,
+	rules.MetricCanonicalURL:              CheckCanonicalURL,
+}
+
+// ParseChecks parses a comma separated list of checks, "all" selects all of them.
+func ParseChecks(s string) (map[Check]bool, error) {
+	checks := make(map[Check]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "all" {
+			for _, c := range Checks {
+				checks[c] = true
+			}
+			continue
+		}
+		if !isCheck(Check(name)) {
+			return nil, fmt.Errorf("unknown check `%s`", name)
+		}
+		checks[Check(name)] = true
+	}
+	return checks, nil
+}
+
+// isCheck returns true if c is one of Checks.
+func isCheck(c Check) bool {
+	for _, known := range Checks {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// analyze runs the selected checks of analyzer on htmlDoc whose links are resolved against u, the fields of the
+// result which belong to the other checks are left empty.
+func analyze(ctx context.Context, analyzer *htmlanalysis.Analyzer, u *url.URL, htmlDoc string, checks map[Check]bool) *htmlanalysis.Result {
+	a := analyzer.NewHTMLAnalyzer(htmlDoc, u)
+	r := &htmlanalysis.Result{}
+	if checks[CheckHTMLVersion] {
+		r.HTMLVersion = a.GetHTMLVersion()
+	}
+	if checks[CheckTitle] {
+		r.PageTitle = a.GetPageTitle()
+	}
+	if checks[CheckHeadings] {
+		r.HeadingsCount = a.GetHeadingsCount()
+	}
+	if checks[CheckLinks] {
+		r.LinksCount = a.GetLinksCount()
+	}
+	if checks[CheckInaccessibleLinks] {
+		r.InaccessibleLinksCount = a.GetInaccessibleLinksCount(ctx)
+		r.InaccessibleLinks = a.GetInaccessibleLinks()
+		r.RobotsSkippedLinksCount = a.GetRobotsSkippedLinksCount()
+	}
+	if checks[CheckLoginForm] {
+		r.HasLoginForm = a.HasLoginForm()
+	}
+	if checks[CheckCanonicalURL] {
+		if cu := a.GetCanonicalURL(); cu != nil {
+			r.CanonicalURL = cu.String()
+		}
+	}
+	return r
+}
+
+// Thresholds are the limits which the results of analyses must satisfy.
+// MaxInaccessibleLinks is the maximum number of inaccessible links, a negative number disables the limit.
+// RequireTitle and RequireCanonicalURL require the page to have a title and a canonical link.
+type Thresholds struct {
+	MaxInaccessibleLinks int
+	RequireTitle         bool
+	RequireCanonicalURL  bool
+}
+
+// requiredChecks returns the checks which must run to evaluate t.
+func (t Thresholds) requiredChecks() []Check {
+	var checks []Check
+	if t.MaxInaccessibleLinks >= 0 {
+		checks = append(checks, CheckInaccessibleLinks)
+	}
+	if t.RequireTitle {
+		checks = append(checks, CheckTitle)
+	}
+	if t.RequireCanonicalURL {
+		checks = append(checks, CheckCanonicalURL)
+	}
+	return checks
+}
+
+// Checks returns the verdict of each threshold of t on r, the disabled thresholds are not checked.
+func (t Thresholds) Checks(r *htmlanalysis.Result) []report.Check {
+	var checks []report.Check
+	if t.MaxInaccessibleLinks >= 0 {
+		msg := fmt.Sprintf(
+			"page has %d inaccessible links, at most %d are allowed",
+			r.InaccessibleLinksCount,
+			t.MaxInaccessibleLinks,
+		)
+		checks = append(checks, report.NewCheck("max_inaccessible_links", r.InaccessibleLinksCount <= t.MaxInaccessibleLinks, msg, msg))
+	}
+	if t.RequireTitle {
+		hasTitle := r.PageTitle != "" && r.PageTitle != htmlanalysis.EmptyPageTitle
+		checks = append(checks, report.NewCheck("require_title", hasTitle, "page has a title", "page has no title"))
+	}
+	if t.RequireCanonicalURL {
+		checks = append(checks, report.NewCheck(
+			"require_canonical_url",
+			r.CanonicalURL != "",
+			"page has a canonical url",
+			"page has no canonical url",
+		))
+	}
+	return checks
+}
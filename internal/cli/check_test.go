@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChecks(t *testing.T) {
+	checks, err := ParseChecks("all")
+	if assert.NoError(t, err) {
+		assert.Len(t, checks, len(Checks))
+	}
+
+	checks, err = ParseChecks("title, links")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[Check]bool{CheckTitle: true, CheckLinks: true}, checks)
+	}
+
+	_, err = ParseChecks("title,speed")
+	assert.EqualError(t, err, "unknown check `speed`")
+}
+
+func TestAnalyze(t *testing.T) {
+	u, _ := url.Parse("https://example.com")
+	htmlDoc := `<!DOCTYPE html><title>Detective</title><h1>a</h1><link rel="canonical" href="/home">`
+
+	r := analyze(context.Background(), htmlanalysis.NewAnalyzer(), u, htmlDoc, map[Check]bool{CheckTitle: true, CheckCanonicalURL: true})
+	assert.Equal(t, &htmlanalysis.Result{PageTitle: "Detective", CanonicalURL: "https://example.com/home"}, r)
+}
+
+func TestThresholds_Checks(t *testing.T) {
+	tests := []struct {
+		name           string
+		thresholds     Thresholds
+		result         *htmlanalysis.Result
+		expectedChecks []report.Check
+	}{
+		{
+			name:       "disabled",
+			thresholds: Thresholds{MaxInaccessibleLinks: -1},
+			result:     &htmlanalysis.Result{InaccessibleLinksCount: 5},
+		},
+		{
+			name:       "satisfied",
+			thresholds: Thresholds{MaxInaccessibleLinks: 1, RequireTitle: true, RequireCanonicalURL: true},
+			result: &htmlanalysis.Result{
+				PageTitle:              "Detective",
+				InaccessibleLinksCount: 1,
+				CanonicalURL:           "https://example.com",
+			},
+			expectedChecks: []report.Check{
+				{Name: "max_inaccessible_links", Passed: true, Message: "page has 1 inaccessible links, at most 1 are allowed"},
+				{Name: "require_title", Passed: true, Message: "page has a title"},
+				{Name: "require_canonical_url", Passed: true, Message: "page has a canonical url"},
+			},
+		},
+		{
+			name:       "violated",
+			thresholds: Thresholds{MaxInaccessibleLinks: 0, RequireTitle: true, RequireCanonicalURL: true},
+			result:     &htmlanalysis.Result{PageTitle: htmlanalysis.EmptyPageTitle, InaccessibleLinksCount: 2},
+			expectedChecks: []report.Check{
+				{Name: "max_inaccessible_links", Message: "page has 2 inaccessible links, at most 0 are allowed"},
+				{Name: "require_title", Message: "page has no title"},
+				{Name: "require_canonical_url", Message: "page has no canonical url"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedChecks, test.thresholds.Checks(test.result))
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package cli implements the detective command-line client which analyzes html documents without the server.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/mammadmodi/detective/pkg/rules"
+)
+
+// List of the exit codes of Run.
+const (
+	// ExitOK means the analysis finished and the result satisfies the thresholds and the rules.
+	ExitOK = 0
+	// ExitViolated means the analysis finished but the result violates the thresholds or the rules.
+	ExitViolated = 1
+	// ExitError means the command is not valid or the analysis failed.
+	ExitError = 2
+)
+
+// usage is the usage of the client.
+const usage = `Usage: detective analyze [flags] <url | file | ->
+
+Analyzes the html document of a url, a local file or stdin ("-") and prints a report.
+The command exits with 1 if the page violates the thresholds or the rules and with 2 if the analysis fails.
+
+Flags:
+`
+
+// options holds the parsed flags of the analyze command.
+type options struct {
+	source      string
+	baseURL     string
+	timeout     time.Duration
+	concurrency int
+	checks      map[Check]bool
+	thresholds  Thresholds
+	rules       *rules.RuleSet
+	reporter    report.Reporter
+}
+
+// Run runs the command of args, which doesn't include the program name, and returns its exit code.
+// stdin is the document of the "-" source, the report is written to stdout and the errors to stderr.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "analyze" {
+		_, _ = fmt.Fprint(stderr, usage)
+		newFlagSet(stderr, &options{}, new(string), new(string), new(string)).PrintDefaults()
+		return ExitError
+	}
+	opts, err := parseFlags(args[1:], stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			_, _ = fmt.Fprintf(stderr, "detective: %v\n", err)
+		}
+		return ExitError
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	hc := &http.Client{Timeout: opts.timeout}
+	a := htmlanalysis.NewAnalyzer(htmlanalysis.WithHTTPClient(hc), htmlanalysis.WithLinkProbeLimit(opts.concurrency))
+
+	u, htmlDoc, err := load(ctx, hc, opts, stdin)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "detective: %v\n", err)
+		return ExitError
+	}
+
+	r := analyze(ctx, a, u, htmlDoc, opts.checks)
+	if err := ctx.Err(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "detective: analysis didn't finish in %s\n", opts.timeout)
+		return ExitError
+	}
+	// The page is reported with the default checks unless thresholds or rules are given, only thresholds and rules
+	// affect the exit code.
+	page := report.NewPage(opts.source, r, "")
+	checks := opts.thresholds.Checks(r)
+	if opts.rules != nil {
+		checks = append(checks, opts.rules.Evaluate(u.String(), r).Checks()...)
+	}
+	if len(checks) > 0 {
+		page.Checks = checks
+	}
+	if err := opts.reporter.Write(stdout, []*report.Page{page}); err != nil {
+		_, _ = fmt.Fprintf(stderr, "detective: error while writing report: %v\n", err)
+		return ExitError
+	}
+
+	code := ExitOK
+	for _, c := range checks {
+		if !c.Passed {
+			_, _ = fmt.Fprintf(stderr, "detective: %s\n", c.Message)
+			code = ExitViolated
+		}
+	}
+	return code
+}
+
+// newFlagSet creates the flag set of the analyze command which stores the flags in opts, checks, format and
+// rulesFile.
+func newFlagSet(output io.Writer, opts *options, checks, format, rulesFile *string) *flag.FlagSet {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(output, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.baseURL, "base-url", "", "url which the links of a file or stdin are resolved against")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "maximum duration of the whole analysis")
+	fs.IntVar(&opts.concurrency, "concurrency", 16, "maximum number of links which are checked at the same time")
+	fs.StringVar(checks, "checks", "all", "comma separated list of checks to run: all or "+joinChecks(Checks))
+	fs.StringVar(format, "format", string(report.FormatJSON), "format of the report: "+joinFormats(report.Formats))
+	fs.IntVar(&opts.thresholds.MaxInaccessibleLinks, "max-inaccessible-links", -1,
+		"fails if the page has more inaccessible links, a negative number disables the threshold")
+	fs.BoolVar(&opts.thresholds.RequireTitle, "require-title", false, "fails if the page has no title")
+	fs.BoolVar(&opts.thresholds.RequireCanonicalURL, "require-canonical-url", false,
+		"fails if the page has no canonical url")
+	fs.StringVar(rulesFile, "rules", "", "path of a yaml or json rules file which the page is evaluated against")
+	return fs
+}
+
+// parseFlags parses the flags and the source of the analyze command.
+func parseFlags(args []string, output io.Writer) (*options, error) {
+	opts := &options{}
+	var checks, format, rulesFile string
+	fs := newFlagSet(output, opts, &checks, &format, &rulesFile)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, errors.New("exactly one url, file or - must be given")
+	}
+	opts.source = fs.Arg(0)
+	if opts.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	var err error
+	opts.checks, err = ParseChecks(checks)
+	if err != nil {
+		return nil, err
+	}
+	opts.reporter, err = report.New(report.Format(format))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range opts.thresholds.requiredChecks() {
+		if !opts.checks[c] {
+			return nil, fmt.Errorf("threshold requires the `%s` check", c)
+		}
+	}
+	if rulesFile != "" {
+		opts.rules, err = rules.Load(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range opts.rules.Rules {
+			if c := metricChecks[r.Metric]; !opts.checks[c] {
+				return nil, fmt.Errorf("rule `%s` requires the `%s` check", r.Name, c)
+			}
+		}
+	}
+	return opts, nil
+}
+
+// load reads the html document of the source of opts and returns the url which its links are resolved against.
+// A url is fetched with hc, the base url of a file or stdin is required when their links are checked.
+func load(ctx context.Context, hc *http.Client, opts *options, stdin io.Reader) (*url.URL, string, error) {
+	if isURL(opts.source) {
+		u, err := url.ParseRequestURI(opts.source)
+		if err != nil {
+			return nil, "", fmt.Errorf("entered url is not valid: %w", err)
+		}
+		htmlDoc, err := fetch(ctx, hc, u)
+		return u, htmlDoc, err
+	}
+
+	var u *url.URL
+	if opts.baseURL != "" {
+		var err error
+		u, err = url.ParseRequestURI(opts.baseURL)
+		if err != nil || u.Host == "" {
+			return nil, "", errors.New("base url is not valid")
+		}
+	} else if opts.checks[CheckInaccessibleLinks] {
+		return nil, "", errors.New("base url is required to check the links of a file or stdin")
+	} else {
+		// The links are only counted, so they are resolved against the location of the document.
+		path, _ := filepath.Abs(opts.source)
+		if opts.source == "-" {
+			path, _ = filepath.Abs(".")
+			path += string(filepath.Separator)
+		}
+		u = &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+	if opts.source == "-" {
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(opts.source)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read html document: %w", err)
+	}
+	return u, string(b), nil
+}
+
+// fetch performs a GET request to u and returns its html document.
+func fetch(ctx context.Context, hc *http.Client, u *url.URL) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("error while creating HTTP request: %w", err)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error while performing HTTP request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("url responded with status code %d", resp.StatusCode)
+	}
+	if t := resp.Header.Get("Content-Type"); !strings.HasPrefix(t, "text/html") {
+		return "", fmt.Errorf("response content type `%s` isn't text/html", t)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read the response body: %w", err)
+	}
+	return string(b), nil
+}
+
+// isURL returns true if source is an http or https url rather than a file.
+func isURL(source string) bool {
+	s := strings.ToLower(source)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// joinFormats joins the names of formats with commas.
+func joinFormats(formats []report.Format) string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ",")
+}
+
+// joinChecks joins the names of checks with commas.
+func joinChecks(checks []Check) string {
+	names := make([]string, 0, len(checks))
+	for _, c := range checks {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ",")
+}
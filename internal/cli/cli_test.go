@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSite serves body as an html page on "/" and responds with 404 on the other paths until the test finishes.
+func newTestSite(t *testing.T, body string) *httptest.Server {
+	site := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, body)
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// runTest runs args with stdin and returns the exit code, the page of the json report and the stderr.
+func runTest(t *testing.T, stdin string, args ...string) (int, *report.Page, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	if stdout.Len() == 0 {
+		return code, nil, stderr.String()
+	}
+	var decoded struct {
+		Pages []*report.Page `json:"pages"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &decoded); err != nil || len(decoded.Pages) != 1 {
+		t.Fatalf("error while decoding report: %v", err)
+	}
+	return code, decoded.Pages[0], stderr.String()
+}
+
+// failedChecks returns the messages of the failed checks of p.
+func failedChecks(p *report.Page) []string {
+	var msgs []string
+	for _, c := range p.Checks {
+		if !c.Passed {
+			msgs = append(msgs, c.Message)
+		}
+	}
+	return msgs
+}
+
+func TestRun(t *testing.T) {
+	site := newTestSite(t, `<!DOCTYPE html><title>Detective</title><a href="/">home</a><a href="/missing">missing</a>`)
+
+	code, page, _ := runTest(t, "", "analyze", site.URL)
+	assert.Equal(t, ExitOK, code)
+	if assert.NotNil(t, page) {
+		assert.Equal(t, site.URL, page.URL)
+		assert.Equal(t, "HTML 5", page.Result.HTMLVersion)
+		assert.Equal(t, "Detective", page.Result.PageTitle)
+		assert.Equal(t, 1, page.Result.InaccessibleLinksCount)
+		assert.Len(t, page.Checks, len(report.DefaultChecks(page.Result)))
+	}
+
+	code, page, stderr := runTest(t, "", "analyze", "-max-inaccessible-links", "0", "-require-canonical-url", site.URL)
+	assert.Equal(t, ExitViolated, code)
+	if assert.NotNil(t, page) {
+		assert.Equal(t, []string{
+			"page has 1 inaccessible links, at most 0 are allowed",
+			"page has no canonical url",
+		}, failedChecks(page))
+	}
+	assert.Contains(t, stderr, "page has no canonical url")
+
+	code, _, stderr = runTest(t, "", "analyze", site.URL+"/missing")
+	assert.Equal(t, ExitError, code)
+	assert.Contains(t, stderr, "status code 404")
+}
+
+func TestRunFileAndStdin(t *testing.T) {
+	site := newTestSite(t, "")
+	path := filepath.Join(t.TempDir(), "index.html")
+	if err := ioutil.WriteFile(path, []byte(`<title>File</title><a href="/">home</a><a href="https://example.com">x</a>`), 0o600); err != nil {
+		t.Fatalf("error while writing file: %v", err)
+	}
+
+	code, page, _ := runTest(t, "", "analyze", "-checks", "title,links", path)
+	assert.Equal(t, ExitOK, code)
+	if assert.NotNil(t, page) {
+		assert.Equal(t, "File", page.Result.PageTitle)
+		assert.Equal(t, 1, page.Result.LinksCount.Internal)
+		assert.Equal(t, 1, page.Result.LinksCount.External)
+		assert.Nil(t, page.Result.HeadingsCount)
+	}
+
+	code, page, _ = runTest(t, `<a href="/">home</a>`, "analyze", "-base-url", site.URL, "-require-title", "-")
+	assert.Equal(t, ExitViolated, code)
+	if assert.NotNil(t, page) {
+		assert.Equal(t, "-", page.URL)
+		assert.Equal(t, 0, page.Result.InaccessibleLinksCount)
+		assert.Equal(t, []string{"page has no title"}, failedChecks(page))
+	}
+
+	code, _, stderr := runTest(t, "", "analyze", path)
+	assert.Equal(t, ExitError, code)
+	assert.Contains(t, stderr, "base url is required")
+}
+
+func TestRunRules(t *testing.T) {
+	site := newTestSite(t, `<!DOCTYPE html><title>Detective</title><h1>a</h1><h1>b</h1><a href="/missing">x</a>`)
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	rules := `
+rules:
+  - name: one-h1
+    metric: headings.h1
+    min: 1
+    max: 1
+  - name: title-length
+    metric: title_length
+    min: 5
+    max: 60
+`
+	if err := ioutil.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatalf("error while writing rules file: %v", err)
+	}
+
+	code, page, stderr := runTest(t, "", "analyze", "-rules", path, "-max-inaccessible-links", "1", site.URL)
+	assert.Equal(t, ExitViolated, code)
+	if assert.NotNil(t, page) {
+		assert.Len(t, page.Checks, 3)
+		assert.Equal(t, []string{"headings.h1 is 2, expected between 1 and 1"}, failedChecks(page))
+	}
+	assert.Contains(t, stderr, "headings.h1 is 2")
+
+	// The rules can not check the metrics of the checks which don't run.
+	code, page, stderr = runTest(t, "", "analyze", "-checks", "title,links", "-rules", path, site.URL)
+	assert.Equal(t, ExitError, code)
+	assert.Nil(t, page)
+	assert.Contains(t, stderr, "rule `one-h1` requires the `headings` check")
+}
+
+func TestRunInvalidCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedStderr string
+	}{
+		{name: "missing command", args: nil, expectedStderr: "Usage: detective analyze"},
+		{name: "unknown command", args: []string{"crawl"}, expectedStderr: "Usage: detective analyze"},
+		{name: "missing source", args: []string{"analyze"}, expectedStderr: "exactly one url"},
+		{name: "unknown flag", args: []string{"analyze", "-verbose", "-"}, expectedStderr: "flag provided but not defined"},
+		{name: "unknown check", args: []string{"analyze", "-checks", "title,speed", "-"}, expectedStderr: "unknown check `speed`"},
+		{name: "unknown format", args: []string{"analyze", "-format", "pdf", "-"}, expectedStderr: "unknown report format `pdf`"},
+		{
+			name:           "threshold without check",
+			args:           []string{"analyze", "-checks", "links", "-require-title", "-"},
+			expectedStderr: "threshold requires the `title` check",
+		},
+		{name: "missing file", args: []string{"analyze", "-checks", "title", "missing.html"}, expectedStderr: "could not read"},
+		{name: "missing rules file", args: []string{"analyze", "-rules", "missing.yaml", "-"}, expectedStderr: "rules file"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, page, stderr := runTest(t, "", test.args...)
+			assert.Equal(t, ExitError, code)
+			assert.Nil(t, page)
+			assert.Contains(t, stderr, test.expectedStderr)
+		})
+	}
+}
+
+func TestRunFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"analyze", "-checks", "title", "-require-title", "-format", "junit", "-"}
+	code := Run(context.Background(), args, strings.NewReader("<title>Detective</title>"), &stdout, &stderr)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout.String(), `<testsuite name="-" tests="1" failures="0">`)
+	assert.Contains(t, stdout.String(), `<testcase name="require_title" classname="-">`)
+}
@@ -4,32 +4,287 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
 	"github.com/mammadmodi/detective/pkg/logger"
 )
 
 // AppConfig is a struct which contains configuration of the application.
+// JobTTL is the time which the finished jobs are kept for before they're evicted.
+// ShutdownTimeout is the time which in-flight requests and jobs get to finish after a shutdown signal.
+// MaxDocumentSize is the maximum size of the analyzed html documents in bytes, zero disables the limit.
+// RulesFile is the path of a yaml or json file of rules which the analyzed pages are evaluated against.
+// The sub configs are ignored by envconfig because they are processed with their own prefixes.
 type AppConfig struct {
-	LoggerConfig *logger.Config
-	Addr         string        `default:":8000"`
-	HTTPTimeout  time.Duration `split_words:"true" default:"30s"`
+	LoggerConfig    *logger.Config   `ignored:"true"`
+	Addr            string           `default:":8000"`
+	HTTPTimeout     time.Duration    `split_words:"true" default:"30s"`
+	JobWorkers      int              `split_words:"true" default:"4"`
+	JobQueueSize    int              `split_words:"true" default:"100"`
+	JobTTL          time.Duration    `split_words:"true" default:"1h"`
+	ShutdownTimeout time.Duration    `split_words:"true" default:"30s"`
+	MaxDocumentSize int64            `split_words:"true" default:"10485760"`
+	RulesFile       string           `split_words:"true"`
+	CrawlConfig     *CrawlConfig     `ignored:"true"`
+	SitemapConfig   *SitemapConfig   `ignored:"true"`
+	RobotsConfig    *RobotsConfig    `ignored:"true"`
+	HistoryConfig   *HistoryConfig   `ignored:"true"`
+	MonitorConfig   *MonitorConfig   `ignored:"true"`
+	WebhookConfig   *WebhookConfig   `ignored:"true"`
+	MetricsConfig   *MetricsConfig   `ignored:"true"`
+	TracingConfig   *TracingConfig   `ignored:"true"`
+	AuthConfig      *AuthConfig      `ignored:"true"`
+	AdmissionConfig *AdmissionConfig `ignored:"true"`
+	GRPCConfig      *GRPCConfig      `ignored:"true"`
+	EgressConfig    *EgressConfig    `ignored:"true"`
+}
+
+// EgressConfig holds the configuration of the outgoing requests of the page fetcher and the link checker.
+// HTTPProxy and HTTPSProxy are the urls of the proxies of the http and https requests whose scheme is http, https or
+// socks5, and NoProxy is the list of the hosts, domains and CIDRs which are requested directly like NO_PROXY.
+// CAFile is the path of a PEM bundle of root CAs which are trusted besides the system roots, CertFile and KeyFile are
+// the PEM files of the client certificate and InsecureSkipVerify disables the verification of server certificates.
+// The fields have no envconfig tags since envconfig would also read the unprefixed HTTP_PROXY, NO_PROXY, etc.
+type EgressConfig struct {
+	HTTPProxy          string   `split_words:"true"`
+	HTTPSProxy         string   `split_words:"true"`
+	NoProxy            []string `split_words:"true"`
+	CAFile             string   `split_words:"true"`
+	CertFile           string   `split_words:"true"`
+	KeyFile            string   `split_words:"true"`
+	InsecureSkipVerify bool     `split_words:"true" default:"false"`
+}
+
+// GRPCConfig holds the configuration of the gRPC analysis service which is served on Addr when it's enabled.
+type GRPCConfig struct {
+	Enabled bool   `default:"false"`
+	Addr    string `default:":9000"`
+}
+
+// AdmissionConfig holds the limits of the admission control of analyses.
+// MaxConcurrentAnalyses is the number of analyses which run at the same time and MaxQueuedAnalyses is the number of
+// analyses which wait for at most MaxQueueWait for a slot, MaxLinkProbes is the number of links which are checked at
+// the same time across all the analyses. Zero limits disable them. RetryAfter is sent to the clients of the
+// rejected analyses.
+type AdmissionConfig struct {
+	MaxConcurrentAnalyses int           `split_words:"true" default:"16"`
+	MaxQueuedAnalyses     int           `split_words:"true" default:"32"`
+	MaxQueueWait          time.Duration `split_words:"true" default:"10s"`
+	MaxLinkProbes         int           `split_words:"true" default:"256"`
+	RetryAfter            time.Duration `split_words:"true" default:"5s"`
+}
+
+// AuthConfig holds the configuration of API key authentication.
+// Keys is the list of API keys in the `name:key` format and KeysFile is the path of a json file which holds more
+// clients with their own limits. RateLimit (requests per second), Burst and DailyQuota (analyses per UTC day) are
+// the default limits of the clients.
+type AuthConfig struct {
+	Enabled    bool `default:"false"`
+	Keys       []string
+	KeysFile   string  `split_words:"true"`
+	RateLimit  float64 `split_words:"true" default:"5"`
+	Burst      int     `default:"10"`
+	DailyQuota int     `split_words:"true" default:"1000"`
+}
+
+// TracingConfig holds the configuration of OpenTelemetry tracing, tracing is disabled when Exporter is "none".
+// Exporter is one of "none", "stdout" or "otlp" and Endpoint is the host:port of the OTLP/HTTP collector.
+// SampleRatio is the fraction of the traces which are sampled.
+type TracingConfig struct {
+	Exporter    string  `default:"none"`
+	Endpoint    string  `default:"localhost:4318"`
+	Insecure    bool    `default:"true"`
+	SampleRatio float64 `split_words:"true" default:"1"`
+	ServiceName string  `split_words:"true" default:"detective"`
+}
+
+// MetricsConfig holds the configuration of the prometheus metrics endpoint.
+type MetricsConfig struct {
+	Enabled bool `default:"true"`
+}
+
+// WebhookConfig holds the configuration of webhook notifications, webhooks are disabled when Urls is empty.
+// Secret is the key of the HMAC signature of the payloads and Events limits the types of delivered events.
+// A failed delivery is retried MaxRetries times with an exponential backoff which starts from Backoff.
+// Urls is not spelled as an acronym because split_words would turn URLs into UR_LS, and an envconfig tag would
+// also read the unprefixed URLS variable.
+type WebhookConfig struct {
+	Urls       []string `split_words:"true"`
+	Secret     string
+	Events     []string
+	MaxRetries int           `split_words:"true" default:"3"`
+	Backoff    time.Duration `default:"1s"`
+	Timeout    time.Duration `default:"10s"`
+}
+
+// MonitorConfig holds the configuration of scheduled monitors.
+// Concurrency is the number of monitors which run at the same time and MinInterval is the shortest accepted
+// interval of monitor schedules.
+type MonitorConfig struct {
+	Enabled     bool          `default:"true"`
+	Concurrency int           `default:"2"`
+	MinInterval time.Duration `split_words:"true" default:"1m"`
+}
+
+// HistoryConfig holds the configuration of the analysis history store.
+// Path is the path of the database file which holds the stored analyses, the analyses which are older than MaxAge
+// or aren't among the newest MaxRecords analyses are pruned and zero disables each of the limits.
+type HistoryConfig struct {
+	Enabled    bool          `default:"true"`
+	Path       string        `default:"detective.db"`
+	MaxAge     time.Duration `split_words:"true" default:"720h"`
+	MaxRecords int           `split_words:"true" default:"10000"`
+}
+
+// SitemapConfig holds the limits of sitemap audits.
+// MaxUrls is not spelled as an acronym because split_words would turn MaxURLs into MAX_UR_LS.
+type SitemapConfig struct {
+	MaxUrls     int `split_words:"true" default:"500"`
+	Concurrency int `default:"4"`
+}
+
+// RobotsConfig holds the configuration of robots.txt evaluation.
+type RobotsConfig struct {
+	Enabled   bool          `default:"true"`
+	UserAgent string        `split_words:"true" default:"detective"`
+	CacheTTL  time.Duration `split_words:"true" default:"1h"`
+}
+
+// CrawlConfig holds the limits of site crawls.
+type CrawlConfig struct {
+	MaxDepth    int `split_words:"true" default:"3"`
+	MaxPages    int `split_words:"true" default:"100"`
+	Concurrency int `default:"4"`
 }
 
 // NewAppConfig creates an AppConfig object based on the environment variables of the OS.
 func NewAppConfig() (*AppConfig, error) {
+	return Load("")
+}
+
+// Load creates an AppConfig object based on the configuration file of path and the environment variables of the OS
+// and validates it. The environment variables override the values of the file and the file is skipped when path is
+// empty.
+func Load(path string) (*AppConfig, error) {
+	values := fileValues{}
+	if path != "" {
+		var err error
+		if values, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := newAppConfig(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := values.checkUnused(); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newAppConfig creates an AppConfig object based on the environment variables of the OS and the values of the
+// configuration file which are not set by them.
+func newAppConfig(values fileValues) (*AppConfig, error) {
 	// Try to load env variables to AppConfig struct.
 	c := &AppConfig{}
-	err := envconfig.Process("detective", c)
+	err := process("detective", c, values)
 	if err != nil {
 		return nil, fmt.Errorf("error while processing env variables for root configs, error: %s", err.Error())
 	}
 
 	// Try to load env variables to logger.Config struct.
 	loggerConfig := &logger.Config{}
-	if err := envconfig.Process("detective_logger", loggerConfig); err != nil {
+	if err := process("detective_logger", loggerConfig, values); err != nil {
 		return nil, fmt.Errorf("error while processing env variables for logger configs, error: %s", err.Error())
 	}
 	c.LoggerConfig = loggerConfig
 
+	// Try to load env variables to CrawlConfig struct.
+	crawlConfig := &CrawlConfig{}
+	if err := process("detective_crawl", crawlConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for crawl configs, error: %s", err.Error())
+	}
+	c.CrawlConfig = crawlConfig
+
+	// Try to load env variables to SitemapConfig struct.
+	sitemapConfig := &SitemapConfig{}
+	if err := process("detective_sitemap", sitemapConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for sitemap configs, error: %s", err.Error())
+	}
+	c.SitemapConfig = sitemapConfig
+
+	// Try to load env variables to RobotsConfig struct.
+	robotsConfig := &RobotsConfig{}
+	if err := process("detective_robots", robotsConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for robots configs, error: %s", err.Error())
+	}
+	c.RobotsConfig = robotsConfig
+
+	// Try to load env variables to HistoryConfig struct.
+	historyConfig := &HistoryConfig{}
+	if err := process("detective_history", historyConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for history configs, error: %s", err.Error())
+	}
+	c.HistoryConfig = historyConfig
+
+	// Try to load env variables to MonitorConfig struct.
+	monitorConfig := &MonitorConfig{}
+	if err := process("detective_monitor", monitorConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for monitor configs, error: %s", err.Error())
+	}
+	c.MonitorConfig = monitorConfig
+
+	// Try to load env variables to WebhookConfig struct.
+	webhookConfig := &WebhookConfig{}
+	if err := process("detective_webhook", webhookConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for webhook configs, error: %s", err.Error())
+	}
+	c.WebhookConfig = webhookConfig
+
+	// Try to load env variables to MetricsConfig struct.
+	metricsConfig := &MetricsConfig{}
+	if err := process("detective_metrics", metricsConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for metrics configs, error: %s", err.Error())
+	}
+	c.MetricsConfig = metricsConfig
+
+	// Try to load env variables to TracingConfig struct.
+	tracingConfig := &TracingConfig{}
+	if err := process("detective_tracing", tracingConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for tracing configs, error: %s", err.Error())
+	}
+	c.TracingConfig = tracingConfig
+
+	// Try to load env variables to AuthConfig struct.
+	authConfig := &AuthConfig{}
+	if err := process("detective_auth", authConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for auth configs, error: %s", err.Error())
+	}
+	c.AuthConfig = authConfig
+
+	// Try to load env variables to AdmissionConfig struct.
+	admissionConfig := &AdmissionConfig{}
+	if err := process("detective_admission", admissionConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for admission configs, error: %s", err.Error())
+	}
+	c.AdmissionConfig = admissionConfig
+
+	// Try to load env variables to GRPCConfig struct.
+	grpcConfig := &GRPCConfig{}
+	if err := process("detective_grpc", grpcConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for grpc configs, error: %s", err.Error())
+	}
+	c.GRPCConfig = grpcConfig
+
+	// Try to load env variables to EgressConfig struct.
+	egressConfig := &EgressConfig{}
+	if err := process("detective_egress", egressConfig, values); err != nil {
+		return nil, fmt.Errorf("error while processing env variables for egress configs, error: %s", err.Error())
+	}
+	c.EgressConfig = egressConfig
+
 	return c, nil
 }
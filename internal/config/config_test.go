@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,8 +22,85 @@ func setConfigOsEnvVariables() *AppConfig {
 			FileRedirectPath:    "/var/log",
 			FileRedirectPrefix:  "detective",
 		},
-		Addr:        "10.0.0.1:8080",
-		HTTPTimeout: 25 * time.Second,
+		Addr:            "10.0.0.1:8080",
+		HTTPTimeout:     25 * time.Second,
+		JobWorkers:      8,
+		JobQueueSize:    50,
+		JobTTL:          30 * time.Minute,
+		ShutdownTimeout: 15 * time.Second,
+		MaxDocumentSize: 1048576,
+		RulesFile:       "/etc/detective/rules.yaml",
+		CrawlConfig: &CrawlConfig{
+			MaxDepth:    2,
+			MaxPages:    20,
+			Concurrency: 3,
+		},
+		SitemapConfig: &SitemapConfig{
+			MaxUrls:     200,
+			Concurrency: 2,
+		},
+		RobotsConfig: &RobotsConfig{
+			Enabled:   false,
+			UserAgent: "detective-test",
+			CacheTTL:  10 * time.Minute,
+		},
+		HistoryConfig: &HistoryConfig{
+			Enabled:    false,
+			Path:       "/tmp/detective-test.db",
+			MaxAge:     48 * time.Hour,
+			MaxRecords: 500,
+		},
+		MonitorConfig: &MonitorConfig{
+			Enabled:     false,
+			Concurrency: 5,
+			MinInterval: 5 * time.Minute,
+		},
+		WebhookConfig: &WebhookConfig{
+			Urls:       []string{"http://hooks.local/a", "http://hooks.local/b"},
+			Secret:     "secret",
+			Events:     []string{"job.completed"},
+			MaxRetries: 5,
+			Backoff:    2 * time.Second,
+			Timeout:    5 * time.Second,
+		},
+		MetricsConfig: &MetricsConfig{
+			Enabled: false,
+		},
+		TracingConfig: &TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "collector.local:4318",
+			Insecure:    false,
+			SampleRatio: 0.5,
+			ServiceName: "detective-test",
+		},
+		AuthConfig: &AuthConfig{
+			Enabled:    true,
+			Keys:       []string{"team-a:key-a", "team-b:key-b"},
+			KeysFile:   "/etc/detective/keys.json",
+			RateLimit:  2.5,
+			Burst:      4,
+			DailyQuota: 100,
+		},
+		AdmissionConfig: &AdmissionConfig{
+			MaxConcurrentAnalyses: 4,
+			MaxQueuedAnalyses:     8,
+			MaxQueueWait:          3 * time.Second,
+			MaxLinkProbes:         64,
+			RetryAfter:            2 * time.Second,
+		},
+		GRPCConfig: &GRPCConfig{
+			Enabled: true,
+			Addr:    ":9090",
+		},
+		EgressConfig: &EgressConfig{
+			HTTPProxy:          "http://proxy.local:3128",
+			HTTPSProxy:         "socks5://proxy.local:1080",
+			NoProxy:            []string{"localhost", ".internal"},
+			CAFile:             "/etc/detective/ca.pem",
+			CertFile:           "/etc/detective/client.pem",
+			KeyFile:            "/etc/detective/client-key.pem",
+			InsecureSkipVerify: true,
+		},
 	}
 
 	_ = os.Setenv("DETECTIVE_LOGGER_ENABLED", fmt.Sprint(c.LoggerConfig.Enabled))
@@ -33,6 +111,59 @@ func setConfigOsEnvVariables() *AppConfig {
 	_ = os.Setenv("DETECTIVE_LOGGER_FILE_REDIRECT_PREFIX", c.LoggerConfig.FileRedirectPrefix)
 	_ = os.Setenv("DETECTIVE_ADDR", c.Addr)
 	_ = os.Setenv("DETECTIVE_HTTP_TIMEOUT", c.HTTPTimeout.String())
+	_ = os.Setenv("DETECTIVE_JOB_WORKERS", fmt.Sprint(c.JobWorkers))
+	_ = os.Setenv("DETECTIVE_JOB_QUEUE_SIZE", fmt.Sprint(c.JobQueueSize))
+	_ = os.Setenv("DETECTIVE_JOB_TTL", c.JobTTL.String())
+	_ = os.Setenv("DETECTIVE_SHUTDOWN_TIMEOUT", c.ShutdownTimeout.String())
+	_ = os.Setenv("DETECTIVE_MAX_DOCUMENT_SIZE", fmt.Sprint(c.MaxDocumentSize))
+	_ = os.Setenv("DETECTIVE_RULES_FILE", c.RulesFile)
+	_ = os.Setenv("DETECTIVE_CRAWL_MAX_DEPTH", fmt.Sprint(c.CrawlConfig.MaxDepth))
+	_ = os.Setenv("DETECTIVE_CRAWL_MAX_PAGES", fmt.Sprint(c.CrawlConfig.MaxPages))
+	_ = os.Setenv("DETECTIVE_CRAWL_CONCURRENCY", fmt.Sprint(c.CrawlConfig.Concurrency))
+	_ = os.Setenv("DETECTIVE_SITEMAP_MAX_URLS", fmt.Sprint(c.SitemapConfig.MaxUrls))
+	_ = os.Setenv("DETECTIVE_SITEMAP_CONCURRENCY", fmt.Sprint(c.SitemapConfig.Concurrency))
+	_ = os.Setenv("DETECTIVE_ROBOTS_ENABLED", fmt.Sprint(c.RobotsConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_ROBOTS_USER_AGENT", c.RobotsConfig.UserAgent)
+	_ = os.Setenv("DETECTIVE_ROBOTS_CACHE_TTL", c.RobotsConfig.CacheTTL.String())
+	_ = os.Setenv("DETECTIVE_HISTORY_ENABLED", fmt.Sprint(c.HistoryConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_HISTORY_PATH", c.HistoryConfig.Path)
+	_ = os.Setenv("DETECTIVE_HISTORY_MAX_AGE", c.HistoryConfig.MaxAge.String())
+	_ = os.Setenv("DETECTIVE_HISTORY_MAX_RECORDS", fmt.Sprint(c.HistoryConfig.MaxRecords))
+	_ = os.Setenv("DETECTIVE_MONITOR_ENABLED", fmt.Sprint(c.MonitorConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_MONITOR_CONCURRENCY", fmt.Sprint(c.MonitorConfig.Concurrency))
+	_ = os.Setenv("DETECTIVE_MONITOR_MIN_INTERVAL", c.MonitorConfig.MinInterval.String())
+	_ = os.Setenv("DETECTIVE_WEBHOOK_URLS", strings.Join(c.WebhookConfig.Urls, ","))
+	_ = os.Setenv("DETECTIVE_WEBHOOK_SECRET", c.WebhookConfig.Secret)
+	_ = os.Setenv("DETECTIVE_WEBHOOK_EVENTS", strings.Join(c.WebhookConfig.Events, ","))
+	_ = os.Setenv("DETECTIVE_WEBHOOK_MAX_RETRIES", fmt.Sprint(c.WebhookConfig.MaxRetries))
+	_ = os.Setenv("DETECTIVE_WEBHOOK_BACKOFF", c.WebhookConfig.Backoff.String())
+	_ = os.Setenv("DETECTIVE_WEBHOOK_TIMEOUT", c.WebhookConfig.Timeout.String())
+	_ = os.Setenv("DETECTIVE_METRICS_ENABLED", fmt.Sprint(c.MetricsConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_TRACING_EXPORTER", c.TracingConfig.Exporter)
+	_ = os.Setenv("DETECTIVE_TRACING_ENDPOINT", c.TracingConfig.Endpoint)
+	_ = os.Setenv("DETECTIVE_TRACING_INSECURE", fmt.Sprint(c.TracingConfig.Insecure))
+	_ = os.Setenv("DETECTIVE_TRACING_SAMPLE_RATIO", fmt.Sprint(c.TracingConfig.SampleRatio))
+	_ = os.Setenv("DETECTIVE_TRACING_SERVICE_NAME", c.TracingConfig.ServiceName)
+	_ = os.Setenv("DETECTIVE_AUTH_ENABLED", fmt.Sprint(c.AuthConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_AUTH_KEYS", strings.Join(c.AuthConfig.Keys, ","))
+	_ = os.Setenv("DETECTIVE_AUTH_KEYS_FILE", c.AuthConfig.KeysFile)
+	_ = os.Setenv("DETECTIVE_AUTH_RATE_LIMIT", fmt.Sprint(c.AuthConfig.RateLimit))
+	_ = os.Setenv("DETECTIVE_AUTH_BURST", fmt.Sprint(c.AuthConfig.Burst))
+	_ = os.Setenv("DETECTIVE_AUTH_DAILY_QUOTA", fmt.Sprint(c.AuthConfig.DailyQuota))
+	_ = os.Setenv("DETECTIVE_ADMISSION_MAX_CONCURRENT_ANALYSES", fmt.Sprint(c.AdmissionConfig.MaxConcurrentAnalyses))
+	_ = os.Setenv("DETECTIVE_ADMISSION_MAX_QUEUED_ANALYSES", fmt.Sprint(c.AdmissionConfig.MaxQueuedAnalyses))
+	_ = os.Setenv("DETECTIVE_ADMISSION_MAX_QUEUE_WAIT", c.AdmissionConfig.MaxQueueWait.String())
+	_ = os.Setenv("DETECTIVE_ADMISSION_MAX_LINK_PROBES", fmt.Sprint(c.AdmissionConfig.MaxLinkProbes))
+	_ = os.Setenv("DETECTIVE_ADMISSION_RETRY_AFTER", c.AdmissionConfig.RetryAfter.String())
+	_ = os.Setenv("DETECTIVE_GRPC_ENABLED", fmt.Sprint(c.GRPCConfig.Enabled))
+	_ = os.Setenv("DETECTIVE_GRPC_ADDR", c.GRPCConfig.Addr)
+	_ = os.Setenv("DETECTIVE_EGRESS_HTTP_PROXY", c.EgressConfig.HTTPProxy)
+	_ = os.Setenv("DETECTIVE_EGRESS_HTTPS_PROXY", c.EgressConfig.HTTPSProxy)
+	_ = os.Setenv("DETECTIVE_EGRESS_NO_PROXY", strings.Join(c.EgressConfig.NoProxy, ","))
+	_ = os.Setenv("DETECTIVE_EGRESS_CA_FILE", c.EgressConfig.CAFile)
+	_ = os.Setenv("DETECTIVE_EGRESS_CERT_FILE", c.EgressConfig.CertFile)
+	_ = os.Setenv("DETECTIVE_EGRESS_KEY_FILE", c.EgressConfig.KeyFile)
+	_ = os.Setenv("DETECTIVE_EGRESS_INSECURE_SKIP_VERIFY", fmt.Sprint(c.EgressConfig.InsecureSkipVerify))
 
 	return c
 }
@@ -49,6 +180,23 @@ func TestNewConfigurationSuccess(t *testing.T) {
 	assert.EqualValues(t, expectedConfigs, actualConfigs)
 }
 
+func TestNewConfigurationIgnoresUnprefixedVariables(t *testing.T) {
+	unsetConfigOsEnvVariables()
+	defer unsetConfigOsEnvVariables()
+	for _, name := range []string{"URLS", "MAX_URLS", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "CA_FILE"} {
+		_ = os.Setenv(name, "http://other.local")
+		defer os.Unsetenv(name)
+	}
+
+	c, err := NewAppConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, c.WebhookConfig.Urls)
+	assert.Equal(t, 500, c.SitemapConfig.MaxUrls)
+	assert.Equal(t, &EgressConfig{}, c.EgressConfig)
+}
+
 func TestNewConfigurationFailures(t *testing.T) {
 	t.Run("error when logger config is not valid", func(t *testing.T) {
 		// Enabled field must be boolean.
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/kelseyhightower/envconfig"
+)
+
+// fileValue is a value of the configuration file.
+// Name is the dotted path of the value in the file and used is set when the value is assigned to a config.
+type fileValue struct {
+	name  string
+	value string
+	used  bool
+}
+
+// fileValues maps the names of the environment variables to the values of the configuration file which they
+// correspond to, e.g. `crawl: {max_depth: 3}` is the value of DETECTIVE_CRAWL_MAX_DEPTH.
+type fileValues map[string]*fileValue
+
+// readFile reads the yaml or json configuration file of path.
+func readFile(path string) (fileValues, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading configuration file: %w", err)
+	}
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error while parsing configuration file: %w", err)
+	}
+
+	values := fileValues{}
+	if err := values.add("detective", "", doc); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// add adds v, which is the value of name in the configuration file, to values under key. The nested objects are
+// flattened and the lists are joined by commas like the lists of the environment variables.
+func (values fileValues) add(key, name string, v interface{}) error {
+	var value string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if err := values.add(key+"_"+strings.ToUpper(k), joinName(name, k), e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, e := range v {
+			item, ok := scalar(e)
+			if !ok {
+				return fmt.Errorf("configuration `%s` must be a list of scalars", name)
+			}
+			items = append(items, item)
+		}
+		value = strings.Join(items, ",")
+	case nil:
+		return nil
+	default:
+		value, _ = scalar(v)
+	}
+	values[strings.ToUpper(key)] = &fileValue{name: name, value: value}
+	return nil
+}
+
+// checkUnused returns an error which lists the values of the file which don't belong to any config.
+func (values fileValues) checkUnused() error {
+	var names []string
+	for _, v := range values {
+		if !v.used {
+			names = append(names, "`"+v.name+"`")
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown configurations in configuration file: %s", strings.Join(names, ", "))
+}
+
+// process loads the environment variables of prefix to spec like envconfig.Process and then assigns the values of
+// the configuration file to the fields of spec whose environment variables are not set.
+func process(prefix string, spec interface{}, values fileValues) error {
+	if err := envconfig.Process(prefix, spec); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	// The keys of the fields are taken from envconfig, so they always match the environment variables.
+	var b bytes.Buffer
+	if err := envconfig.Usagef(prefix, spec, &b, "{{range .}}{{.Name}} {{usage_key .}}\n{{end}}"); err != nil {
+		return err
+	}
+	s := reflect.ValueOf(spec).Elem()
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		v, ok := values[parts[1]]
+		if !ok {
+			continue
+		}
+		v.used = true
+		f, _ := s.Type().FieldByName(parts[0])
+		if isEnvSet(parts[1], f.Tag.Get("envconfig")) {
+			continue
+		}
+		if err := setField(s.FieldByName(parts[0]), v.value); err != nil {
+			return fmt.Errorf("configuration `%s` is not valid: %w", v.name, err)
+		}
+	}
+	return nil
+}
+
+// isEnvSet returns true if the environment variable of key or its alternative name is set.
+func isEnvSet(key, alt string) bool {
+	if _, ok := os.LookupEnv(key); ok {
+		return true
+	}
+	if alt == "" {
+		return false
+	}
+	_, ok := os.LookupEnv(strings.ToUpper(alt))
+	return ok
+}
+
+// setField parses value by the type of f and assigns it to f.
+func setField(f reflect.Value, value string) error {
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		f.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("type %s is not supported", f.Type())
+	}
+	return nil
+}
+
+// scalar returns the string form of v if it's a string, a number or a boolean.
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// joinName joins the dotted name of an object of the configuration file and the key of one of its values.
+func joinName(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
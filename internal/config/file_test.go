@@ -0,0 +1,116 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unsetConfigOsEnvVariables unsets the env variables of the configs which are set by the other tests.
+func unsetConfigOsEnvVariables() {
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "DETECTIVE_") {
+			_ = os.Unsetenv(e[:strings.Index(e, "=")])
+		}
+	}
+}
+
+// writeConfigFile writes content to a config file with the extension ext and returns its path.
+func writeConfigFile(t *testing.T, ext, content string) string {
+	path := filepath.Join(t.TempDir(), "detective"+ext)
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("error while writing config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	unsetConfigOsEnvVariables()
+	defer unsetConfigOsEnvVariables()
+
+	path := writeConfigFile(t, ".yaml", `
+addr: ":8080"
+http_timeout: 10s
+max_document_size: 2048
+logger:
+  level: debug
+crawl:
+  max_depth: 5
+  concurrency: 2
+webhook:
+  urls:
+    - http://hooks.local/a
+    - http://hooks.local/b
+  secret: secret
+auth:
+  rate_limit: 2.5
+tracing:
+  insecure: false
+`)
+	_ = os.Setenv("DETECTIVE_CRAWL_CONCURRENCY", "8")
+
+	c, err := Load(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ":8080", c.Addr)
+	assert.Equal(t, 10*time.Second, c.HTTPTimeout)
+	assert.Equal(t, int64(2048), c.MaxDocumentSize)
+	assert.Equal(t, "debug", c.LoggerConfig.Level)
+	assert.Equal(t, 5, c.CrawlConfig.MaxDepth)
+	assert.Equal(t, []string{"http://hooks.local/a", "http://hooks.local/b"}, c.WebhookConfig.Urls)
+	assert.Equal(t, 2.5, c.AuthConfig.RateLimit)
+	assert.False(t, c.TracingConfig.Insecure)
+
+	// The env variables override the file and the defaults fill the rest.
+	assert.Equal(t, 8, c.CrawlConfig.Concurrency)
+	assert.Equal(t, 100, c.CrawlConfig.MaxPages)
+	assert.Equal(t, 4, c.JobWorkers)
+
+	// Json is valid yaml.
+	path = writeConfigFile(t, ".json", `{"sitemap": {"max_urls": 10}, "grpc": {"enabled": true}}`)
+	c, err = Load(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 10, c.SitemapConfig.MaxUrls)
+		assert.True(t, c.GRPCConfig.Enabled)
+	}
+}
+
+func TestLoadFailures(t *testing.T) {
+	unsetConfigOsEnvVariables()
+	defer unsetConfigOsEnvVariables()
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "invalid document", content: "crawl: [", expectedError: "error while parsing configuration file"},
+		{
+			name:          "unknown configurations",
+			content:       "crawl: {max_deep: 2}\nport: 80",
+			expectedError: "unknown configurations in configuration file: `crawl.max_deep`, `port`",
+		},
+		{name: "invalid type", content: "crawl: {max_depth: two}", expectedError: "configuration `crawl.max_depth` is not valid"},
+		{name: "invalid duration", content: "http_timeout: 10", expectedError: "configuration `http_timeout` is not valid"},
+		{name: "invalid list", content: "webhook: {urls: [{url: x}]}", expectedError: "must be a list of scalars"},
+		{name: "invalid value", content: "crawl: {max_pages: 0}", expectedError: "DETECTIVE_CRAWL_MAX_PAGES must be positive"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := Load(writeConfigFile(t, ".yaml", test.content))
+			assert.Nil(t, c)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.expectedError)
+			}
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces the secrets of the redacted configurations.
+const redactedValue = "REDACTED"
+
+// Redacted returns a copy of c which can be logged, the keys of API keys, the webhook secret and the passwords of the
+// proxy urls are replaced by REDACTED.
+func (c *AppConfig) Redacted() *AppConfig {
+	r := *c
+	if c.AuthConfig != nil {
+		auth := *c.AuthConfig
+		auth.Keys = make([]string, len(c.AuthConfig.Keys))
+		for i, k := range c.AuthConfig.Keys {
+			// Only the name of a `name:key` key is kept, a key without a name is redacted entirely.
+			auth.Keys[i] = redactedValue
+			if j := strings.Index(k, ":"); j >= 0 {
+				auth.Keys[i] = k[:j] + ":" + redactedValue
+			}
+		}
+		r.AuthConfig = &auth
+	}
+	if c.WebhookConfig != nil && c.WebhookConfig.Secret != "" {
+		webhook := *c.WebhookConfig
+		webhook.Secret = redactedValue
+		r.WebhookConfig = &webhook
+	}
+	if c.EgressConfig != nil {
+		egress := *c.EgressConfig
+		egress.HTTPProxy = redactURL(egress.HTTPProxy)
+		egress.HTTPSProxy = redactURL(egress.HTTPSProxy)
+		r.EgressConfig = &egress
+	}
+	return &r
+}
+
+// redactURL replaces the password of the user info of rawURL, rawURL is returned as is if it has no password.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); !ok {
+		return rawURL
+	}
+	u.User = url.UserPassword(u.User.Username(), redactedValue)
+	return u.String()
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// logLevels is the list of the levels which the logger accepts.
+var logLevels = []string{"debug", "info", "warn", "error", "fatal", "panic"}
+
+// tracingExporters is the list of the accepted span exporters.
+var tracingExporters = []string{"none", "stdout", "otlp"}
+
+// proxySchemes is the list of the accepted schemes of proxy urls.
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// Validate checks the values of c and its sub configs, the returned error lists all of the invalid values by the
+// names of their environment variables.
+func (c *AppConfig) Validate() error {
+	v := &validator{}
+	v.check(c.Addr != "", "DETECTIVE_ADDR", "must not be empty")
+	v.check(c.HTTPTimeout > 0, "DETECTIVE_HTTP_TIMEOUT", "must be positive")
+	v.check(c.JobWorkers > 0, "DETECTIVE_JOB_WORKERS", "must be positive")
+	v.check(c.JobQueueSize >= 0, "DETECTIVE_JOB_QUEUE_SIZE", "must not be negative")
+	v.check(c.JobTTL > 0, "DETECTIVE_JOB_TTL", "must be positive")
+	v.check(c.ShutdownTimeout >= 0, "DETECTIVE_SHUTDOWN_TIMEOUT", "must not be negative")
+	v.check(c.MaxDocumentSize >= 0, "DETECTIVE_MAX_DOCUMENT_SIZE", "must not be negative")
+
+	v.oneOf(strings.ToLower(c.LoggerConfig.Level), "DETECTIVE_LOGGER_LEVEL", logLevels)
+	v.check(c.CrawlConfig.MaxDepth >= 0, "DETECTIVE_CRAWL_MAX_DEPTH", "must not be negative")
+	v.check(c.CrawlConfig.MaxPages > 0, "DETECTIVE_CRAWL_MAX_PAGES", "must be positive")
+	v.check(c.CrawlConfig.Concurrency > 0, "DETECTIVE_CRAWL_CONCURRENCY", "must be positive")
+	v.check(c.SitemapConfig.MaxUrls > 0, "DETECTIVE_SITEMAP_MAX_URLS", "must be positive")
+	v.check(c.SitemapConfig.Concurrency > 0, "DETECTIVE_SITEMAP_CONCURRENCY", "must be positive")
+	v.check(c.RobotsConfig.CacheTTL >= 0, "DETECTIVE_ROBOTS_CACHE_TTL", "must not be negative")
+	v.check(!c.HistoryConfig.Enabled || c.HistoryConfig.Path != "", "DETECTIVE_HISTORY_PATH", "must not be empty")
+	v.check(c.HistoryConfig.MaxAge >= 0, "DETECTIVE_HISTORY_MAX_AGE", "must not be negative")
+	v.check(c.HistoryConfig.MaxRecords >= 0, "DETECTIVE_HISTORY_MAX_RECORDS", "must not be negative")
+	v.check(c.MonitorConfig.Concurrency > 0, "DETECTIVE_MONITOR_CONCURRENCY", "must be positive")
+	v.check(c.MonitorConfig.MinInterval > 0, "DETECTIVE_MONITOR_MIN_INTERVAL", "must be positive")
+	v.check(c.WebhookConfig.MaxRetries >= 0, "DETECTIVE_WEBHOOK_MAX_RETRIES", "must not be negative")
+	v.check(c.WebhookConfig.Backoff >= 0, "DETECTIVE_WEBHOOK_BACKOFF", "must not be negative")
+	v.check(c.WebhookConfig.Timeout > 0, "DETECTIVE_WEBHOOK_TIMEOUT", "must be positive")
+	v.check(len(c.WebhookConfig.Urls) == 0 || c.WebhookConfig.Secret != "", "DETECTIVE_WEBHOOK_SECRET",
+		"must not be empty when DETECTIVE_WEBHOOK_URLS is set")
+	v.oneOf(c.TracingConfig.Exporter, "DETECTIVE_TRACING_EXPORTER", tracingExporters)
+	v.check(c.TracingConfig.SampleRatio >= 0 && c.TracingConfig.SampleRatio <= 1,
+		"DETECTIVE_TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+	v.check(c.AuthConfig.RateLimit > 0, "DETECTIVE_AUTH_RATE_LIMIT", "must be positive")
+	v.check(c.AuthConfig.Burst > 0, "DETECTIVE_AUTH_BURST", "must be positive")
+	v.check(c.AuthConfig.DailyQuota != 0, "DETECTIVE_AUTH_DAILY_QUOTA", "must not be zero, use a negative value to disable the quota")
+	v.check(c.AdmissionConfig.MaxConcurrentAnalyses >= 0, "DETECTIVE_ADMISSION_MAX_CONCURRENT_ANALYSES", "must not be negative")
+	v.check(c.AdmissionConfig.MaxQueuedAnalyses >= 0, "DETECTIVE_ADMISSION_MAX_QUEUED_ANALYSES", "must not be negative")
+	v.check(c.AdmissionConfig.MaxQueueWait >= 0, "DETECTIVE_ADMISSION_MAX_QUEUE_WAIT", "must not be negative")
+	v.check(c.AdmissionConfig.MaxLinkProbes >= 0, "DETECTIVE_ADMISSION_MAX_LINK_PROBES", "must not be negative")
+	v.check(c.AdmissionConfig.RetryAfter >= 0, "DETECTIVE_ADMISSION_RETRY_AFTER", "must not be negative")
+	v.check(!c.GRPCConfig.Enabled || c.GRPCConfig.Addr != "", "DETECTIVE_GRPC_ADDR", "must not be empty")
+	v.check(!c.GRPCConfig.Enabled || c.GRPCConfig.Addr != c.Addr, "DETECTIVE_GRPC_ADDR", "must differ from DETECTIVE_ADDR")
+	v.proxy(c.EgressConfig.HTTPProxy, "DETECTIVE_EGRESS_HTTP_PROXY")
+	v.proxy(c.EgressConfig.HTTPSProxy, "DETECTIVE_EGRESS_HTTPS_PROXY")
+	v.check((c.EgressConfig.CertFile == "") == (c.EgressConfig.KeyFile == ""),
+		"DETECTIVE_EGRESS_CERT_FILE", "must be set together with DETECTIVE_EGRESS_KEY_FILE")
+
+	if len(v.problems) > 0 {
+		return errors.New("configuration is not valid: " + strings.Join(v.problems, "; "))
+	}
+	return nil
+}
+
+// validator collects the problems of a config.
+type validator struct {
+	problems []string
+}
+
+// check adds the problem of key if ok is false.
+func (v *validator) check(ok bool, key, problem string) {
+	if !ok {
+		v.problems = append(v.problems, key+" "+problem)
+	}
+}
+
+// oneOf adds a problem for key if value is not one of accepted.
+func (v *validator) oneOf(value, key string, accepted []string) {
+	for _, a := range accepted {
+		if value == a {
+			return
+		}
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s must be one of %s", key, strings.Join(accepted, ", ")))
+}
+
+// proxy adds a problem for key if value is neither empty nor a url with one of proxySchemes and a host.
+func (v *validator) proxy(value, key string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		v.problems = append(v.problems, key+" must be a url")
+		return
+	}
+	v.oneOf(u.Scheme, key+" scheme", proxySchemes)
+}
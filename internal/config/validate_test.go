@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConfig_Validate(t *testing.T) {
+	unsetConfigOsEnvVariables()
+	defer unsetConfigOsEnvVariables()
+
+	c, err := NewAppConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, c.Validate())
+
+	c.JobWorkers = 0
+	c.LoggerConfig.Level = "verbose"
+	c.HistoryConfig.MaxRecords = -1
+	c.WebhookConfig.Urls = []string{"http://hooks.local"}
+	c.TracingConfig.SampleRatio = 2
+	c.AuthConfig.DailyQuota = 0
+	c.GRPCConfig.Enabled = true
+	c.GRPCConfig.Addr = c.Addr
+	c.EgressConfig.HTTPProxy = "proxy.local"
+	c.EgressConfig.HTTPSProxy = "ftp://proxy.local"
+	c.EgressConfig.KeyFile = "client-key.pem"
+	assert.EqualError(t, c.Validate(), "configuration is not valid: "+
+		"DETECTIVE_JOB_WORKERS must be positive; "+
+		"DETECTIVE_LOGGER_LEVEL must be one of debug, info, warn, error, fatal, panic; "+
+		"DETECTIVE_HISTORY_MAX_RECORDS must not be negative; "+
+		"DETECTIVE_WEBHOOK_SECRET must not be empty when DETECTIVE_WEBHOOK_URLS is set; "+
+		"DETECTIVE_TRACING_SAMPLE_RATIO must be between 0 and 1; "+
+		"DETECTIVE_AUTH_DAILY_QUOTA must not be zero, use a negative value to disable the quota; "+
+		"DETECTIVE_GRPC_ADDR must differ from DETECTIVE_ADDR; "+
+		"DETECTIVE_EGRESS_HTTP_PROXY must be a url; "+
+		"DETECTIVE_EGRESS_HTTPS_PROXY scheme must be one of http, https, socks5; "+
+		"DETECTIVE_EGRESS_CERT_FILE must be set together with DETECTIVE_EGRESS_KEY_FILE")
+
+	c, _ = NewAppConfig()
+	c.EgressConfig.HTTPProxy = "http://proxy.local:3128"
+	c.EgressConfig.HTTPSProxy = "socks5://proxy.local:1080"
+	assert.NoError(t, c.Validate())
+}
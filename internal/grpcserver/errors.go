@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mammadmodi/detective/internal/handler"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain is the domain of the ErrorInfo details of the errors.
+const errorDomain = "detective"
+
+// errorCodes maps the error codes of the REST API to gRPC codes.
+var errorCodes = map[handler.ErrorCode]codes.Code{
+	handler.ErrorCodeInvalidRequest:         codes.InvalidArgument,
+	handler.ErrorCodeInvalidURL:             codes.InvalidArgument,
+	handler.ErrorCodeUnauthorized:           codes.Unauthenticated,
+	handler.ErrorCodeRobotsDisallowed:       codes.PermissionDenied,
+	handler.ErrorCodeNotFound:               codes.NotFound,
+	handler.ErrorCodeConflict:               codes.FailedPrecondition,
+	handler.ErrorCodeRateLimited:            codes.ResourceExhausted,
+	handler.ErrorCodeQuotaExceeded:          codes.ResourceExhausted,
+	handler.ErrorCodeUnsupportedContentType: codes.FailedPrecondition,
+	handler.ErrorCodeDocumentTooLarge:       codes.FailedPrecondition,
+	handler.ErrorCodeParseFailed:            codes.FailedPrecondition,
+	handler.ErrorCodeUpstreamStatus:         codes.Unavailable,
+	handler.ErrorCodeUpstreamUnreachable:    codes.Unavailable,
+	handler.ErrorCodeUpstreamTimeout:        codes.DeadlineExceeded,
+	handler.ErrorCodeCanceled:               codes.Canceled,
+	handler.ErrorCodeSaturated:              codes.Unavailable,
+	handler.ErrorCodeFeatureDisabled:        codes.Unimplemented,
+	handler.ErrorCodeShuttingDown:           codes.Unavailable,
+	handler.ErrorCodeInternal:               codes.Internal,
+}
+
+// statusError converts e to a gRPC status error with an ErrorInfo detail whose reason is the error code of e and
+// whose metadata holds the details of e.
+func statusError(e *handler.APIError) error {
+	return newStatus(e, 0).Err()
+}
+
+// retryableStatusError is like statusError but it also asks the client to retry after wait.
+func retryableStatusError(e *handler.APIError, wait time.Duration) error {
+	return newStatus(e, wait).Err()
+}
+
+// newStatus creates the status of statusError, a positive wait is added as a RetryInfo detail.
+func newStatus(e *handler.APIError, wait time.Duration) *status.Status {
+	code, ok := errorCodes[e.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	info := &errdetails.ErrorInfo{Reason: string(e.Code), Domain: errorDomain}
+	if d := e.Details; d != nil {
+		info.Metadata = make(map[string]string)
+		if d.UpstreamStatus != 0 {
+			info.Metadata["upstream_status"] = strconv.Itoa(d.UpstreamStatus)
+		}
+		if d.ContentType != "" {
+			info.Metadata["content_type"] = d.ContentType
+		}
+		if d.MaxSize != 0 {
+			info.Metadata["max_size"] = strconv.FormatInt(d.MaxSize, 10)
+		}
+		if d.Cause != "" {
+			info.Metadata["cause"] = d.Cause
+		}
+	}
+
+	s := status.New(code, e.Message)
+	details := []proto.Message{info}
+	if wait > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)})
+	}
+	withDetails, err := s.WithDetails(details...)
+	if err != nil {
+		return s
+	}
+	return withDetails
+}
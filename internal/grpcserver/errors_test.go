@@ -0,0 +1,40 @@
+package grpcserver
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	err := statusError(&handler.APIError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    handler.ErrorCodeUnsupportedContentType,
+		Message: "could not retrieve html body of url",
+		Details: &handler.ErrorDetails{UpstreamStatus: http.StatusOK, ContentType: "application/json", MaxSize: 10},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "could not retrieve html body of url", status.Convert(err).Message())
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeUnsupportedContentType), info.GetReason())
+		assert.Equal(t, errorDomain, info.GetDomain())
+		assert.Equal(t, map[string]string{
+			"upstream_status": "200",
+			"content_type":    "application/json",
+			"max_size":        "10",
+		}, info.GetMetadata())
+	}
+	assert.Zero(t, retryDelay(err))
+
+	err = retryableStatusError(&handler.APIError{Code: handler.ErrorCodeUpstreamTimeout}, time.Second)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, time.Second, retryDelay(err))
+
+	err = statusError(&handler.APIError{Code: "UNKNOWN"})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+}
@@ -0,0 +1,129 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/handler"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataAPIKey is the metadata key of the API key of calls, the bearer token of the authorization metadata is
+// accepted as well.
+var metadataAPIKey = strings.ToLower(handler.HeaderAPIKey)
+
+// guard applies the authentication, the rate limits, the daily quotas and the admission control of the handler to
+// the calls, every call of the service runs an analysis so all of them are charged and admitted.
+type guard struct {
+	handler *handler.HTTPHandler
+	logger  *zap.Logger
+}
+
+// unaryInterceptor guards the unary calls.
+func (g *guard) unaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	next grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, release, err := g.admit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return next(ctx, req)
+}
+
+// streamInterceptor guards the streaming calls.
+func (g *guard) streamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	next grpc.StreamHandler,
+) error {
+	ctx, release, err := g.admit(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return next(srv, &guardedStream{ServerStream: ss, ctx: ctx})
+}
+
+// guardedStream is a grpc.ServerStream whose context holds the authenticated client of the call.
+type guardedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *guardedStream) Context() context.Context {
+	return s.ctx
+}
+
+// admit authenticates the API key of the call, takes it from the rate limit of the client, holds a slot of the
+// admission controller for it and then takes it from the daily quota of the client, like the middlewares of the
+// REST endpoints, so the calls which aren't admitted don't use the quota. It returns a copy of ctx which holds the
+// authenticated client, so the client owns the analyses of the call, and the function which releases the slot.
+func (g *guard) admit(ctx context.Context, method string) (_ context.Context, release func(), err error) {
+	l := g.logger.With(zap.String("method", method))
+	k := g.handler.Keyring
+	key := apiKey(ctx)
+	if k != nil {
+		client, err := k.Authenticate(key)
+		if err != nil {
+			l.Warn("call with invalid api key rejected")
+			return nil, nil, statusError(&handler.APIError{Code: handler.ErrorCodeUnauthorized, Message: err.Error()})
+		}
+		ctx = auth.NewContext(ctx, client)
+		l = l.With(zap.String("client", client.Name))
+		if wait, err := k.Allow(key); err != nil {
+			l.Warn("call rejected by rate limit")
+			return nil, nil, retryableStatusError(&handler.APIError{Code: handler.ErrorCodeRateLimited, Message: err.Error()}, wait)
+		}
+	}
+
+	release = func() {}
+	if a := g.handler.Admission; a != nil {
+		release, err = a.Acquire(ctx)
+		if err != nil {
+			l.With(zap.Error(err)).Warn("analysis rejected by admission control")
+			return nil, nil, retryableStatusError(&handler.APIError{
+				Code:    handler.ErrorCodeSaturated,
+				Message: admission.ErrSaturated.Error(),
+			}, g.handler.RetryAfter)
+		}
+	}
+
+	if k != nil {
+		_, wait, err := k.Charge(key)
+		switch {
+		case errors.Is(err, auth.ErrQuotaExceeded):
+			release()
+			l.Warn("call rejected by daily quota")
+			return nil, nil, retryableStatusError(&handler.APIError{Code: handler.ErrorCodeQuotaExceeded, Message: err.Error()}, wait)
+		case err != nil:
+			release()
+			l.With(zap.Error(err)).Warn("call rejected because its quota could not be charged")
+			return nil, nil, statusError(&handler.APIError{Code: handler.ErrorCodeUnauthorized, Message: err.Error()})
+		}
+	}
+	return ctx, release, nil
+}
+
+// apiKey returns the API key of the incoming metadata of ctx.
+func apiKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(metadataAPIKey); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	const prefix = "bearer "
+	if a := md.Get("authorization"); len(a) > 0 && len(a[0]) > len(prefix) && strings.EqualFold(a[0][:len(prefix)], prefix) {
+		return a[0][len(prefix):]
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	detectivev1 "github.com/mammadmodi/detective/pkg/pb/detective/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// retryDelay returns the retry delay of the RetryInfo detail of err.
+func retryDelay(err error) time.Duration {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	return 0
+}
+
+func TestGuard_Authentication(t *testing.T) {
+	h := newTestHandler(nil)
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 100, Burst: 100, DailyQuota: 1},
+	)
+	client := newTestClient(t, h)
+	req := &detectivev1.AnalyzeHTMLRequest{BaseUrl: "invalid"}
+
+	_, err := client.AnalyzeHTML(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeUnauthorized), info.GetReason())
+	}
+
+	// The invalid request reaches the service, so the key is authenticated and charged.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer key-a")
+	_, err = client.AnalyzeHTML(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-a")
+	_, err = client.AnalyzeHTML(ctx, req)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeQuotaExceeded), info.GetReason())
+	}
+	assert.True(t, retryDelay(err) > 0)
+}
+
+func TestGuard_Admission(t *testing.T) {
+	h := newTestHandler(nil)
+	h.Admission = admission.NewController(1, 0, time.Millisecond)
+	h.RetryAfter = 5 * time.Second
+	client := newTestClient(t, h)
+
+	release, _ := h.Admission.Acquire(context.Background())
+	_, err := client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: "invalid"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeSaturated), info.GetReason())
+	}
+	assert.Equal(t, 5*time.Second, retryDelay(err))
+
+	release()
+	_, err = client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 0, h.Admission.Running())
+}
+
+func TestGuard_AdmissionBeforeQuota(t *testing.T) {
+	h := newTestHandler(nil)
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 100, Burst: 100, DailyQuota: 1},
+	)
+	h.Admission = admission.NewController(1, 0, time.Millisecond)
+	client := newTestClient(t, h)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-a")
+	req := &detectivev1.AnalyzeURLRequest{Url: "invalid"}
+
+	// The call which isn't admitted doesn't use the only analysis of the quota.
+	release, _ := h.Admission.Acquire(context.Background())
+	_, err := client.AnalyzeURL(ctx, req)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	release()
+
+	_, err = client.AnalyzeURL(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.AnalyzeURL(ctx, req)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, 0, h.Admission.Running())
+}
+
+func TestGuard_Owner(t *testing.T) {
+	page := newTestPage(t, `<!DOCTYPE html><title>Detective</title>`)
+	owners := make(chan string, 2)
+	h := newTestHandler(func(ctx context.Context, u *url.URL, body string) (*htmlanalysis.Result, error) {
+		owners <- auth.Owner(ctx)
+		return htmlanalysis.Analyze(ctx, u, body)
+	})
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 100, Burst: 100, DailyQuota: -1},
+	)
+	client := newTestClient(t, h)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-a")
+
+	// The authenticated client reaches the analyses of unary and streaming calls, so it owns their records.
+	_, err := client.AnalyzeURL(ctx, &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", <-owners)
+
+	stream, err := client.AnalyzeURLProgress(ctx, &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "team-a", <-owners)
+}
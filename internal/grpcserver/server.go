@@ -0,0 +1,197 @@
+// Package grpcserver serves the analyses of detective over gRPC.
+package grpcserver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	detectivev1 "github.com/mammadmodi/detective/pkg/pb/detective/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements detectivev1.AnalysisServiceServer with the fetch and analysis logic of the http handler, so
+// the gRPC and the REST APIs analyze pages the same way.
+type Service struct {
+	detectivev1.UnimplementedAnalysisServiceServer
+
+	handler *handler.HTTPHandler
+	logger  *zap.Logger
+}
+
+// NewService creates a Service which analyzes pages with h.
+func NewService(h *handler.HTTPHandler, l *zap.Logger) *Service {
+	return &Service{handler: h, logger: l}
+}
+
+// New creates a grpc.Server which serves the Service of h.
+// The calls are authenticated with the Keyring of h and admitted by its admission controller like the analyses of
+// the REST API, opts are appended to the options of the server.
+func New(h *handler.HTTPHandler, l *zap.Logger, opts ...grpc.ServerOption) *grpc.Server {
+	g := &guard{handler: h, logger: l}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(g.unaryInterceptor),
+		grpc.ChainStreamInterceptor(g.streamInterceptor),
+	)
+	s := grpc.NewServer(opts...)
+	detectivev1.RegisterAnalysisServiceServer(s, NewService(h, l))
+	return s
+}
+
+// AnalyzeURL fetches the html document of the url of req and analyzes it.
+func (s *Service) AnalyzeURL(ctx context.Context, req *detectivev1.AnalyzeURLRequest) (*detectivev1.AnalyzeResponse, error) {
+	analysisID, res, apiErr := s.handler.AnalyzeRawURL(ctx, req.GetUrl(), func(string, interface{}) {})
+	if apiErr != nil {
+		s.logger.With(zap.String("url", req.GetUrl()), zap.Error(apiErr)).Info("analysis of url failed")
+		return nil, statusError(apiErr)
+	}
+	return &detectivev1.AnalyzeResponse{AnalysisId: analysisID, Result: resultProto(res)}, nil
+}
+
+// AnalyzeHTML analyzes the html document of req.
+func (s *Service) AnalyzeHTML(ctx context.Context, req *detectivev1.AnalyzeHTMLRequest) (*detectivev1.AnalyzeResponse, error) {
+	res, apiErr := s.handler.AnalyzeHTML(ctx, req.GetBaseUrl(), req.GetHtml())
+	if apiErr != nil {
+		return nil, statusError(apiErr)
+	}
+	return &detectivev1.AnalyzeResponse{Result: resultProto(res)}, nil
+}
+
+// AnalyzeURLProgress analyzes the url of req and streams the fetch of the page and the outcome of checking each of
+// its links, the last message holds the result.
+func (s *Service) AnalyzeURLProgress(
+	req *detectivev1.AnalyzeURLRequest,
+	stream detectivev1.AnalysisService_AnalyzeURLProgressServer,
+) error {
+	ps := &progressSender{stream: stream}
+	send := ps.send
+	analysisID, res, apiErr := s.handler.AnalyzeRawURL(stream.Context(), req.GetUrl(), func(name string, data interface{}) {
+		switch name {
+		case handler.EventFetched:
+			u, _ := data.(string)
+			send(&detectivev1.AnalyzeURLProgressResponse{
+				Event: &detectivev1.AnalyzeURLProgressResponse_FetchedUrl{FetchedUrl: u},
+			})
+		case string(htmlanalysis.PhaseLinkChecked):
+			if lc, ok := data.(*htmlanalysis.LinkCheck); ok {
+				send(&detectivev1.AnalyzeURLProgressResponse{
+					Event: &detectivev1.AnalyzeURLProgressResponse_LinkChecked{LinkChecked: linkCheckProto(lc)},
+				})
+			}
+		}
+	})
+	if apiErr != nil {
+		_ = ps.close()
+		s.logger.With(zap.String("url", req.GetUrl()), zap.Error(apiErr)).Info("analysis of url failed")
+		return statusError(apiErr)
+	}
+
+	send(&detectivev1.AnalyzeURLProgressResponse{
+		Event: &detectivev1.AnalyzeURLProgressResponse_Result{
+			Result: &detectivev1.AnalyzeResponse{AnalysisId: analysisID, Result: resultProto(res)},
+		},
+	})
+	return ps.close()
+}
+
+// progressSender sends the messages of AnalyzeURLProgress to its stream.
+// The events of link checks are emitted from different go routines, but a stream must not be sent to concurrently
+// nor after its handler returns, which the link checks of an interrupted analysis may still do. The first error of
+// the stream is kept and the messages after it or after close are dropped.
+type progressSender struct {
+	stream detectivev1.AnalysisService_AnalyzeURLProgressServer
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// send sends msg to the stream unless the sender is closed or the stream has failed.
+func (p *progressSender) send(msg *detectivev1.AnalyzeURLProgressResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed && p.err == nil {
+		p.err = p.stream.Send(msg)
+	}
+}
+
+// close drops the messages which are sent after it and returns the first error of the stream.
+// It must be called before the handler returns.
+func (p *progressSender) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return p.err
+}
+
+// resultProto converts r to its protobuf message.
+func resultProto(r *htmlanalysis.Result) *detectivev1.Result {
+	if r == nil {
+		return nil
+	}
+	p := &detectivev1.Result{
+		HtmlVersion:             r.HTMLVersion,
+		PageTitle:               r.PageTitle,
+		InaccessibleLinksCount:  int32(r.InaccessibleLinksCount),
+		InaccessibleLinks:       r.InaccessibleLinks,
+		RobotsSkippedLinksCount: int32(r.RobotsSkippedLinksCount),
+		HasLoginForm:            r.HasLoginForm,
+		CanonicalUrl:            r.CanonicalURL,
+	}
+	if hc := r.HeadingsCount; hc != nil {
+		p.HeadingsCount = &detectivev1.HeadingsCount{
+			H1: int32(hc.H1),
+			H2: int32(hc.H2),
+			H3: int32(hc.H3),
+			H4: int32(hc.H4),
+			H5: int32(hc.H5),
+			H6: int32(hc.H6),
+		}
+	}
+	if lc := r.LinksCount; lc != nil {
+		p.LinksCount = &detectivev1.LinksCount{Internal: int32(lc.Internal), External: int32(lc.External)}
+	}
+	if t := r.TLS; t != nil {
+		p.Tls = &detectivev1.TLSReport{
+			Version:          t.Version,
+			CipherSuite:      t.CipherSuite,
+			Certificate:      certificateProto(t.Certificate),
+			HostnameMismatch: t.HostnameMismatch,
+		}
+		for _, c := range t.Chain {
+			p.Tls.Chain = append(p.Tls.Chain, certificateProto(c))
+		}
+	}
+	return p
+}
+
+// certificateProto converts c to its protobuf message.
+func certificateProto(c *htmlanalysis.Certificate) *detectivev1.Certificate {
+	if c == nil {
+		return nil
+	}
+	return &detectivev1.Certificate{
+		Subject:       c.Subject,
+		Issuer:        c.Issuer,
+		Sans:          c.SANs,
+		NotBefore:     timestamppb.New(c.NotBefore),
+		NotAfter:      timestamppb.New(c.NotAfter),
+		DaysRemaining: int32(c.DaysRemaining),
+		SelfSigned:    c.SelfSigned,
+	}
+}
+
+// linkCheckProto converts lc to its protobuf message.
+func linkCheckProto(lc *htmlanalysis.LinkCheck) *detectivev1.LinkCheck {
+	return &detectivev1.LinkCheck{
+		Url:        lc.URL,
+		Accessible: lc.Accessible,
+		Skipped:    lc.Skipped,
+		Checked:    int32(lc.Checked),
+		Total:      int32(lc.Total),
+		Outcome:    string(lc.Outcome),
+	}
+}
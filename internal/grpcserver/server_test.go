@@ -0,0 +1,248 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mammadmodi/detective/internal/handler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	detectivev1 "github.com/mammadmodi/detective/pkg/pb/detective/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the gRPC server of h on an in-memory listener and returns a client of it.
+// The server and the client are closed when the test finishes.
+func newTestClient(t *testing.T, h *handler.HTTPHandler) detectivev1.AnalysisServiceClient {
+	ln := bufconn.Listen(1 << 20)
+	s := New(h, zap.NewNop())
+	go func() { _ = s.Serve(ln) }()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("error while dialing grpc server: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		s.Stop()
+	})
+	return detectivev1.NewAnalysisServiceClient(conn)
+}
+
+// newTestHandler creates an HTTPHandler which analyzes pages with analyze.
+func newTestHandler(analyze handler.HTMLAnalyzeFunc) *handler.HTTPHandler {
+	return &handler.HTTPHandler{
+		HTTPClient:      http.DefaultClient,
+		Logger:          zap.NewNop(),
+		HTMLAnalyzeFunc: analyze,
+	}
+}
+
+// newTestPage serves body as an html page until the test finishes.
+func newTestPage(t *testing.T, body string) *httptest.Server {
+	page := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, body)
+	}))
+	t.Cleanup(page.Close)
+	return page
+}
+
+// errorInfo returns the ErrorInfo detail of err.
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestService_AnalyzeURL(t *testing.T) {
+	page := newTestPage(t, "<!DOCTYPE html><title>Detective</title>")
+	client := newTestClient(t, newTestHandler(htmlanalysis.Analyze))
+
+	res, err := client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "HTML 5", res.GetResult().GetHtmlVersion())
+	assert.Equal(t, "Detective", res.GetResult().GetPageTitle())
+	assert.NotNil(t, res.GetResult().GetHeadingsCount())
+
+	_, err = client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: page.URL + "/missing"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeUpstreamStatus), info.GetReason())
+		assert.Equal(t, "404", info.GetMetadata()["upstream_status"])
+	}
+
+	_, err = client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_AnalyzeURLTLS(t *testing.T) {
+	page := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title>")
+	}))
+	defer page.Close()
+	h := newTestHandler(htmlanalysis.Analyze)
+	h.HTTPClient = page.Client()
+	client := newTestClient(t, h)
+
+	res, err := client.AnalyzeURL(context.Background(), &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	leaf := page.Certificate()
+	tls := res.GetResult().GetTls()
+	if !assert.NotNil(t, tls) {
+		return
+	}
+	assert.NotEmpty(t, tls.GetVersion())
+	assert.NotEmpty(t, tls.GetCipherSuite())
+	assert.False(t, tls.GetHostnameMismatch())
+	assert.Equal(t, leaf.Subject.String(), tls.GetCertificate().GetSubject())
+	assert.True(t, leaf.NotAfter.Equal(tls.GetCertificate().GetNotAfter().AsTime()))
+	assert.True(t, tls.GetCertificate().GetSelfSigned())
+	assert.Len(t, tls.GetChain(), 1)
+}
+
+func TestService_AnalyzeHTML(t *testing.T) {
+	client := newTestClient(t, newTestHandler(func(_ context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error) {
+		if htmlDoc == "" {
+			return nil, errors.New("empty document")
+		}
+		return &htmlanalysis.Result{PageTitle: u.Host}, nil
+	}))
+
+	res, err := client.AnalyzeHTML(context.Background(), &detectivev1.AnalyzeHTMLRequest{
+		Html:    "<!DOCTYPE html>",
+		BaseUrl: "https://example.com",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "example.com", res.GetResult().GetPageTitle())
+		assert.Empty(t, res.GetAnalysisId())
+	}
+
+	_, err = client.AnalyzeHTML(context.Background(), &detectivev1.AnalyzeHTMLRequest{BaseUrl: "https://example.com"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeParseFailed), info.GetReason())
+		assert.Equal(t, "empty document", info.GetMetadata()["cause"])
+	}
+}
+
+func TestService_AnalyzeURLProgress(t *testing.T) {
+	page := newTestPage(t, `<!DOCTYPE html><title>Detective</title><a href="/">home</a><a href="/missing">missing</a>`)
+	client := newTestClient(t, newTestHandler(htmlanalysis.Analyze))
+
+	stream, err := client.AnalyzeURLProgress(context.Background(), &detectivev1.AnalyzeURLRequest{Url: page.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var msgs []*detectivev1.AnalyzeURLProgressResponse
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if !assert.Len(t, msgs, 4) {
+		return
+	}
+	assert.Equal(t, page.URL, msgs[0].GetFetchedUrl())
+	accessible := map[string]bool{}
+	outcomes := map[string]string{}
+	for _, msg := range msgs[1:3] {
+		lc := msg.GetLinkChecked()
+		if assert.NotNil(t, lc) {
+			assert.Equal(t, int32(2), lc.GetTotal())
+			accessible[lc.GetUrl()] = lc.GetAccessible()
+			outcomes[lc.GetUrl()] = lc.GetOutcome()
+		}
+	}
+	assert.Equal(t, map[string]bool{page.URL + "/": true, page.URL + "/missing": false}, accessible)
+	assert.Equal(t, map[string]string{
+		page.URL + "/":        string(htmlanalysis.LinkOutcomeAccessible),
+		page.URL + "/missing": string(htmlanalysis.LinkOutcomeClientError),
+	}, outcomes)
+	assert.Equal(t, int32(1), msgs[3].GetResult().GetResult().GetInaccessibleLinksCount())
+}
+
+func TestService_AnalyzeURLProgressRedirect(t *testing.T) {
+	page := newTestPage(t, `<!DOCTYPE html><title>Detective</title>`)
+	redirect := httptest.NewServer(http.RedirectHandler(page.URL, http.StatusMovedPermanently))
+	defer redirect.Close()
+	client := newTestClient(t, newTestHandler(htmlanalysis.Analyze))
+
+	stream, err := client.AnalyzeURLProgress(context.Background(), &detectivev1.AnalyzeURLRequest{Url: redirect.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg, err := stream.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, page.URL, msg.GetFetchedUrl())
+	}
+}
+
+// testProgressStream is an AnalyzeURLProgress stream which records the sent messages.
+type testProgressStream struct {
+	detectivev1.AnalysisService_AnalyzeURLProgressServer
+	sent []*detectivev1.AnalyzeURLProgressResponse
+}
+
+func (s *testProgressStream) Send(msg *detectivev1.AnalyzeURLProgressResponse) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestProgressSender(t *testing.T) {
+	stream := &testProgressStream{}
+	ps := &progressSender{stream: stream}
+	msg := &detectivev1.AnalyzeURLProgressResponse{}
+
+	ps.send(msg)
+	assert.NoError(t, ps.close())
+	// The link checks of an interrupted analysis may emit events after the handler returns.
+	ps.send(msg)
+	assert.Len(t, stream.sent, 1)
+}
+
+func TestService_AnalyzeURLProgressFailure(t *testing.T) {
+	client := newTestClient(t, newTestHandler(htmlanalysis.Analyze))
+
+	stream, err := client.AnalyzeURLProgress(context.Background(), &detectivev1.AnalyzeURLRequest{Url: "invalid"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	if info := errorInfo(err); assert.NotNil(t, info) {
+		assert.Equal(t, string(handler.ErrorCodeInvalidURL), info.GetReason())
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/admission"
+	"go.uber.org/zap"
+)
+
+// Admit is a middleware which holds a slot of the admission controller while the request runs its analysis.
+// The requests which are not admitted get a 503 response with a Retry-After header.
+// It passes all the requests when the handler has no admission controller.
+func (h *HTTPHandler) Admit(c *gin.Context) {
+	if h.Admission == nil {
+		c.Next()
+		return
+	}
+
+	release, err := h.Admission.Acquire(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, admission.ErrSaturated) {
+			h.requestLogger(c).Warn("analysis rejected by admission control")
+		} else {
+			h.requestLogger(c).With(zap.Error(err)).Info("client gave up while waiting for admission")
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(h.RetryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &Response{
+			Error:     admission.ErrSaturated.Error(),
+			ErrorCode: ErrorCodeSaturated,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+	defer release()
+	c.Next()
+}
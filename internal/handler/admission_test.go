@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_Admit(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.Admission = admission.NewController(1, 0, time.Second)
+	h.RetryAfter = 1500 * time.Millisecond
+
+	running := make(chan struct{})
+	unblock := make(chan struct{})
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/analyze", h.Admit, func(c *gin.Context) {
+		close(running)
+		<-unblock
+		c.JSON(http.StatusOK, &Response{Code: http.StatusOK})
+	})
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "/analyze", nil)
+		r.ServeHTTP(first, req)
+		close(done)
+	}()
+	<-running
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/analyze", nil)
+	r.ServeHTTP(res, req)
+	var resp Response
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, admission.ErrSaturated.Error(), resp.Error)
+	assert.Equal(t, "2", res.Header().Get("Retry-After"))
+
+	close(unblock)
+	<-done
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, 0, h.Admission.Running())
+}
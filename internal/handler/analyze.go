@@ -2,14 +2,22 @@ package handler
 
 import (
 	"context"
-	"errors"
+	"crypto/tls"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -19,90 +27,215 @@ type URLRequest struct {
 }
 
 // Response is a struct which is returned to user on the analyze request.
+// AnalysisID is the id of the stored analysis when the analysis history is enabled.
+// Verdict is the outcome of the rules of the handler on the result when the handler has rules.
+// ErrorCode and Details describe the error in a machine readable form.
 type Response struct {
-	Result *htmlanalysis.Result `json:"result"`
-	Error  string               `json:"error"`
-	Code   int                  `json:"code"`
+	AnalysisID string               `json:"analysis_id,omitempty"`
+	Result     *htmlanalysis.Result `json:"result"`
+	Verdict    *rules.Verdict       `json:"verdict,omitempty"`
+	Error      string               `json:"error"`
+	ErrorCode  ErrorCode            `json:"error_code,omitempty"`
+	Details    *ErrorDetails        `json:"details,omitempty"`
+	Code       int                  `json:"code"`
 }
 
 // AnalyzeURL gets an URLRequest and analyzes the content of the html returned by url.
+// The result is reported in the format which is negotiated by the Accept header, json by default.
 func (h *HTTPHandler) AnalyzeURL(c *gin.Context) {
 	req := URLRequest{}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.Logger.With(zap.Error(err)).Error("error while binding request body")
-		c.AbortWithStatusJSON(http.StatusNotAcceptable, &Response{
-			Error: "cannot parse request body",
-			Code:  http.StatusNotAcceptable,
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &Response{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
 		})
 		return
 	}
-	h.Logger.With(zap.Any("request", req)).Info("request body bound successfully")
+	h.requestLogger(c).With(zap.Any("request", req)).Info("request body bound successfully")
 
 	u, err := url.ParseRequestURI(req.URL)
 	if err != nil {
-		h.Logger.With(zap.Error(err)).Error("requested url is not valid")
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
 		c.AbortWithStatusJSON(http.StatusBadRequest, &Response{
-			Error: "entered url is not valid",
-			Code:  http.StatusBadRequest,
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
 		})
 		return
 	}
-	h.Logger.With(zap.Any("entered_url", u)).Info("entered url parsed successfully")
+	h.requestLogger(c).With(zap.Any("entered_url", u)).Info("entered url parsed successfully")
 
-	htmlDoc, err := h.performGetRequest(c.Request.Context(), u)
+	p, err := h.fetchPage(c.Request.Context(), u)
 	if err != nil {
-		h.Logger.With(zap.Error(err)).Error("error while performing request")
-		c.AbortWithStatusJSON(http.StatusPreconditionFailed, &Response{
-			Error: "could not retrieve html body of url",
-			Code:  http.StatusPreconditionFailed,
-		})
+		h.requestLogger(c).With(zap.Error(err)).Error("error while performing request")
+		abortWithAPIError(c, fetchAPIError(err))
 		return
 	}
-	h.Logger.Info("request performed successfully")
+	h.requestLogger(c).Info("request performed successfully")
 
-	res, err := h.HTMLAnalyzeFunc(c.Request.Context(), u, htmlDoc)
+	res, err := h.HTMLAnalyzeFunc(c.Request.Context(), u, p.HTML)
 	if err != nil {
-		h.Logger.With(zap.Error(err)).Error("error while parsing html")
-		c.AbortWithStatusJSON(http.StatusPreconditionFailed, &Response{
-			Error: "error while parsing html",
-			Code:  http.StatusPreconditionFailed,
-		})
+		h.requestLogger(c).With(zap.Error(err)).Error("error while parsing html")
+		abortWithAPIError(c, analysisAPIError(err))
+		return
+	}
+	if err := c.Request.Context().Err(); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Warn("analysis was interrupted")
+		abortWithAPIError(c, interruptedAPIError(err))
 		return
 	}
-	h.Logger.With(zap.Any("result", res)).Info("html analyzed successfully")
+	res.TLS = h.tlsReport(p)
+	h.requestLogger(c).With(zap.Any("result", res)).Info("html analyzed successfully")
 
+	analysisID := h.saveAnalysis(c.Request.Context(), u, res)
+	if r := reporter(c); r != nil {
+		h.writeReport(c, r, h.resultPages(u.String(), res))
+		return
+	}
 	c.JSON(http.StatusOK, Response{
-		Result: res,
-		Code:   http.StatusOK,
+		AnalysisID: analysisID,
+		Result:     res,
+		Verdict:    h.evaluate(u.String(), res),
+		Code:       http.StatusOK,
 	})
 }
 
-// performGetRequest performs a GET request to url returns a html string if it has.
-func (h *HTTPHandler) performGetRequest(ctx context.Context, u *url.URL) (html string, err error) {
+// AnalyzeHTML analyzes htmlDoc which is sent by the client, its relative links are resolved against rawBaseURL.
+// The documents which are larger than MaxDocumentSize are rejected and the result is not stored in the analysis
+// history because the document doesn't belong to a fetched page.
+func (h *HTTPHandler) AnalyzeHTML(ctx context.Context, rawBaseURL, htmlDoc string) (*htmlanalysis.Result, *APIError) {
+	u, err := url.ParseRequestURI(rawBaseURL)
+	if err != nil || u.Host == "" {
+		h.logger(ctx).With(zap.Error(err)).Error("entered base url is not valid")
+		return nil, newAPIError(ErrorCodeInvalidURL, "entered base url is not valid", nil)
+	}
+	if h.MaxDocumentSize > 0 && int64(len(htmlDoc)) > h.MaxDocumentSize {
+		return nil, newAPIError(ErrorCodeDocumentTooLarge, "html document is too large", &ErrorDetails{MaxSize: h.MaxDocumentSize})
+	}
+
+	res, err := h.HTMLAnalyzeFunc(ctx, u, htmlDoc)
+	if err != nil {
+		h.logger(ctx).With(zap.Error(err)).Error("error while parsing html")
+		return nil, analysisAPIError(err)
+	}
+	h.logger(ctx).With(zap.Any("result", res)).Info("html analyzed successfully")
+	return res, nil
+}
+
+// fetchedPage is a page which is retrieved by fetchPage.
+// URL is the url of the page after following redirects and TLS is the state of its connection, which is nil for the
+// http pages.
+type fetchedPage struct {
+	URL  *url.URL
+	HTML string
+	TLS  *tls.ConnectionState
+}
+
+// tlsReport returns the certificate health of the connection of p at the current time of the handler, it's nil for
+// the http pages.
+func (h *HTTPHandler) tlsReport(p *fetchedPage) *htmlanalysis.TLSReport {
+	return htmlanalysis.NewTLSReport(p.TLS, p.URL.Hostname(), h.now())
+}
+
+// performGetRequest performs a GET request to url and returns the url of the page after following redirects, its html
+// string and the certificate health of its connection, which is nil for the http pages.
+// It returns robots.ErrDisallowed if the robots.txt of the host disallows the url and a *FetchError which
+// categorizes the failure if the page can't be retrieved.
+func (h *HTTPHandler) performGetRequest(
+	ctx context.Context,
+	u *url.URL,
+) (*url.URL, string, *htmlanalysis.TLSReport, error) {
+	p, err := h.fetchPage(ctx, u)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return p.URL, p.HTML, h.tlsReport(p), nil
+}
+
+// fetchPage is like performGetRequest but it returns the fetched page with the state of its connection. The fetch is traced by a span which is named after performGetRequest.
+func (h *HTTPHandler) fetchPage(ctx context.Context, u *url.URL) (p *fetchedPage, err error) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "http_handler.performGetRequest",
+		trace.WithAttributes(attribute.String("url", u.String())))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(attribute.String("final_url", p.URL.String()), attribute.Int("size", len(p.HTML)))
+		}
+		span.End()
+	}()
+
+	if h.RobotsChecker != nil {
+		if err := h.RobotsChecker.Wait(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	p, err = h.getPage(ctx, u)
+	size := 0
+	if p != nil {
+		size = len(p.HTML)
+	}
+	h.Metrics.ObservePageFetch(time.Since(start), size, err)
+	return p, err
+}
+
+// getPage performs the GET request of fetchPage, the returned errors are *FetchError.
+func (h *HTTPHandler) getPage(ctx context.Context, u *url.URL) (*fetchedPage, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
-		return "", errors.New("error while creating HTTP request")
+		return nil, &FetchError{Code: ErrorCodeInvalidURL, Err: fmt.Errorf("error while creating HTTP request: %w", err)}
+	}
+	if h.RobotsChecker != nil {
+		req.Header.Set("User-Agent", h.RobotsChecker.UserAgent())
 	}
 
 	resp, err := h.HTTPClient.Do(req)
 	if err != nil {
-		return "", errors.New("error while performing HTTP request")
+		return nil, newFetchError(fmt.Errorf("error while performing HTTP request: %w", err))
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New("could not get a response from url")
+		return nil, &FetchError{
+			Code:       ErrorCodeUpstreamStatus,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("url responded with status code %d", resp.StatusCode),
+		}
 	}
 
 	t := resp.Header.Get("Content-Type")
 	if !strings.HasPrefix(t, "text/html") {
-		return "", errors.New("response content type wasn't text/html")
+		return nil, &FetchError{
+			Code:        ErrorCodeUnsupportedContentType,
+			StatusCode:  resp.StatusCode,
+			ContentType: t,
+			Err:         fmt.Errorf("response content type `%s` isn't text/html", t),
+		}
 	}
 
-	b, err := ioutil.ReadAll(resp.Body)
+	body := io.Reader(resp.Body)
+	if h.MaxDocumentSize > 0 {
+		// One more byte than the limit is read to find out whether the document exceeds it.
+		body = io.LimitReader(resp.Body, h.MaxDocumentSize+1)
+	}
+	b, err := ioutil.ReadAll(body)
 	if err != nil {
-		return "", errors.New("could not read the response body")
+		return nil, newFetchError(fmt.Errorf("could not read the response body: %w", err))
+	}
+	if h.MaxDocumentSize > 0 && int64(len(b)) > h.MaxDocumentSize {
+		return nil, &FetchError{
+			Code:        ErrorCodeDocumentTooLarge,
+			StatusCode:  resp.StatusCode,
+			ContentType: t,
+			MaxSize:     h.MaxDocumentSize,
+			Err:         fmt.Errorf("document is larger than %d bytes", h.MaxDocumentSize),
+		}
 	}
 
-	return string(b), nil
+	return &fetchedPage{URL: resp.Request.URL, HTML: string(b), TLS: resp.TLS}, nil
 }
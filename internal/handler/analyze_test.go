@@ -6,7 +6,12 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
@@ -14,6 +19,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func newTestHTTPHandler() *HTTPHandler {
@@ -38,11 +44,12 @@ func TestHTTPHandler_AnalyzeURLIncorrectBody(t *testing.T) {
 	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
 	expectedResponse := Response{
-		Error: "cannot parse request body",
-		Code:  http.StatusNotAcceptable,
+		Error:     "cannot parse request body",
+		ErrorCode: ErrorCodeInvalidRequest,
+		Code:      http.StatusBadRequest,
 	}
 	assert.Equal(t, expectedResponse, actualResponse)
-	assert.Equal(t, http.StatusNotAcceptable, res.Code)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
 }
 
 func TestHTTPHandler_AnalyzeURLBadRequest(t *testing.T) {
@@ -69,8 +76,9 @@ func TestHTTPHandler_AnalyzeURLBadRequest(t *testing.T) {
 	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
 	expectedResponse := Response{
-		Error: "entered url is not valid",
-		Code:  http.StatusBadRequest,
+		Error:     "entered url is not valid",
+		ErrorCode: ErrorCodeInvalidURL,
+		Code:      http.StatusBadRequest,
 	}
 	assert.Equal(t, expectedResponse, actualResponse)
 	assert.Equal(t, http.StatusBadRequest, res.Code)
@@ -100,12 +108,13 @@ func TestHTTPHandler_AnalyzeURLInaccessibleURL(t *testing.T) {
 		var actualResponse Response
 		_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
-		expectedResponse := Response{
-			Error: "could not retrieve html body of url",
-			Code:  http.StatusPreconditionFailed,
+		assert.Equal(t, "could not retrieve html body of url", actualResponse.Error)
+		assert.Equal(t, ErrorCodeUpstreamUnreachable, actualResponse.ErrorCode)
+		if assert.NotNil(t, actualResponse.Details) {
+			assert.Contains(t, actualResponse.Details.Cause, "connection refused")
 		}
-		assert.Equal(t, expectedResponse, actualResponse)
-		assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+		assert.Equal(t, http.StatusBadGateway, actualResponse.Code)
+		assert.Equal(t, http.StatusBadGateway, res.Code)
 	})
 
 	t.Run("when url doesn't return 2xx response", func(t *testing.T) {
@@ -134,12 +143,12 @@ func TestHTTPHandler_AnalyzeURLInaccessibleURL(t *testing.T) {
 		var actualResponse Response
 		_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
-		expectedResponse := Response{
-			Error: "could not retrieve html body of url",
-			Code:  http.StatusPreconditionFailed,
+		assert.Equal(t, "could not retrieve html body of url", actualResponse.Error)
+		assert.Equal(t, ErrorCodeUpstreamStatus, actualResponse.ErrorCode)
+		if assert.NotNil(t, actualResponse.Details) {
+			assert.Equal(t, http.StatusNotFound, actualResponse.Details.UpstreamStatus)
 		}
-		assert.Equal(t, expectedResponse, actualResponse)
-		assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+		assert.Equal(t, http.StatusBadGateway, res.Code)
 	})
 
 	t.Run("when url returns non html response in body", func(t *testing.T) {
@@ -169,12 +178,40 @@ func TestHTTPHandler_AnalyzeURLInaccessibleURL(t *testing.T) {
 		var actualResponse Response
 		_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
-		expectedResponse := Response{
-			Error: "could not retrieve html body of url",
-			Code:  http.StatusPreconditionFailed,
+		assert.Equal(t, "could not retrieve html body of url", actualResponse.Error)
+		assert.Equal(t, ErrorCodeUnsupportedContentType, actualResponse.ErrorCode)
+		if assert.NotNil(t, actualResponse.Details) {
+			assert.Equal(t, "application/json", actualResponse.Details.ContentType)
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	})
+
+	t.Run("when url returns a document larger than the limit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+			res.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title>")
+		}))
+		defer server.Close()
+
+		h := newTestHTTPHandler()
+		h.MaxDocumentSize = 10
+		b, _ := json.Marshal(URLRequest{URL: server.URL})
+
+		res := httptest.NewRecorder()
+		ginCtx, r := gin.CreateTestContext(res)
+		r.POST("/analyze-url", h.AnalyzeURL)
+
+		ginCtx.Request, _ = http.NewRequest(http.MethodPost, "/analyze-url", strings.NewReader(string(b)))
+		r.ServeHTTP(res, ginCtx.Request)
+
+		var actualResponse Response
+		_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
+
+		assert.Equal(t, ErrorCodeDocumentTooLarge, actualResponse.ErrorCode)
+		if assert.NotNil(t, actualResponse.Details) {
+			assert.Equal(t, int64(10), actualResponse.Details.MaxSize)
 		}
-		assert.Equal(t, expectedResponse, actualResponse)
-		assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
 	})
 }
 
@@ -213,11 +250,13 @@ func TestHTTPHandler_AnalyzeURLNotParsableHTML(t *testing.T) {
 	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
 
 	expectedResponse := Response{
-		Error: "error while parsing html",
-		Code:  http.StatusPreconditionFailed,
+		Error:     "error while parsing html",
+		ErrorCode: ErrorCodeParseFailed,
+		Details:   &ErrorDetails{Cause: "cannot parse html"},
+		Code:      http.StatusUnprocessableEntity,
 	}
 	assert.Equal(t, expectedResponse, actualResponse)
-	assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
 }
 
 func TestHTTPHandler_AnalyzeURLSuccess(t *testing.T) {
@@ -263,3 +302,139 @@ func TestHTTPHandler_AnalyzeURLSuccess(t *testing.T) {
 	assert.Empty(t, actualResponse.Error)
 	assert.Equal(t, http.StatusOK, res.Code)
 }
+
+func TestHTTPHandler_AnalyzeURLTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title>")
+	}))
+	defer server.Close()
+
+	// The certificate is evaluated at the time of the clock of the handler.
+	now := server.Certificate().NotAfter.Add(-36 * time.Hour)
+	h := newTestHTTPHandler()
+	h.HTTPClient = server.Client()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	h.Now = func() time.Time { return now }
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/analyze-url", h.AnalyzeURL)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/analyze-url", strings.NewReader(`{"url": "`+server.URL+`"}`))
+	r.ServeHTTP(res, req)
+
+	var actualResponse Response
+	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
+	if assert.Equal(t, http.StatusOK, res.Code) && assert.NotNil(t, actualResponse.Result.TLS) {
+		report := actualResponse.Result.TLS
+		assert.Equal(t, "TLS 1.3", report.Version)
+		assert.NotEmpty(t, report.CipherSuite)
+		assert.Contains(t, report.Certificate.SANs, "127.0.0.1")
+		assert.True(t, report.Certificate.SelfSigned)
+		assert.Equal(t, 1, report.Certificate.DaysRemaining)
+		assert.False(t, report.HostnameMismatch)
+		assert.Len(t, report.Chain, 1)
+	}
+}
+
+func TestHTTPHandler_AnalyzeURLDisallowedByRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/robots.txt" {
+			_, _ = io.WriteString(res, "User-agent: detective\nDisallow: /private")
+			return
+		}
+		assert.Equal(t, "detective", req.Header.Get("User-Agent"))
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html>")
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	h.RobotsChecker = robots.NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/analyze-url", h.AnalyzeURL)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/analyze-url", strings.NewReader(`{"url": "`+server.URL+`/private"}`))
+	r.ServeHTTP(res, req)
+
+	var actualResponse Response
+	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
+	assert.Equal(t, Response{
+		Error:     "url is disallowed by robots.txt",
+		ErrorCode: ErrorCodeRobotsDisallowed,
+		Code:      http.StatusForbidden,
+	}, actualResponse)
+	assert.Equal(t, http.StatusForbidden, res.Code)
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/analyze-url", strings.NewReader(`{"url": "`+server.URL+`/public"}`))
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestHTTPHandler_PerformGetRequestSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<!DOCTYPE html>")
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	_, _, _, err := newTestHTTPHandler().performGetRequest(context.Background(), u)
+	assert.NoError(t, err)
+	u, _ = url.Parse("http://localhost:22222/")
+	_, _, _, err = newTestHTTPHandler().performGetRequest(context.Background(), u)
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "http_handler.performGetRequest", spans[0].Name)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+	}
+}
+
+func TestHTTPHandler_AnalyzeHTML(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = func(_ context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error) {
+		if htmlDoc == "" {
+			return nil, errors.New("empty document")
+		}
+		return &htmlanalysis.Result{PageTitle: u.Host}, nil
+	}
+	h.MaxDocumentSize = 20
+
+	res, apiErr := h.AnalyzeHTML(context.Background(), "https://example.com", "<!DOCTYPE html>")
+	if assert.Nil(t, apiErr) {
+		assert.Equal(t, "example.com", res.PageTitle)
+	}
+
+	tests := []struct {
+		name         string
+		baseURL      string
+		htmlDoc      string
+		expectedCode ErrorCode
+	}{
+		{name: "invalid base url", baseURL: "example", htmlDoc: "<!DOCTYPE html>", expectedCode: ErrorCodeInvalidURL},
+		{name: "large document", baseURL: "https://example.com", htmlDoc: strings.Repeat("a", 21), expectedCode: ErrorCodeDocumentTooLarge},
+		{name: "analysis failure", baseURL: "https://example.com", expectedCode: ErrorCodeParseFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, apiErr := h.AnalyzeHTML(context.Background(), tc.baseURL, tc.htmlDoc)
+			assert.Nil(t, res)
+			if assert.NotNil(t, apiErr) {
+				assert.Equal(t, tc.expectedCode, apiErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"go.uber.org/zap"
+)
+
+// List of the headers of authentication and quotas.
+const (
+	HeaderAPIKey         = "X-API-Key"
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+)
+
+// clientContextKey is the key of the authenticated client in the contexts of gin.
+const clientContextKey = "detective_client"
+
+// Authenticate is a middleware which authenticates the API key of requests and enforces the rate limit of its client.
+// The key is read from the X-API-Key header or the bearer token of the Authorization header and the client
+// name is attached to the request logger. The client is stored in the request context and owns the jobs and
+// analyses of the request. It passes all the requests when the handler has no Keyring.
+func (h *HTTPHandler) Authenticate(c *gin.Context) {
+	if h.Keyring == nil {
+		c.Next()
+		return
+	}
+
+	key := apiKey(c.Request)
+	client, err := h.Keyring.Authenticate(key)
+	if err != nil {
+		h.requestLogger(c).Warn("request with invalid api key rejected")
+		c.Header("WWW-Authenticate", `Bearer realm="detective"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &Response{
+			Error:     err.Error(),
+			ErrorCode: ErrorCodeUnauthorized,
+			Code:      http.StatusUnauthorized,
+		})
+		return
+	}
+
+	l := h.requestLogger(c).With(zap.String("client", client.Name))
+	c.Request = c.Request.WithContext(auth.NewContext(withLogger(c.Request.Context(), l), client))
+	c.Set(clientContextKey, key)
+
+	if wait, err := h.Keyring.Allow(key); err != nil {
+		l.Warn("request rejected by rate limit")
+		abortWithTooManyRequests(c, wait, err)
+		return
+	}
+	c.Next()
+}
+
+// ChargeQuota is a middleware which takes an analysis from the daily quota of the authenticated client.
+// It must be used after Authenticate and passes all the requests when the handler has no Keyring.
+func (h *HTTPHandler) ChargeQuota(c *gin.Context) {
+	key := c.GetString(clientContextKey)
+	if h.Keyring == nil || key == "" {
+		c.Next()
+		return
+	}
+
+	remaining, wait, err := h.Keyring.Charge(key)
+	if errors.Is(err, auth.ErrQuotaExceeded) {
+		h.requestLogger(c).Warn("request rejected by daily quota")
+		c.Header(HeaderQuotaRemaining, "0")
+		abortWithTooManyRequests(c, wait, err)
+		return
+	}
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Warn("request rejected because its quota could not be charged")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &Response{
+			Error:     err.Error(),
+			ErrorCode: ErrorCodeUnauthorized,
+			Code:      http.StatusUnauthorized,
+		})
+		return
+	}
+	if remaining >= 0 {
+		c.Header(HeaderQuotaRemaining, strconv.Itoa(remaining))
+	}
+	c.Next()
+}
+
+// chargePages takes the pages of a crawl or a sitemap audit from the daily quota of the authenticated client,
+// the first page has been charged by ChargeQuota. It aborts c with a 429 response and returns false when the
+// remaining quota of the client is less than pages.
+func (h *HTTPHandler) chargePages(c *gin.Context, pages int) bool {
+	key := c.GetString(clientContextKey)
+	if h.Keyring == nil || key == "" || pages < 2 {
+		return true
+	}
+
+	remaining, wait, err := h.Keyring.ChargeN(key, pages-1)
+	if err != nil {
+		h.requestLogger(c).With(zap.Int("pages", pages)).Warn("request rejected because its pages exceed daily quota")
+		abortWithTooManyRequests(c, wait, err)
+		return false
+	}
+	if remaining >= 0 {
+		c.Header(HeaderQuotaRemaining, strconv.Itoa(remaining))
+	}
+	return true
+}
+
+// refundPages gives the pages which have been charged by chargePages but not analyzed back to the daily quota of
+// the authenticated client.
+func (h *HTTPHandler) refundPages(c *gin.Context, pages int) {
+	if key := c.GetString(clientContextKey); h.Keyring != nil && key != "" {
+		h.Keyring.Refund(key, pages)
+	}
+}
+
+// apiKey returns the API key of r.
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+	const prefix = "Bearer "
+	if a := r.Header.Get("Authorization"); len(a) > len(prefix) && strings.EqualFold(a[:len(prefix)], prefix) {
+		return a[len(prefix):]
+	}
+	return ""
+}
+
+// abortWithTooManyRequests aborts c with a 429 response which asks the client to retry after wait.
+func abortWithTooManyRequests(c *gin.Context, wait time.Duration, err error) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	code := ErrorCodeRateLimited
+	if errors.Is(err, auth.ErrQuotaExceeded) {
+		code = ErrorCodeQuotaExceeded
+	}
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, &Response{
+		Error:     err.Error(),
+		ErrorCode: code,
+		Code:      http.StatusTooManyRequests,
+	})
+}
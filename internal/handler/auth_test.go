@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestAuthRouter creates a router which authenticates the requests of /ping and charges the quota of /analyze.
+// Both the endpoints log a message with the request logger.
+func newTestAuthRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	ping := func(c *gin.Context) {
+		h.requestLogger(c).Info("ping")
+		c.JSON(http.StatusOK, &Response{Code: http.StatusOK})
+	}
+	api := r.Group("/", h.Authenticate)
+	api.GET("/ping", ping)
+	api.GET("/analyze", h.ChargeQuota, ping)
+	return r
+}
+
+// serveAuthRequest serves a GET request with the headers on r and decodes the Response.
+func serveAuthRequest(r *gin.Engine, target string, headers map[string]string) (*httptest.ResponseRecorder, Response) {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	r.ServeHTTP(res, req)
+
+	var resp Response
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+	return res, resp
+}
+
+func TestHTTPHandler_Authenticate(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	h := newTestHTTPHandler()
+	h.Logger = zap.New(core)
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 0.001, Burst: 2, DailyQuota: 10},
+	)
+	r := newTestAuthRouter(h)
+
+	for _, headers := range []map[string]string{
+		{},
+		{HeaderAPIKey: "unknown"},
+		{"Authorization": "Basic key-a"},
+	} {
+		res, resp := serveAuthRequest(r, "/ping", headers)
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, auth.ErrInvalidKey.Error(), resp.Error)
+		assert.NotEmpty(t, res.Header().Get("WWW-Authenticate"))
+	}
+
+	res, _ := serveAuthRequest(r, "/ping", map[string]string{HeaderAPIKey: "key-a"})
+	assert.Equal(t, http.StatusOK, res.Code)
+	res, _ = serveAuthRequest(r, "/ping", map[string]string{"Authorization": "Bearer key-a"})
+	assert.Equal(t, http.StatusOK, res.Code)
+	pings := logs.FilterMessage("ping").All()
+	if assert.Len(t, pings, 2) {
+		assert.Equal(t, "team-a", pings[0].ContextMap()["client"])
+	}
+
+	res, resp := serveAuthRequest(r, "/ping", map[string]string{HeaderAPIKey: "key-a"})
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, auth.ErrRateLimited.Error(), resp.Error)
+	assert.NotEmpty(t, res.Header().Get("Retry-After"))
+}
+
+func TestHTTPHandler_ChargeQuota(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 1000, Burst: 1000, DailyQuota: 2},
+	)
+	r := newTestAuthRouter(h)
+	headers := map[string]string{HeaderAPIKey: "key-a"}
+
+	res, _ := serveAuthRequest(r, "/analyze", headers)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "1", res.Header().Get(HeaderQuotaRemaining))
+	res, _ = serveAuthRequest(r, "/ping", headers)
+	assert.Equal(t, http.StatusOK, res.Code)
+	res, _ = serveAuthRequest(r, "/analyze", headers)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "0", res.Header().Get(HeaderQuotaRemaining))
+
+	res, resp := serveAuthRequest(r, "/analyze", headers)
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, auth.ErrQuotaExceeded.Error(), resp.Error)
+	assert.NotEmpty(t, res.Header().Get("Retry-After"))
+}
+
+func TestHTTPHandler_ChargePages(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}},
+		auth.Limits{RateLimit: 1000, Burst: 1000, DailyQuota: 4},
+	)
+	r := newTestAuthRouter(h)
+	// The batch has a budget of 3 pages and analyzes 2 of them.
+	r.GET("/batch", h.Authenticate, h.ChargeQuota, func(c *gin.Context) {
+		if !h.chargePages(c, 3) {
+			return
+		}
+		h.refundPages(c, 1)
+		c.JSON(http.StatusOK, &Response{Code: http.StatusOK})
+	})
+	headers := map[string]string{HeaderAPIKey: "key-a"}
+
+	res, _ := serveAuthRequest(r, "/batch", headers)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "1", res.Header().Get(HeaderQuotaRemaining))
+
+	res, resp := serveAuthRequest(r, "/batch", headers)
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, ErrorCodeQuotaExceeded, resp.ErrorCode)
+
+	// The rejected batch only takes the analysis which is charged by ChargeQuota.
+	res, _ = serveAuthRequest(r, "/analyze", headers)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "0", res.Header().Get(HeaderQuotaRemaining))
+}
+
+func TestHTTPHandler_AuthenticateDisabled(t *testing.T) {
+	r := newTestAuthRouter(newTestHTTPHandler())
+	res, _ := serveAuthRequest(r, "/analyze", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, res.Header().Get(HeaderQuotaRemaining))
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/webhook"
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"go.uber.org/zap"
+)
+
+// CrawlRequest is a struct which entry crawl requests bind to it.
+// MaxDepth and MaxPages fall back to the defaults of the handler when they are not set and they can not exceed them.
+// SameHost is true when it's not set.
+type CrawlRequest struct {
+	URL        string   `json:"url"`
+	MaxDepth   *int     `json:"max_depth"`
+	MaxPages   int      `json:"max_pages"`
+	SameHost   *bool    `json:"same_host"`
+	PathPrefix string   `json:"path_prefix"`
+	Include    []string `json:"include"`
+	Exclude    []string `json:"exclude"`
+}
+
+// CrawlResponse is a struct which is returned to user on the crawl request.
+// Verdicts maps the urls of the analyzed pages to the outcome of the rules of the handler on them. Report is partial
+// when the crawl is cut short by the deadline of the request or a cancellation, the error code is INCOMPLETE then.
+type CrawlResponse struct {
+	Report    *crawler.Report           `json:"report"`
+	Verdicts  map[string]*rules.Verdict `json:"verdicts,omitempty"`
+	Error     string                    `json:"error"`
+	ErrorCode ErrorCode                 `json:"error_code,omitempty"`
+	Details   *ErrorDetails             `json:"details,omitempty"`
+	Code      int                       `json:"code"`
+}
+
+// CrawlURL gets a CrawlRequest and analyzes all the pages of the site which are reachable from the url.
+// The page budget of the crawl is charged from the daily quota of the client and the pages which are not
+// analyzed are refunded after the crawl.
+// The pages are reported in the format which is negotiated by the Accept header, json by default.
+func (h *HTTPHandler) CrawlURL(c *gin.Context) {
+	req := CrawlRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &CrawlResponse{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.Any("request", req)).Info("request body bound successfully")
+
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil || u.Host == "" {
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &CrawlResponse{
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	opts, err := h.crawlOptions(req)
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("crawl options are not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &CrawlResponse{
+			Error:     err.Error(),
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+	if !h.chargePages(c, opts.MaxPages) {
+		return
+	}
+
+	cr := crawler.New(h.performGetRequest, crawler.AnalyzeFunc(h.HTMLAnalyzeFunc), h.requestLogger(c).Named("crawler"))
+	report, err := cr.Crawl(c.Request.Context(), u, opts)
+	if report != nil {
+		h.refundPages(c, opts.MaxPages-len(report.Pages))
+	}
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while crawling site")
+		h.Notifier.Notify(webhook.EventBatchFinished, &BatchEvent{
+			Kind:       BatchKindCrawl,
+			URL:        u.String(),
+			Report:     report,
+			Incomplete: true,
+		})
+		apiErr := newAPIError(ErrorCodeIncomplete, "crawl did not complete", &ErrorDetails{Cause: err.Error()})
+		c.AbortWithStatusJSON(apiErr.Status, &CrawlResponse{
+			Report:    report,
+			Verdicts:  h.crawlVerdicts(report),
+			Error:     apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Details:   apiErr.Details,
+			Code:      apiErr.Status,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.Any("summary", report.Summary)).Info("site crawled successfully")
+	h.Notifier.Notify(webhook.EventBatchFinished, &BatchEvent{Kind: BatchKindCrawl, URL: u.String(), Report: report})
+	if r := reporter(c); r != nil {
+		h.writeReport(c, r, h.crawlPages(report))
+		return
+	}
+
+	c.JSON(http.StatusOK, &CrawlResponse{
+		Report:   report,
+		Verdicts: h.crawlVerdicts(report),
+		Code:     http.StatusOK,
+	})
+}
+
+// crawlOptions converts req to crawler.Options which is limited by the crawl options of the handler.
+func (h *HTTPHandler) crawlOptions(req CrawlRequest) (crawler.Options, error) {
+	opts := crawler.Options{
+		MaxDepth:    h.CrawlOptions.MaxDepth,
+		MaxPages:    h.CrawlOptions.MaxPages,
+		Concurrency: h.CrawlOptions.Concurrency,
+		SameHost:    true,
+		PathPrefix:  req.PathPrefix,
+	}
+	if req.MaxDepth != nil && *req.MaxDepth >= 0 && *req.MaxDepth < opts.MaxDepth {
+		opts.MaxDepth = *req.MaxDepth
+	}
+	if req.MaxPages > 0 && req.MaxPages < opts.MaxPages {
+		opts.MaxPages = req.MaxPages
+	}
+	if req.SameHost != nil {
+		opts.SameHost = *req.SameHost
+	}
+
+	for _, expr := range req.Include {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return opts, fmt.Errorf("include expression `%s` is not valid", expr)
+		}
+		opts.Include = append(opts.Include, re)
+	}
+	for _, expr := range req.Exclude {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return opts, fmt.Errorf("exclude expression `%s` is not valid", expr)
+		}
+		opts.Exclude = append(opts.Exclude, re)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveCrawlRequest serves body on the crawl endpoint of h and decodes the CrawlResponse.
+func serveCrawlRequest(h *HTTPHandler, body string) (*httptest.ResponseRecorder, CrawlResponse) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/crawl", h.CrawlURL)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/crawl", strings.NewReader(body))
+	r.ServeHTTP(res, req)
+
+	var cr CrawlResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &cr)
+	return res, cr
+}
+
+func TestHTTPHandler_CrawlURLSuccess(t *testing.T) {
+	pages := map[string]string{
+		"/":      `<title>Home</title><a href="/a">A</a><a href="/b">B</a>`,
+		"/a":     `<title>A</title><a href="/a/one">One</a>`,
+		"/b":     `<title>B</title><a href="/">Home</a>`,
+		"/a/one": `<title>One</title>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		htmlDoc, ok := pages[req.URL.Path]
+		if !ok {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, htmlDoc)
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	h.CrawlOptions = crawler.Options{MaxDepth: 1, MaxPages: 10, Concurrency: 2}
+
+	// Requested depth can't exceed the max depth of the handler.
+	res, cr := serveCrawlRequest(h, `{"url": "`+server.URL+`", "max_depth": 5}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, cr.Error)
+	if !assert.NotNil(t, cr.Report) {
+		return
+	}
+	assert.Len(t, cr.Report.Pages, 3)
+	assert.Equal(t, 3, cr.Report.Summary.PagesCrawled)
+
+	res, cr = serveCrawlRequest(h, `{"url": "`+server.URL+`", "max_depth": 1, "exclude": ["/b$"]}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Len(t, cr.Report.Pages, 2)
+}
+
+func TestHTTPHandler_CrawlURLIncomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, `<title>Home</title><a href="/a">A</a>`)
+	}))
+	defer server.Close()
+
+	// The request is canceled while the seed page is analyzed, so the next pages are not crawled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = func(ctx context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error) {
+		cancel()
+		return htmlanalysis.Analyze(ctx, u, htmlDoc)
+	}
+	h.CrawlOptions = crawler.Options{MaxDepth: 1, MaxPages: 10, Concurrency: 1}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/crawl", h.CrawlURL)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/crawl", strings.NewReader(`{"url": "`+server.URL+`"}`))
+	r.ServeHTTP(res, req)
+
+	var cr CrawlResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &cr)
+	assert.Equal(t, http.StatusGatewayTimeout, res.Code)
+	assert.Equal(t, ErrorCodeIncomplete, cr.ErrorCode)
+	if assert.NotNil(t, cr.Report) {
+		assert.Len(t, cr.Report.Pages, 1)
+	}
+}
+
+func TestHTTPHandler_CrawlURLFailures(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		expectedCode    int
+		expectedErr     string
+		expectedErrCode ErrorCode
+	}{
+		{
+			name:            "incorrect body",
+			body:            "invalid_request_json",
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "cannot parse request body",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "invalid url",
+			body:            `{"url": "/relative"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "entered url is not valid",
+			expectedErrCode: ErrorCodeInvalidURL,
+		},
+		{
+			name:            "invalid include expression",
+			body:            `{"url": "http://localhost:22222/", "include": ["("]}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "include expression `(` is not valid",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, cr := serveCrawlRequest(newTestHTTPHandler(), tc.body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedErr, cr.Error)
+			assert.Equal(t, tc.expectedErrCode, cr.ErrorCode)
+			assert.Nil(t, cr.Report)
+		})
+	}
+}
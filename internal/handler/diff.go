@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"go.uber.org/zap"
+)
+
+// DiffSource is one side of a diff request, it's either the id of a stored analysis or a url which is
+// analyzed freshly.
+type DiffSource struct {
+	AnalysisID string `json:"analysis_id"`
+	URL        string `json:"url"`
+}
+
+// DiffRequest is a struct which diff requests bind to it.
+type DiffRequest struct {
+	Before DiffSource `json:"before"`
+	After  DiffSource `json:"after"`
+}
+
+// DiffResponse is a struct which is returned to user on the diff request.
+type DiffResponse struct {
+	Before    *htmlanalysis.Result     `json:"before"`
+	After     *htmlanalysis.Result     `json:"after"`
+	Diff      *htmlanalysis.ResultDiff `json:"diff"`
+	Error     string                   `json:"error"`
+	ErrorCode ErrorCode                `json:"error_code,omitempty"`
+	Details   *ErrorDetails            `json:"details,omitempty"`
+	Code      int                      `json:"code"`
+}
+
+// DiffAnalyses gets a DiffRequest and returns the difference between the results of its sources.
+func (h *HTTPHandler) DiffAnalyses(c *gin.Context) {
+	req := DiffRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &DiffResponse{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.Any("request", req)).Info("request body bound successfully")
+
+	before, apiErr := h.resolveDiffSource(c.Request.Context(), req.Before)
+	if apiErr != nil {
+		abortWithDiffError(c, "before: ", apiErr)
+		return
+	}
+	after, apiErr := h.resolveDiffSource(c.Request.Context(), req.After)
+	if apiErr != nil {
+		abortWithDiffError(c, "after: ", apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, &DiffResponse{
+		Before: before,
+		After:  after,
+		Diff:   htmlanalysis.Diff(before, after),
+		Code:   http.StatusOK,
+	})
+}
+
+// resolveDiffSource returns the result of src by loading its stored analysis or analyzing its url.
+// The analyses which aren't visible to the authenticated client of ctx are not found.
+// It returns an APIError if it fails.
+func (h *HTTPHandler) resolveDiffSource(ctx context.Context, src DiffSource) (*htmlanalysis.Result, *APIError) {
+	switch {
+	case (src.AnalysisID == "") == (src.URL == ""):
+		return nil, newAPIError(ErrorCodeInvalidRequest, "either analysis_id or url must be entered", nil)
+	case src.URL != "":
+		_, res, apiErr := h.AnalyzeRawURL(ctx, src.URL, func(string, interface{}) {})
+		return res, apiErr
+	case h.HistoryStore == nil:
+		return nil, newAPIError(ErrorCodeFeatureDisabled, "analysis history is disabled", nil)
+	}
+
+	r, err := h.HistoryStore.Get(src.AnalysisID)
+	if errors.Is(err, history.ErrNotFound) || err == nil && !r.VisibleTo(auth.Owner(ctx)) {
+		return nil, newAPIError(ErrorCodeNotFound, "analysis not found", nil)
+	}
+	if err != nil {
+		h.logger(ctx).With(zap.Error(err)).Error("error while retrieving analysis")
+		return nil, newAPIError(ErrorCodeInternal, "could not retrieve analysis", nil)
+	}
+	return r.Result, nil
+}
+
+// abortWithDiffError aborts c with a DiffResponse which holds apiErr, the message of apiErr is prefixed with the
+// side of the diff which failed.
+func abortWithDiffError(c *gin.Context, side string, apiErr *APIError) {
+	c.AbortWithStatusJSON(apiErr.Status, &DiffResponse{
+		Error:     side + apiErr.Message,
+		ErrorCode: apiErr.Code,
+		Details:   apiErr.Details,
+		Code:      apiErr.Status,
+	})
+}
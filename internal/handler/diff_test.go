@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveDiffRequest serves body on the diff endpoint of h and decodes the DiffResponse.
+func serveDiffRequest(h *HTTPHandler, body string) (*httptest.ResponseRecorder, DiffResponse) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/diff", h.DiffAnalyses)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/diff", strings.NewReader(body))
+	r.ServeHTTP(res, req)
+
+	var dr DiffResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &dr)
+	return res, dr
+}
+
+func TestHTTPHandler_DiffAnalyses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<title>Detective</title>")
+	}))
+	defer server.Close()
+
+	h := newTestHistoryHandler(t)
+	stored := &history.Record{URL: server.URL, Result: &htmlanalysis.Result{
+		PageTitle:         "Old Detective",
+		HasLoginForm:      true,
+		InaccessibleLinks: []string{server.URL + "/broken"},
+	}}
+	if !assert.NoError(t, h.HistoryStore.Save(stored)) {
+		return
+	}
+
+	res, dr := serveDiffRequest(h, `{"before": {"analysis_id": "`+stored.ID+`"}, "after": {"url": "`+server.URL+`"}}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	if !assert.NotNil(t, dr.Diff) {
+		return
+	}
+	assert.True(t, dr.Diff.Changed)
+	assert.Equal(t, &htmlanalysis.StringChange{Old: "Old Detective", New: "Detective"}, dr.Diff.PageTitle)
+	assert.True(t, dr.Diff.LoginFormDisappeared)
+	assert.Equal(t, []string{server.URL + "/broken"}, dr.Diff.NewlyFixedLinks)
+	assert.Equal(t, "Detective", dr.After.PageTitle)
+
+	// The fresh analysis is stored in the history too.
+	records, _ := h.HistoryStore.List(server.URL, "", 10)
+	assert.Len(t, records, 2)
+}
+
+func TestHTTPHandler_DiffAnalysesFailures(t *testing.T) {
+	testCases := []struct {
+		name            string
+		handler         *HTTPHandler
+		body            string
+		expectedCode    int
+		expectedErr     string
+		expectedErrCode ErrorCode
+	}{
+		{
+			name:            "incorrect body",
+			handler:         newTestHTTPHandler(),
+			body:            "invalid_request_json",
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "cannot parse request body",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "empty source",
+			handler:         newTestHTTPHandler(),
+			body:            `{"before": {}, "after": {"url": "http://example.com"}}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "before: either analysis_id or url must be entered",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "history is disabled",
+			handler:         newTestHTTPHandler(),
+			body:            `{"before": {"analysis_id": "1"}, "after": {"analysis_id": "2"}}`,
+			expectedCode:    http.StatusServiceUnavailable,
+			expectedErr:     "before: analysis history is disabled",
+			expectedErrCode: ErrorCodeFeatureDisabled,
+		},
+		{
+			name:            "analysis not found",
+			handler:         newTestHistoryHandler(t),
+			body:            `{"before": {"analysis_id": "1"}, "after": {"analysis_id": "2"}}`,
+			expectedCode:    http.StatusNotFound,
+			expectedErr:     "before: analysis not found",
+			expectedErrCode: ErrorCodeNotFound,
+		},
+		{
+			name:            "inaccessible url",
+			handler:         newTestHistoryHandler(t),
+			body:            `{"before": {"url": "http://localhost:22222/"}, "after": {"url": "invalid_url"}}`,
+			expectedCode:    http.StatusBadGateway,
+			expectedErr:     "before: could not retrieve html body of url",
+			expectedErrCode: ErrorCodeUpstreamUnreachable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, dr := serveDiffRequest(tc.handler, tc.body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedErr, dr.Error)
+			assert.Equal(t, tc.expectedErrCode, dr.ErrorCode)
+			assert.Equal(t, tc.expectedCode, dr.Code)
+			assert.Nil(t, dr.Diff)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+)
+
+// ErrorCode is a machine readable code of the error of a response.
+type ErrorCode string
+
+// List of the error codes of responses.
+const (
+	ErrorCodeInvalidRequest         ErrorCode = "INVALID_REQUEST"
+	ErrorCodeInvalidURL             ErrorCode = "INVALID_URL"
+	ErrorCodeUnauthorized           ErrorCode = "UNAUTHORIZED"
+	ErrorCodeRobotsDisallowed       ErrorCode = "ROBOTS_DISALLOWED"
+	ErrorCodeNotFound               ErrorCode = "NOT_FOUND"
+	ErrorCodeConflict               ErrorCode = "CONFLICT"
+	ErrorCodeRateLimited            ErrorCode = "RATE_LIMITED"
+	ErrorCodeQuotaExceeded          ErrorCode = "QUOTA_EXCEEDED"
+	ErrorCodeUnsupportedContentType ErrorCode = "UNSUPPORTED_CONTENT_TYPE"
+	ErrorCodeDocumentTooLarge       ErrorCode = "DOCUMENT_TOO_LARGE"
+	ErrorCodeParseFailed            ErrorCode = "PARSE_FAILED"
+	ErrorCodeUpstreamStatus         ErrorCode = "UPSTREAM_STATUS"
+	ErrorCodeUpstreamUnreachable    ErrorCode = "UPSTREAM_UNREACHABLE"
+	ErrorCodeUpstreamTimeout        ErrorCode = "UPSTREAM_TIMEOUT"
+	ErrorCodeIncomplete             ErrorCode = "INCOMPLETE"
+	ErrorCodeCanceled               ErrorCode = "CANCELED"
+	ErrorCodeSaturated              ErrorCode = "SATURATED"
+	ErrorCodeFeatureDisabled        ErrorCode = "FEATURE_DISABLED"
+	ErrorCodeShuttingDown           ErrorCode = "SHUTTING_DOWN"
+	ErrorCodeInternal               ErrorCode = "INTERNAL"
+)
+
+// errorStatuses maps the error codes to the http status codes of their responses.
+var errorStatuses = map[ErrorCode]int{
+	ErrorCodeInvalidRequest:         http.StatusBadRequest,
+	ErrorCodeInvalidURL:             http.StatusBadRequest,
+	ErrorCodeUnauthorized:           http.StatusUnauthorized,
+	ErrorCodeRobotsDisallowed:       http.StatusForbidden,
+	ErrorCodeNotFound:               http.StatusNotFound,
+	ErrorCodeConflict:               http.StatusConflict,
+	ErrorCodeRateLimited:            http.StatusTooManyRequests,
+	ErrorCodeQuotaExceeded:          http.StatusTooManyRequests,
+	ErrorCodeUnsupportedContentType: http.StatusUnprocessableEntity,
+	ErrorCodeDocumentTooLarge:       http.StatusUnprocessableEntity,
+	ErrorCodeParseFailed:            http.StatusUnprocessableEntity,
+	ErrorCodeUpstreamStatus:         http.StatusBadGateway,
+	ErrorCodeUpstreamUnreachable:    http.StatusBadGateway,
+	ErrorCodeUpstreamTimeout:        http.StatusGatewayTimeout,
+	ErrorCodeIncomplete:             http.StatusGatewayTimeout,
+	ErrorCodeCanceled:               http.StatusServiceUnavailable,
+	ErrorCodeSaturated:              http.StatusServiceUnavailable,
+	ErrorCodeFeatureDisabled:        http.StatusServiceUnavailable,
+	ErrorCodeShuttingDown:           http.StatusServiceUnavailable,
+	ErrorCodeInternal:               http.StatusInternalServerError,
+}
+
+// ErrorDetails holds the details of an error which is caused by the upstream of an analysis.
+// UpstreamStatus and ContentType belong to the response of the upstream, MaxSize is the maximum accepted size of
+// documents and Cause is the underlying error.
+type ErrorDetails struct {
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+	ContentType    string `json:"content_type,omitempty"`
+	MaxSize        int64  `json:"max_size,omitempty"`
+	Cause          string `json:"cause,omitempty"`
+}
+
+// APIError is an error which is returned to user in the error fields of a response.
+// Status is the http status code of the response.
+type APIError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Details *ErrorDetails
+}
+
+// Error returns the message of e.
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// abortWithAPIError aborts c with a Response which holds e.
+func abortWithAPIError(c *gin.Context, e *APIError) {
+	c.AbortWithStatusJSON(e.Status, &Response{
+		Error:     e.Message,
+		ErrorCode: e.Code,
+		Details:   e.Details,
+		Code:      e.Status,
+	})
+}
+
+// newAPIError creates an APIError of code with the http status of code.
+func newAPIError(code ErrorCode, message string, details *ErrorDetails) *APIError {
+	status, ok := errorStatuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return &APIError{Status: status, Code: code, Message: message, Details: details}
+}
+
+// FetchError is returned by performGetRequest when the page of a url can't be retrieved.
+// Code categorizes the failure, StatusCode and ContentType belong to the upstream response if there was one and
+// Err is the underlying error.
+type FetchError struct {
+	Code        ErrorCode
+	StatusCode  int
+	ContentType string
+	MaxSize     int64
+	Err         error
+}
+
+// Error returns the message of the underlying error of e.
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error of e.
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// newFetchError creates a FetchError of the failed request or read err, it's categorized as a timeout, a
+// cancellation or an unreachable upstream.
+func newFetchError(err error) *FetchError {
+	var ne net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &FetchError{Code: ErrorCodeCanceled, Err: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return &FetchError{Code: ErrorCodeUpstreamTimeout, Err: err}
+	default:
+		return &FetchError{Code: ErrorCodeUpstreamUnreachable, Err: err}
+	}
+}
+
+// fetchAPIError converts an error of performGetRequest to an APIError.
+func fetchAPIError(err error) *APIError {
+	if errors.Is(err, robots.ErrDisallowed) {
+		return newAPIError(ErrorCodeRobotsDisallowed, "url is disallowed by robots.txt", nil)
+	}
+
+	var fe *FetchError
+	if !errors.As(err, &fe) {
+		fe = newFetchError(err)
+	}
+	return newAPIError(fe.Code, "could not retrieve html body of url", &ErrorDetails{
+		UpstreamStatus: fe.StatusCode,
+		ContentType:    fe.ContentType,
+		MaxSize:        fe.MaxSize,
+		Cause:          fe.Err.Error(),
+	})
+}
+
+// sitemapAPIError converts an error of sitemap.Fetch to an APIError.
+func sitemapAPIError(err error) *APIError {
+	var se *sitemap.StatusError
+	if errors.As(err, &se) {
+		return newAPIError(ErrorCodeUpstreamStatus, "could not retrieve sitemap of url", &ErrorDetails{
+			UpstreamStatus: se.StatusCode,
+			Cause:          err.Error(),
+		})
+	}
+
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		fe := newFetchError(err)
+		return newAPIError(fe.Code, "could not retrieve sitemap of url", &ErrorDetails{Cause: err.Error()})
+	}
+	return newAPIError(ErrorCodeParseFailed, "sitemap of url is not valid", &ErrorDetails{Cause: err.Error()})
+}
+
+// interruptedAPIError returns the APIError of an analysis whose context got done with err before it finished.
+// The result of such an analysis is partial, so it's neither returned nor stored.
+func interruptedAPIError(err error) *APIError {
+	fe := newFetchError(err)
+	return newAPIError(fe.Code, "analysis was interrupted before it finished", &ErrorDetails{Cause: err.Error()})
+}
+
+// analysisAPIError converts an error of the HTMLAnalyzeFunc to an APIError.
+func analysisAPIError(err error) *APIError {
+	return newAPIError(ErrorCodeParseFailed, "error while parsing html", &ErrorDetails{Cause: err.Error()})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+	"github.com/stretchr/testify/assert"
+)
+
+// timeoutError is a net.Error which reports a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFetchAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   ErrorCode
+		expectedStatus int
+	}{
+		{
+			name:           "robots disallowed",
+			err:            fmt.Errorf("url is blocked: %w", robots.ErrDisallowed),
+			expectedCode:   ErrorCodeRobotsDisallowed,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "canceled",
+			err:            newFetchError(fmt.Errorf("error while performing HTTP request: %w", context.Canceled)),
+			expectedCode:   ErrorCodeCanceled,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "deadline exceeded",
+			err:            newFetchError(context.DeadlineExceeded),
+			expectedCode:   ErrorCodeUpstreamTimeout,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "network timeout",
+			err:            newFetchError(&url.Error{Op: "Get", URL: "http://localhost", Err: timeoutError{}}),
+			expectedCode:   ErrorCodeUpstreamTimeout,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "unreachable",
+			err:            errors.New("connection refused"),
+			expectedCode:   ErrorCodeUpstreamUnreachable,
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:           "upstream status",
+			err:            &FetchError{Code: ErrorCodeUpstreamStatus, StatusCode: http.StatusNotFound, Err: errors.New("not found")},
+			expectedCode:   ErrorCodeUpstreamStatus,
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := fetchAPIError(tc.err)
+			assert.Equal(t, tc.expectedCode, e.Code)
+			assert.Equal(t, tc.expectedStatus, e.Status)
+		})
+	}
+}
+
+func TestSitemapAPIError(t *testing.T) {
+	e := sitemapAPIError(fmt.Errorf("error while fetching sitemap: %w", &sitemap.StatusError{URL: "http://localhost", StatusCode: http.StatusGone}))
+	assert.Equal(t, ErrorCodeUpstreamStatus, e.Code)
+	assert.Equal(t, http.StatusGone, e.Details.UpstreamStatus)
+
+	e = sitemapAPIError(&url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")})
+	assert.Equal(t, ErrorCodeUpstreamUnreachable, e.Code)
+
+	e = sitemapAPIError(errors.New("XML syntax error"))
+	assert.Equal(t, ErrorCodeParseFailed, e.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, e.Status)
+	assert.Equal(t, "XML syntax error", e.Details.Cause)
+}
+
+func TestNewAPIError(t *testing.T) {
+	e := newAPIError(ErrorCodeQuotaExceeded, "quota exceeded", nil)
+	assert.Equal(t, http.StatusTooManyRequests, e.Status)
+	assert.Equal(t, "quota exceeded", e.Error())
+
+	e = newAPIError("UNKNOWN", "unknown", nil)
+	assert.Equal(t, http.StatusInternalServerError, e.Status)
+}
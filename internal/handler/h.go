@@ -4,18 +4,116 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/internal/job"
+	"github.com/mammadmodi/detective/internal/metrics"
+	"github.com/mammadmodi/detective/internal/monitor"
+	"github.com/mammadmodi/detective/internal/openapi"
+	"github.com/mammadmodi/detective/internal/webhook"
+	"github.com/mammadmodi/detective/pkg/crawler"
 	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"github.com/mammadmodi/detective/pkg/sitemap"
 	"go.uber.org/zap"
 )
 
+// List of the kinds of batches which are reported by webhook.EventBatchFinished events.
+const (
+	BatchKindCrawl   = "crawl"
+	BatchKindSitemap = "sitemap"
+)
+
+// BatchEvent is the data of webhook.EventBatchFinished events.
+// Report is either a *crawler.Report or a *sitemap.Report based on Kind, Incomplete shows that the batch has been
+// cut short by a deadline or a cancellation and Report only holds the pages which are analyzed before it.
+type BatchEvent struct {
+	Kind       string      `json:"kind"`
+	URL        string      `json:"url"`
+	Report     interface{} `json:"report"`
+	Incomplete bool        `json:"incomplete,omitempty"`
+}
+
+// instrumentationName is the name of the tracer of this package.
+const instrumentationName = "github.com/mammadmodi/detective/internal/handler"
+
 // HTMLAnalyzeFunc is a type of function which analyzes an html doc and returns a Result object.
 type HTMLAnalyzeFunc func(ctx context.Context, url *url.URL, htmlDoc string) (*htmlanalysis.Result, error)
 
 // HTTPHandler handles http requests.
 // HTTPClient is used for performing Get http requests to entered urls.
+// JobManager is used for running asynchronous analysis jobs.
+// CrawlOptions holds the default and maximum limits of crawls.
+// SitemapOptions holds the limits of sitemap audits.
+// RobotsChecker is consulted before fetching pages, a nil RobotsChecker ignores robots.txt.
+// HistoryStore persists the results of analyses, a nil HistoryStore disables the analysis history.
+// MonitorScheduler runs the scheduled monitors, a nil MonitorScheduler disables monitoring.
+// Notifier delivers the events of the handler to webhook targets, a nil Notifier drops them.
+// Metrics records the page fetches, a nil Metrics doesn't record anything.
+// BuildInfo is the version information which is returned by the version endpoint.
+// Keyring authenticates the API keys of requests, a nil Keyring disables authentication.
+// Admission limits the analyses which run at the same time, a nil Admission admits all of them.
+// RetryAfter is the time which the clients of rejected analyses are asked to wait before retrying.
+// MaxDocumentSize is the maximum size of the fetched html documents in bytes, zero disables the limit.
+// Validator validates the requests of the versioned API against its OpenAPI document, a nil Validator disables it.
+// Rules are evaluated on the results of analyses and their verdicts are included in the responses, nil Rules disable
+// the verdicts.
+// Now returns the current time which the certificates of the fetched pages are evaluated at, it should be the clock
+// of the analyzer and time.Now is used when it's nil.
 type HTTPHandler struct {
-	HTTPClient      *http.Client
-	Logger          *zap.Logger
-	HTMLAnalyzeFunc HTMLAnalyzeFunc
+	HTTPClient       *http.Client
+	Logger           *zap.Logger
+	HTMLAnalyzeFunc  HTMLAnalyzeFunc
+	JobManager       *job.Manager
+	CrawlOptions     crawler.Options
+	SitemapOptions   sitemap.Options
+	RobotsChecker    *robots.Checker
+	HistoryStore     history.Store
+	MonitorScheduler *monitor.Scheduler
+	Notifier         *webhook.Notifier
+	Metrics          *metrics.Metrics
+	BuildInfo        BuildInfo
+	Keyring          *auth.Keyring
+	Admission        *admission.Controller
+	RetryAfter       time.Duration
+	MaxDocumentSize  int64
+	Validator        *openapi.Validator
+	Rules            *rules.RuleSet
+	Now              func() time.Time
+
+	shuttingDown int32
+}
+
+// loggerContextKey is the key of the request logger in the contexts of requests.
+type loggerContextKey struct{}
+
+// withLogger returns a copy of ctx which holds the request logger l.
+func withLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// logger returns the request logger of ctx or the Logger of the handler if ctx doesn't hold one.
+func (h *HTTPHandler) logger(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return h.Logger
+}
+
+// now returns the current time by the Now of the handler.
+func (h *HTTPHandler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+	return time.Now()
+}
+
+// requestLogger returns the request logger of c.
+func (h *HTTPHandler) requestLogger(c *gin.Context) *zap.Logger {
+	return h.logger(c.Request.Context())
 }
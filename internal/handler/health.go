@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List of the statuses which are returned by the health endpoints.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// BuildInfo holds the version information of the application which is loaded in build time.
+type BuildInfo struct {
+	CommitSHA     string `json:"commit_sha"`
+	CommitRefName string `json:"commit_ref_name"`
+	BuildDate     string `json:"build_date"`
+}
+
+// HealthResponse is a struct which is returned to user on the health and readiness requests.
+type HealthResponse struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	Code      int       `json:"code"`
+}
+
+// SetShuttingDown marks the handler as shutting down, so the readiness check fails from then on.
+func (h *HTTPHandler) SetShuttingDown() {
+	atomic.StoreInt32(&h.shuttingDown, 1)
+}
+
+// Healthz reports that the application is alive.
+func (h *HTTPHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, &HealthResponse{
+		Status: StatusOK,
+		Code:   http.StatusOK,
+	})
+}
+
+// Readyz reports whether the application can accept new requests.
+// It fails while the application is shutting down or when the queue of jobs or the admission of analyses is
+// saturated.
+func (h *HTTPHandler) Readyz(c *gin.Context) {
+	errMsg, errCode := "", ErrorCodeSaturated
+	switch {
+	case atomic.LoadInt32(&h.shuttingDown) == 1:
+		errMsg, errCode = "application is shutting down", ErrorCodeShuttingDown
+	case h.JobManager != nil && h.JobManager.Saturated():
+		errMsg = "job queue is saturated"
+	case h.Admission != nil && h.Admission.Saturated():
+		errMsg = "analysis admission is saturated"
+	}
+	if errMsg != "" {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &HealthResponse{
+			Status:    StatusUnavailable,
+			Error:     errMsg,
+			ErrorCode: errCode,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &HealthResponse{
+		Status: StatusOK,
+		Code:   http.StatusOK,
+	})
+}
+
+// Version returns the build information of the application.
+func (h *HTTPHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, &h.BuildInfo)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/admission"
+	"github.com/mammadmodi/detective/internal/job"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestHealthRouter creates a router which serves the health endpoints of h.
+func newTestHealthRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/version", h.Version)
+	return r
+}
+
+// serveHealthRequest serves a GET request on r and decodes the HealthResponse.
+func serveHealthRequest(r *gin.Engine, target string) (*httptest.ResponseRecorder, HealthResponse) {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	r.ServeHTTP(res, req)
+
+	var hr HealthResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &hr)
+	return res, hr
+}
+
+func TestHTTPHandler_Healthz(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.SetShuttingDown()
+	res, hr := serveHealthRequest(newTestHealthRouter(h), "/healthz")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, StatusOK, hr.Status)
+}
+
+func TestHTTPHandler_Readyz(t *testing.T) {
+	h := newTestHTTPHandler()
+	// Workers are not started so the queue never gets drained.
+	h.JobManager = job.NewManager(1, 1, nil, zap.NewNop())
+	r := newTestHealthRouter(h)
+
+	res, hr := serveHealthRequest(r, "/readyz")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, StatusOK, hr.Status)
+
+	_, err := h.JobManager.Submit(context.Background(), &url.URL{})
+	assert.NoError(t, err)
+	res, hr = serveHealthRequest(r, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, StatusUnavailable, hr.Status)
+	assert.Equal(t, "job queue is saturated", hr.Error)
+
+	h.JobManager = nil
+	h.Admission = admission.NewController(1, 0, time.Second)
+	release, _ := h.Admission.Acquire(context.Background())
+	res, hr = serveHealthRequest(r, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, "analysis admission is saturated", hr.Error)
+	release()
+
+	h.SetShuttingDown()
+	res, hr = serveHealthRequest(r, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, "application is shutting down", hr.Error)
+}
+
+func TestHTTPHandler_Version(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.BuildInfo = BuildInfo{CommitSHA: "c0ffee", CommitRefName: "v1.0.0", BuildDate: "2021-07-01"}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/version", nil)
+	newTestHealthRouter(h).ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"commit_sha": "c0ffee", "commit_ref_name": "v1.0.0", "build_date": "2021-07-01"}`, res.Body.String())
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"go.uber.org/zap"
+)
+
+// List of the limits of history listing.
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// HistoryResponse is a struct which is returned to user on the history requests.
+type HistoryResponse struct {
+	Records   []*history.Record `json:"records"`
+	Error     string            `json:"error"`
+	ErrorCode ErrorCode         `json:"error_code,omitempty"`
+	Details   *ErrorDetails     `json:"details,omitempty"`
+	Code      int               `json:"code"`
+}
+
+// AnalysisResponse is a struct which is returned to user on the stored analysis requests.
+// Verdict is the outcome of the current rules of the handler on the stored result.
+type AnalysisResponse struct {
+	Analysis  *history.Record `json:"analysis"`
+	Verdict   *rules.Verdict  `json:"verdict,omitempty"`
+	Error     string          `json:"error"`
+	ErrorCode ErrorCode       `json:"error_code,omitempty"`
+	Details   *ErrorDetails   `json:"details,omitempty"`
+	Code      int             `json:"code"`
+}
+
+// GetHistory returns the stored analyses of the url in the `url` query parameter which are visible to the
+// authenticated client, the newest first.
+// The number of returned analyses can be limited by the `limit` query parameter.
+func (h *HTTPHandler) GetHistory(c *gin.Context) {
+	if h.HistoryStore == nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &HistoryResponse{
+			Error:     "analysis history is disabled",
+			ErrorCode: ErrorCodeFeatureDisabled,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+
+	u, err := url.ParseRequestURI(c.Query("url"))
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &HistoryResponse{
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	limit := DefaultHistoryLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, &HistoryResponse{
+				Error:     "limit must be a positive integer",
+				ErrorCode: ErrorCodeInvalidRequest,
+				Code:      http.StatusBadRequest,
+			})
+			return
+		}
+		if limit > MaxHistoryLimit {
+			limit = MaxHistoryLimit
+		}
+	}
+
+	records, err := h.HistoryStore.List(u.String(), auth.Owner(c.Request.Context()), limit)
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while listing analysis history")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, &HistoryResponse{
+			Error:     "could not retrieve analysis history",
+			ErrorCode: ErrorCodeInternal,
+			Code:      http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &HistoryResponse{
+		Records: records,
+		Code:    http.StatusOK,
+	})
+}
+
+// GetAnalysis returns a stored analysis by its id, in the report format which is negotiated by the Accept header.
+// The analyses which aren't visible to the authenticated client are not found.
+func (h *HTTPHandler) GetAnalysis(c *gin.Context) {
+	if h.HistoryStore == nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &AnalysisResponse{
+			Error:     "analysis history is disabled",
+			ErrorCode: ErrorCodeFeatureDisabled,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+
+	r, err := h.HistoryStore.Get(c.Param("id"))
+	if errors.Is(err, history.ErrNotFound) || err == nil && !r.VisibleTo(auth.Owner(c.Request.Context())) {
+		c.AbortWithStatusJSON(http.StatusNotFound, &AnalysisResponse{
+			Error:     "analysis not found",
+			ErrorCode: ErrorCodeNotFound,
+			Code:      http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while retrieving analysis")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, &AnalysisResponse{
+			Error:     "could not retrieve analysis",
+			ErrorCode: ErrorCodeInternal,
+			Code:      http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if rp := reporter(c); rp != nil {
+		h.writeReport(c, rp, h.resultPages(r.URL, r.Result))
+		return
+	}
+	c.JSON(http.StatusOK, &AnalysisResponse{
+		Analysis: r,
+		Verdict:  h.evaluate(r.URL, r.Result),
+		Code:     http.StatusOK,
+	})
+}
+
+// RecordAnalysis retrieves the html body of url, analyzes it and stores the result in the history store.
+// The ID of the returned record is empty when the analysis history is disabled.
+// It's used as the monitor.RunFunc of scheduled monitors.
+func (h *HTTPHandler) RecordAnalysis(ctx context.Context, u *url.URL) (*history.Record, error) {
+	p, err := h.fetchPage(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve html body of url: %w", err)
+	}
+
+	res, err := h.HTMLAnalyzeFunc(ctx, u, p.HTML)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing html: %w", err)
+	}
+	// The result of an interrupted analysis is partial, so it must not be stored.
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("analysis was interrupted: %w", err)
+	}
+	res.TLS = h.tlsReport(p)
+
+	return h.recordAnalysis(ctx, u, res), nil
+}
+
+// saveAnalysis stores the result of analyzing u in the history store and returns the id of the record.
+func (h *HTTPHandler) saveAnalysis(ctx context.Context, u *url.URL, res *htmlanalysis.Result) string {
+	return h.recordAnalysis(ctx, u, res).ID
+}
+
+// recordAnalysis creates a record for the result of analyzing u, which is owned by the authenticated client of ctx,
+// and stores it in the history store.
+// Failures are only logged because the history must not fail the analysis itself, the ID of the returned record
+// is empty if it's not stored.
+func (h *HTTPHandler) recordAnalysis(ctx context.Context, u *url.URL, res *htmlanalysis.Result) *history.Record {
+	r := &history.Record{URL: u.String(), Owner: auth.Owner(ctx), CreatedAt: time.Now().UTC(), Result: res}
+	if h.HistoryStore == nil {
+		return r
+	}
+
+	if err := h.HistoryStore.Save(r); err != nil {
+		h.Logger.With(zap.Error(err), zap.String("url", r.URL)).Error("error while saving analysis")
+		r.ID = ""
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestHistoryHandler creates an HTTPHandler with a history store on a temporary database file.
+func newTestHistoryHandler(t *testing.T) *HTTPHandler {
+	s, err := history.NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("error while opening history store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	h := newTestHTTPHandler()
+	h.HistoryStore = s
+	h.HTMLAnalyzeFunc = func(_ context.Context, _ *url.URL, _ string) (*htmlanalysis.Result, error) {
+		return &htmlanalysis.Result{PageTitle: "Detective"}, nil
+	}
+	return h
+}
+
+// newTestHistoryRouter returns a router which serves the analyze and history endpoints of h.
+func newTestHistoryRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/analyze-url", h.AnalyzeURL)
+	r.GET("/history", h.GetHistory)
+	r.GET("/analyses/:id", h.GetAnalysis)
+	return r
+}
+
+func TestHTTPHandler_History(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<title>Detective</title>")
+	}))
+	defer server.Close()
+
+	h := newTestHistoryHandler(t)
+	r := newTestHistoryRouter(h)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/analyze-url", strings.NewReader(`{"url": "`+server.URL+`"}`))
+		r.ServeHTTP(res, req)
+
+		var ar Response
+		_ = json.Unmarshal(res.Body.Bytes(), &ar)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.NotEmpty(t, ar.AnalysisID)
+		ids = append(ids, ar.AnalysisID)
+	}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/history?url="+url.QueryEscape(server.URL)+"&limit=1", nil)
+	r.ServeHTTP(res, req)
+	var hr HistoryResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &hr)
+	assert.Equal(t, http.StatusOK, res.Code)
+	if assert.Len(t, hr.Records, 1) {
+		assert.Equal(t, ids[1], hr.Records[0].ID)
+		assert.Equal(t, server.URL, hr.Records[0].URL)
+	}
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/analyses/"+ids[0], nil)
+	r.ServeHTTP(res, req)
+	var anr AnalysisResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &anr)
+	assert.Equal(t, http.StatusOK, res.Code)
+	if assert.NotNil(t, anr.Analysis) {
+		assert.Equal(t, ids[0], anr.Analysis.ID)
+		assert.Equal(t, "Detective", anr.Analysis.Result.PageTitle)
+	}
+}
+
+func TestHTTPHandler_HistoryOwner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<title>Detective</title>")
+	}))
+	defer server.Close()
+
+	h := newTestHistoryHandler(t)
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}, {Name: "team-b", Key: "key-b"}},
+		auth.Limits{RateLimit: 100, Burst: 100, DailyQuota: -1},
+	)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(h.Authenticate)
+	r.POST("/analyze-url", h.AnalyzeURL)
+	r.GET("/history", h.GetHistory)
+	r.GET("/analyses/:id", h.GetAnalysis)
+	serve := func(method, target, body, key string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(HeaderAPIKey, key)
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve(http.MethodPost, "/analyze-url", `{"url": "`+server.URL+`"}`, "key-a")
+	var ar Response
+	_ = json.Unmarshal(res.Body.Bytes(), &ar)
+	if !assert.NotEmpty(t, ar.AnalysisID) {
+		return
+	}
+
+	// The analysis of team-a is neither listed nor found for team-b.
+	res = serve(http.MethodGet, "/analyses/"+ar.AnalysisID, "", "key-b")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	res = serve(http.MethodGet, "/history?url="+url.QueryEscape(server.URL), "", "key-b")
+	var hr HistoryResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &hr)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, hr.Records)
+
+	res = serve(http.MethodGet, "/history?url="+url.QueryEscape(server.URL), "", "key-a")
+	_ = json.Unmarshal(res.Body.Bytes(), &hr)
+	if assert.Len(t, hr.Records, 1) {
+		assert.Equal(t, "team-a", hr.Records[0].Owner)
+	}
+	res = serve(http.MethodGet, "/analyses/"+ar.AnalysisID, "", "key-a")
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestHTTPHandler_HistoryInterrupted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, "<title>Detective</title>")
+	}))
+	defer server.Close()
+
+	// The analysis returns a partial result when its context gets done, like the link checks of Analyze.
+	h := newTestHistoryHandler(t)
+	var cancel context.CancelFunc
+	newContext := func() context.Context {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		return ctx
+	}
+	h.HTMLAnalyzeFunc = func(_ context.Context, _ *url.URL, _ string) (*htmlanalysis.Result, error) {
+		cancel()
+		return &htmlanalysis.Result{PageTitle: "Detective"}, nil
+	}
+	r := newTestHistoryRouter(h)
+
+	res := httptest.NewRecorder()
+	body := strings.NewReader(`{"url": "` + server.URL + `"}`)
+	req, _ := http.NewRequestWithContext(newContext(), http.MethodPost, "/analyze-url", body)
+	r.ServeHTTP(res, req)
+	var ar Response
+	_ = json.Unmarshal(res.Body.Bytes(), &ar)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, ErrorCodeCanceled, ar.ErrorCode)
+	assert.Nil(t, ar.Result)
+
+	_, _, apiErr := h.AnalyzeRawURL(newContext(), server.URL, func(string, interface{}) {})
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, ErrorCodeCanceled, apiErr.Code)
+		assert.Equal(t, "analysis was interrupted before it finished", apiErr.Message)
+	}
+	u, _ := url.Parse(server.URL)
+	_, err := h.RecordAnalysis(newContext(), u)
+	if assert.Error(t, err) {
+		assert.Equal(t, "analysis was interrupted: context canceled", err.Error())
+	}
+
+	records, err := h.HistoryStore.List(server.URL, "", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, records, "partial results must not be stored")
+}
+
+func TestHTTPHandler_HistoryFailures(t *testing.T) {
+	testCases := []struct {
+		name         string
+		handler      *HTTPHandler
+		path         string
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			name:         "history is disabled",
+			handler:      newTestHTTPHandler(),
+			path:         "/history?url=http://example.com",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErr:  "analysis history is disabled",
+		},
+		{
+			name:         "invalid url",
+			handler:      newTestHistoryHandler(t),
+			path:         "/history?url=invalid_url",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  "entered url is not valid",
+		},
+		{
+			name:         "invalid limit",
+			handler:      newTestHistoryHandler(t),
+			path:         "/history?url=http://example.com&limit=-1",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  "limit must be a positive integer",
+		},
+		{
+			name:         "analysis is disabled",
+			handler:      newTestHTTPHandler(),
+			path:         "/analyses/1",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErr:  "analysis history is disabled",
+		},
+		{
+			name:         "analysis not found",
+			handler:      newTestHistoryHandler(t),
+			path:         "/analyses/missing",
+			expectedCode: http.StatusNotFound,
+			expectedErr:  "analysis not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			newTestHistoryRouter(tc.handler).ServeHTTP(res, req)
+
+			var body struct {
+				Error string `json:"error"`
+				Code  int    `json:"code"`
+			}
+			_ = json.Unmarshal(res.Body.Bytes(), &body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedErr, body.Error)
+			assert.Equal(t, tc.expectedCode, body.Code)
+		})
+	}
+}
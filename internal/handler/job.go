@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/job"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"go.uber.org/zap"
+)
+
+// JobResponse is a struct which is returned to user on the job requests.
+type JobResponse struct {
+	Job       *job.Job      `json:"job"`
+	Error     string        `json:"error"`
+	ErrorCode ErrorCode     `json:"error_code,omitempty"`
+	Details   *ErrorDetails `json:"details,omitempty"`
+	Code      int           `json:"code"`
+}
+
+// Analyze retrieves the html body of url and analyzes it, the progress of link checking is reported to progress.
+// It's used as the job.RunFunc of asynchronous analysis jobs.
+func (h *HTTPHandler) Analyze(ctx context.Context, u *url.URL, progress htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+	r, err := h.RecordAnalysis(htmlanalysis.WithProgressFunc(ctx, progress), u)
+	if err != nil {
+		return nil, err
+	}
+	return r.Result, nil
+}
+
+// CreateJob gets an URLRequest and queues an asynchronous analysis job for it.
+func (h *HTTPHandler) CreateJob(c *gin.Context) {
+	req := URLRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &JobResponse{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &JobResponse{
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	j, err := h.JobManager.Submit(c.Request.Context(), u)
+	if errors.Is(err, job.ErrClosed) {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while submitting job")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &JobResponse{
+			Error:     "server is shutting down",
+			ErrorCode: ErrorCodeShuttingDown,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while submitting job")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &JobResponse{
+			Error:     "could not queue the job",
+			ErrorCode: ErrorCodeSaturated,
+			Code:      http.StatusServiceUnavailable,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.String("job_id", j.ID)).Info("job queued successfully")
+
+	c.JSON(http.StatusAccepted, &JobResponse{
+		Job:  j,
+		Code: http.StatusAccepted,
+	})
+}
+
+// GetJob returns the status, progress and result of a job, the jobs of other clients are not found.
+func (h *HTTPHandler) GetJob(c *gin.Context) {
+	j, err := h.JobManager.Get(c.Param("id"), auth.Owner(c.Request.Context()))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, &JobResponse{
+			Error:     "job not found",
+			ErrorCode: ErrorCodeNotFound,
+			Code:      http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &JobResponse{
+		Job:  j,
+		Code: http.StatusOK,
+	})
+}
+
+// DeleteJob cancels a job which hasn't been finished yet, the jobs of other clients are not found.
+func (h *HTTPHandler) DeleteJob(c *gin.Context) {
+	j, err := h.JobManager.Cancel(c.Param("id"), auth.Owner(c.Request.Context()))
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, &JobResponse{
+			Error:     "job not found",
+			ErrorCode: ErrorCodeNotFound,
+			Code:      http.StatusNotFound,
+		})
+		return
+	case errors.Is(err, job.ErrFinished):
+		c.AbortWithStatusJSON(http.StatusConflict, &JobResponse{
+			Job:       j,
+			Error:     "job has already finished",
+			ErrorCode: ErrorCodeConflict,
+			Code:      http.StatusConflict,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.String("job_id", j.ID)).Info("job canceled successfully")
+
+	c.JSON(http.StatusOK, &JobResponse{
+		Job:  j,
+		Code: http.StatusOK,
+	})
+}
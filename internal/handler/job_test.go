@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/job"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestJobRouter creates a router which serves the job endpoints of h.
+func newTestJobRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/jobs", h.CreateJob)
+	r.GET("/jobs/:id", h.GetJob)
+	r.DELETE("/jobs/:id", h.DeleteJob)
+	return r
+}
+
+// serveJobRequest serves a request on r and decodes the JobResponse.
+func serveJobRequest(r *gin.Engine, method, target, body string) (*httptest.ResponseRecorder, JobResponse) {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	r.ServeHTTP(res, req)
+
+	var jr JobResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &jr)
+	return res, jr
+}
+
+func TestHTTPHandler_Jobs(t *testing.T) {
+	expectedResult := htmlanalysis.Result{
+		HTMLVersion: "HTML 5",
+		PageTitle:   "Detective",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		res.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(res, "<!DOCTYPE html>")
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = func(_ context.Context, _ *url.URL, _ string) (*htmlanalysis.Result, error) {
+		return &expectedResult, nil
+	}
+	h.JobManager = job.NewManager(1, 1, h.Analyze, zap.NewNop())
+	h.JobManager.Start()
+	defer h.JobManager.Stop()
+	r := newTestJobRouter(h)
+
+	res, jr := serveJobRequest(r, http.MethodPost, "/jobs", `{"url": "`+server.URL+`"}`)
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	if !assert.NotNil(t, jr.Job) {
+		return
+	}
+	id := jr.Job.ID
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && jr.Job.Status != job.StatusDone {
+		time.Sleep(5 * time.Millisecond)
+		res, jr = serveJobRequest(r, http.MethodGet, "/jobs/"+id, "")
+		assert.Equal(t, http.StatusOK, res.Code)
+	}
+	assert.Equal(t, job.StatusDone, jr.Job.Status)
+	assert.Equal(t, expectedResult, *jr.Job.Result)
+
+	res, jr = serveJobRequest(r, http.MethodDelete, "/jobs/"+id, "")
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "job has already finished", jr.Error)
+}
+
+func TestHTTPHandler_JobsOwner(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.Keyring, _ = auth.NewKeyring(
+		[]auth.Client{{Name: "team-a", Key: "key-a"}, {Name: "team-b", Key: "key-b"}},
+		auth.Limits{RateLimit: 100, Burst: 100, DailyQuota: -1},
+	)
+	// Workers are not started so the job stays queued.
+	h.JobManager = job.NewManager(1, 1, nil, zap.NewNop())
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(h.Authenticate)
+	r.POST("/jobs", h.CreateJob)
+	r.GET("/jobs/:id", h.GetJob)
+	r.DELETE("/jobs/:id", h.DeleteJob)
+	serve := func(method, target, body, key string) (*httptest.ResponseRecorder, JobResponse) {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(HeaderAPIKey, key)
+		r.ServeHTTP(res, req)
+
+		var jr JobResponse
+		_ = json.Unmarshal(res.Body.Bytes(), &jr)
+		return res, jr
+	}
+
+	res, jr := serve(http.MethodPost, "/jobs", `{"url": "http://example.com"}`, "key-a")
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	if !assert.NotNil(t, jr.Job) {
+		return
+	}
+	assert.Equal(t, "team-a", jr.Job.Owner)
+	id := jr.Job.ID
+
+	// The job of team-a can neither be seen nor canceled by team-b.
+	res, _ = serve(http.MethodGet, "/jobs/"+id, "", "key-b")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	res, _ = serve(http.MethodDelete, "/jobs/"+id, "", "key-b")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	res, jr = serve(http.MethodDelete, "/jobs/"+id, "", "key-a")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, job.StatusCanceled, jr.Job.Status)
+}
+
+func TestHTTPHandler_JobsFailures(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.JobManager = job.NewManager(1, 0, h.Analyze, zap.NewNop())
+	r := newTestJobRouter(h)
+
+	testCases := []struct {
+		name            string
+		method          string
+		target          string
+		body            string
+		expectedCode    int
+		expectedErr     string
+		expectedErrCode ErrorCode
+	}{
+		{
+			name:            "incorrect body",
+			method:          http.MethodPost,
+			target:          "/jobs",
+			body:            "invalid_request_json",
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "cannot parse request body",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "invalid url",
+			method:          http.MethodPost,
+			target:          "/jobs",
+			body:            `{"url": "invalid_url"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "entered url is not valid",
+			expectedErrCode: ErrorCodeInvalidURL,
+		},
+		{
+			name:            "queue is full",
+			method:          http.MethodPost,
+			target:          "/jobs",
+			body:            `{"url": "http://localhost:22222/"}`,
+			expectedCode:    http.StatusServiceUnavailable,
+			expectedErr:     "could not queue the job",
+			expectedErrCode: ErrorCodeSaturated,
+		},
+		{
+			name:            "get unknown job",
+			method:          http.MethodGet,
+			target:          "/jobs/unknown",
+			expectedCode:    http.StatusNotFound,
+			expectedErr:     "job not found",
+			expectedErrCode: ErrorCodeNotFound,
+		},
+		{
+			name:            "delete unknown job",
+			method:          http.MethodDelete,
+			target:          "/jobs/unknown",
+			expectedCode:    http.StatusNotFound,
+			expectedErr:     "job not found",
+			expectedErrCode: ErrorCodeNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, jr := serveJobRequest(r, tc.method, tc.target, tc.body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedCode, jr.Code)
+			assert.Equal(t, tc.expectedErr, jr.Error)
+			assert.Equal(t, tc.expectedErrCode, jr.ErrorCode)
+			assert.Nil(t, jr.Job)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/monitor"
+	"go.uber.org/zap"
+)
+
+// MonitorRequest is a struct which monitor requests bind to it.
+// Schedule is either an interval like `15m` or a cron expression like `*/15 * * * *`.
+type MonitorRequest struct {
+	URL      string `json:"url"`
+	Schedule string `json:"schedule"`
+}
+
+// MonitorResponse is a struct which is returned to user on the monitor requests.
+type MonitorResponse struct {
+	Monitor   *monitor.Monitor `json:"monitor"`
+	Error     string           `json:"error"`
+	ErrorCode ErrorCode        `json:"error_code,omitempty"`
+	Details   *ErrorDetails    `json:"details,omitempty"`
+	Code      int              `json:"code"`
+}
+
+// MonitorsResponse is a struct which is returned to user on the monitor listing request.
+type MonitorsResponse struct {
+	Monitors  []*monitor.Monitor `json:"monitors"`
+	Error     string             `json:"error"`
+	ErrorCode ErrorCode          `json:"error_code,omitempty"`
+	Details   *ErrorDetails      `json:"details,omitempty"`
+	Code      int                `json:"code"`
+}
+
+// CreateMonitor gets a MonitorRequest and registers a monitor which analyzes the url on its schedule.
+func (h *HTTPHandler) CreateMonitor(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+	u, req, ok := h.bindMonitorRequest(c)
+	if !ok {
+		return
+	}
+
+	m, err := h.MonitorScheduler.Create(c.Request.Context(), u, req.Schedule)
+	if err != nil {
+		h.abortWithMonitorError(c, err)
+		return
+	}
+	h.requestLogger(c).With(zap.String("monitor_id", m.ID)).Info("monitor created successfully")
+
+	c.JSON(http.StatusCreated, &MonitorResponse{
+		Monitor: m,
+		Code:    http.StatusCreated,
+	})
+}
+
+// ListMonitors returns the monitors which are visible to the authenticated client.
+func (h *HTTPHandler) ListMonitors(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &MonitorsResponse{
+		Monitors: h.MonitorScheduler.List(auth.Owner(c.Request.Context())),
+		Code:     http.StatusOK,
+	})
+}
+
+// GetMonitor returns the status and the latest runs of a monitor, the monitors of other clients are not found.
+func (h *HTTPHandler) GetMonitor(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+
+	m, err := h.MonitorScheduler.Get(c.Param("id"), auth.Owner(c.Request.Context()))
+	if err != nil {
+		h.abortWithMonitorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &MonitorResponse{
+		Monitor: m,
+		Code:    http.StatusOK,
+	})
+}
+
+// UpdateMonitor gets a MonitorRequest and changes the url and the schedule of a monitor, the monitors of other
+// clients are not found.
+func (h *HTTPHandler) UpdateMonitor(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+	u, req, ok := h.bindMonitorRequest(c)
+	if !ok {
+		return
+	}
+
+	m, err := h.MonitorScheduler.Update(c.Param("id"), auth.Owner(c.Request.Context()), u, req.Schedule)
+	if err != nil {
+		h.abortWithMonitorError(c, err)
+		return
+	}
+	h.requestLogger(c).With(zap.String("monitor_id", m.ID)).Info("monitor updated successfully")
+
+	c.JSON(http.StatusOK, &MonitorResponse{
+		Monitor: m,
+		Code:    http.StatusOK,
+	})
+}
+
+// ResetMonitorBaseline makes the latest successful run of a monitor the run which the next runs are compared with,
+// so an intended change of the page is accepted. The monitors of other clients are not found.
+func (h *HTTPHandler) ResetMonitorBaseline(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+
+	m, err := h.MonitorScheduler.ResetBaseline(c.Param("id"), auth.Owner(c.Request.Context()))
+	if err != nil {
+		h.abortWithMonitorError(c, err)
+		return
+	}
+	h.requestLogger(c).With(zap.String("monitor_id", m.ID)).Info("monitor baseline reset successfully")
+
+	c.JSON(http.StatusOK, &MonitorResponse{
+		Monitor: m,
+		Code:    http.StatusOK,
+	})
+}
+
+// DeleteMonitor removes a monitor and stops its schedule, the monitors of other clients are not found.
+func (h *HTTPHandler) DeleteMonitor(c *gin.Context) {
+	if !h.monitoringEnabled(c) {
+		return
+	}
+
+	if err := h.MonitorScheduler.Delete(c.Param("id"), auth.Owner(c.Request.Context())); err != nil {
+		h.abortWithMonitorError(c, err)
+		return
+	}
+	h.requestLogger(c).With(zap.String("monitor_id", c.Param("id"))).Info("monitor deleted successfully")
+
+	c.JSON(http.StatusOK, &MonitorResponse{
+		Code: http.StatusOK,
+	})
+}
+
+// monitoringEnabled aborts the request and returns false if there is no monitor scheduler.
+func (h *HTTPHandler) monitoringEnabled(c *gin.Context) bool {
+	if h.MonitorScheduler != nil {
+		return true
+	}
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, &MonitorResponse{
+		Error:     "monitoring is disabled",
+		ErrorCode: ErrorCodeFeatureDisabled,
+		Code:      http.StatusServiceUnavailable,
+	})
+	return false
+}
+
+// bindMonitorRequest binds the MonitorRequest of c and parses its url, it aborts the request and returns false
+// if the request is not valid.
+func (h *HTTPHandler) bindMonitorRequest(c *gin.Context) (*url.URL, *MonitorRequest, bool) {
+	req := &MonitorRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &MonitorResponse{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return nil, nil, false
+	}
+
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &MonitorResponse{
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
+		})
+		return nil, nil, false
+	}
+
+	return u, req, true
+}
+
+// abortWithMonitorError aborts the request with the response which matches err of the monitor scheduler.
+func (h *HTTPHandler) abortWithMonitorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, monitor.ErrNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, &MonitorResponse{
+			Error:     "monitor not found",
+			ErrorCode: ErrorCodeNotFound,
+			Code:      http.StatusNotFound,
+		})
+	case errors.Is(err, monitor.ErrInvalidSchedule):
+		h.requestLogger(c).With(zap.Error(err)).Error("requested schedule is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &MonitorResponse{
+			Error:     err.Error(),
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+	default:
+		h.requestLogger(c).With(zap.Error(err)).Error("error while managing monitor")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, &MonitorResponse{
+			Error:     "could not manage the monitor",
+			ErrorCode: ErrorCodeInternal,
+			Code:      http.StatusInternalServerError,
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/monitor"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestMonitorRouter creates a router which serves the monitor endpoints of h.
+func newTestMonitorRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/monitors", h.CreateMonitor)
+	r.GET("/monitors", h.ListMonitors)
+	r.GET("/monitors/:id", h.GetMonitor)
+	r.PUT("/monitors/:id", h.UpdateMonitor)
+	r.DELETE("/monitors/:id", h.DeleteMonitor)
+	r.POST("/monitors/:id/baseline", h.ResetMonitorBaseline)
+	return r
+}
+
+// serveMonitorRequest serves a request on r and decodes the MonitorResponse.
+func serveMonitorRequest(r *gin.Engine, method, target, body string) (*httptest.ResponseRecorder, MonitorResponse) {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	r.ServeHTTP(res, req)
+
+	var mr MonitorResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &mr)
+	return res, mr
+}
+
+func TestHTTPHandler_Monitors(t *testing.T) {
+	h := newTestHTTPHandler()
+	// The scheduler is not started, so the monitors don't run during the test.
+	h.MonitorScheduler = monitor.NewScheduler(1, time.Minute, h.RecordAnalysis, zap.NewNop())
+	r := newTestMonitorRouter(h)
+
+	res, mr := serveMonitorRequest(r, http.MethodPost, "/monitors", `{"url": "http://example.com", "schedule": "1h"}`)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	if !assert.NotNil(t, mr.Monitor) {
+		return
+	}
+	id := mr.Monitor.ID
+	assert.Equal(t, monitor.StatusPending, mr.Monitor.Status)
+
+	res, mr = serveMonitorRequest(r, http.MethodPut, "/monitors/"+id, `{"url": "http://example.com", "schedule": "0 * * * *"}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "0 * * * *", mr.Monitor.Schedule)
+
+	res, mr = serveMonitorRequest(r, http.MethodGet, "/monitors/"+id, "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, id, mr.Monitor.ID)
+
+	res, mr = serveMonitorRequest(r, http.MethodPost, "/monitors/"+id+"/baseline", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, monitor.StatusPending, mr.Monitor.Status)
+
+	res = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/monitors", nil)
+	r.ServeHTTP(res, req)
+	var msr MonitorsResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &msr)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Len(t, msr.Monitors, 1)
+
+	res, _ = serveMonitorRequest(r, http.MethodDelete, "/monitors/"+id, "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	res, mr = serveMonitorRequest(r, http.MethodGet, "/monitors/"+id, "")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "monitor not found", mr.Error)
+}
+
+func TestHTTPHandler_MonitorsFailures(t *testing.T) {
+	h := newTestHTTPHandler()
+	h.MonitorScheduler = monitor.NewScheduler(1, time.Minute, h.RecordAnalysis, zap.NewNop())
+
+	testCases := []struct {
+		name            string
+		handler         *HTTPHandler
+		method          string
+		target          string
+		body            string
+		expectedCode    int
+		expectedErr     string
+		expectedErrCode ErrorCode
+	}{
+		{
+			name:            "monitoring is disabled",
+			handler:         newTestHTTPHandler(),
+			method:          http.MethodGet,
+			target:          "/monitors",
+			expectedCode:    http.StatusServiceUnavailable,
+			expectedErr:     "monitoring is disabled",
+			expectedErrCode: ErrorCodeFeatureDisabled,
+		},
+		{
+			name:            "incorrect body",
+			handler:         h,
+			method:          http.MethodPost,
+			target:          "/monitors",
+			body:            "invalid_request_json",
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "cannot parse request body",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "invalid url",
+			handler:         h,
+			method:          http.MethodPost,
+			target:          "/monitors",
+			body:            `{"url": "invalid_url", "schedule": "1h"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "entered url is not valid",
+			expectedErrCode: ErrorCodeInvalidURL,
+		},
+		{
+			name:            "too frequent schedule",
+			handler:         h,
+			method:          http.MethodPost,
+			target:          "/monitors",
+			body:            `{"url": "http://example.com", "schedule": "10s"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "schedule is not valid: interval must not be shorter than 1m0s",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "update of missing monitor",
+			handler:         h,
+			method:          http.MethodPut,
+			target:          "/monitors/missing",
+			body:            `{"url": "http://example.com", "schedule": "1h"}`,
+			expectedCode:    http.StatusNotFound,
+			expectedErr:     "monitor not found",
+			expectedErrCode: ErrorCodeNotFound,
+		},
+		{
+			name:            "delete of missing monitor",
+			handler:         h,
+			method:          http.MethodDelete,
+			target:          "/monitors/missing",
+			expectedCode:    http.StatusNotFound,
+			expectedErr:     "monitor not found",
+			expectedErrCode: ErrorCodeNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, mr := serveMonitorRequest(newTestMonitorRouter(tc.handler), tc.method, tc.target, tc.body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedErr, mr.Error)
+			assert.Equal(t, tc.expectedErrCode, mr.ErrorCode)
+			assert.Equal(t, tc.expectedCode, mr.Code)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/openapi"
+	"go.uber.org/zap"
+)
+
+// OpenAPIDocument returns the OpenAPI document of the versioned API.
+func (h *HTTPHandler) OpenAPIDocument(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Document())
+}
+
+// ValidateRequest is a middleware which validates the parameters and the body of requests against the OpenAPI
+// document, the invalid requests get a 400 response which points to the invalid field.
+// It passes all the requests when the handler has no validator.
+func (h *HTTPHandler) ValidateRequest(c *gin.Context) {
+	if h.Validator == nil {
+		c.Next()
+		return
+	}
+
+	params := make(map[string]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = p.Value
+	}
+	err := h.Validator.Validate(c.Request, c.FullPath(), params)
+	if errors.Is(err, openapi.ErrOperationNotFound) {
+		h.requestLogger(c).With(zap.String("route", c.FullPath())).Warn("route is not described by the openapi document")
+		c.Next()
+		return
+	}
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Info("request is not valid")
+		abortWithAPIError(c, newAPIError(ErrorCodeInvalidRequest, "request is not valid", &ErrorDetails{Cause: err.Error()}))
+		return
+	}
+	c.Next()
+}
+
+// Deprecate is a middleware of the deprecated unversioned routes, it points the clients to the versioned route which
+// succeeds the requested one with the Deprecation and Link headers.
+func (h *HTTPHandler) Deprecate(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", "<"+openapi.BasePath+c.Request.URL.Path+`>; rel="successor-version"`)
+	c.Next()
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// newValidatedRouter creates a router which validates the requests of an echo route of POST /api/v1/jobs with h.
+func newValidatedRouter(h *HTTPHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/jobs", h.ValidateRequest, func(c *gin.Context) {
+		req := URLRequest{}
+		_ = c.ShouldBindJSON(&req)
+		c.JSON(http.StatusOK, &req)
+	})
+	return r
+}
+
+func TestHTTPHandler_ValidateRequest(t *testing.T) {
+	h := newTestHTTPHandler()
+	v, err := openapi.NewValidator()
+	if !assert.NoError(t, err) {
+		return
+	}
+	h.Validator = v
+	r := newValidatedRouter(h)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url": 10}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(res, req)
+
+	var actualResponse Response
+	_ = json.Unmarshal(res.Body.Bytes(), &actualResponse)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "request is not valid", actualResponse.Error)
+	assert.Equal(t, ErrorCodeInvalidRequest, actualResponse.ErrorCode)
+	if assert.NotNil(t, actualResponse.Details) {
+		assert.Contains(t, actualResponse.Details.Cause, "body.url")
+	}
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url": "https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"url": "https://example.com"}`, res.Body.String())
+}
+
+func TestHTTPHandler_ValidateRequestWithoutValidator(t *testing.T) {
+	r := newValidatedRouter(newTestHTTPHandler())
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url": 10}`))
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestHTTPHandler_Deprecate(t *testing.T) {
+	h := newTestHTTPHandler()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/jobs/:id", h.Deprecate, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/jobs/j1", nil)
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "true", res.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/jobs/j1>; rel="successor-version"`, res.Header().Get("Link"))
+}
+
+func TestHTTPHandler_OpenAPIDocument(t *testing.T) {
+	h := newTestHTTPHandler()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/openapi.json", h.OpenAPIDocument)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, openapi.Document(), res.Body.Bytes())
+}
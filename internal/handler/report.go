@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+	"go.uber.org/zap"
+)
+
+// reporter returns the Reporter of the format which is negotiated by the Accept header of the request, it returns
+// nil when the json response is accepted.
+func reporter(c *gin.Context) report.Reporter {
+	f := report.Negotiate(c.GetHeader("Accept"))
+	if f == report.FormatJSON {
+		return nil
+	}
+	r, _ := report.New(f)
+	return r
+}
+
+// writeReport responds with the report of pages which is written by r.
+// The report is buffered, so a failure of r results in an internal error instead of a truncated report.
+func (h *HTTPHandler) writeReport(c *gin.Context, r report.Reporter, pages []*report.Page) {
+	var b bytes.Buffer
+	if err := r.Write(&b, pages); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while writing report")
+		abortWithAPIError(c, newAPIError(ErrorCodeInternal, "could not write report", nil))
+		return
+	}
+	c.Data(http.StatusOK, r.ContentType(), b.Bytes())
+}
+
+// evaluate evaluates the rules of the handler on the result of the analysis of the page of u, it returns nil when
+// the handler has no rules or the page has no result.
+func (h *HTTPHandler) evaluate(u string, res *htmlanalysis.Result) *rules.Verdict {
+	if h.Rules == nil || res == nil {
+		return nil
+	}
+	return h.Rules.Evaluate(u, res)
+}
+
+// evaluatePages evaluates the rules of the handler on the pages of a crawl or a sitemap audit which are given by
+// their urls and results, the verdicts are mapped to the urls of the pages.
+func (h *HTTPHandler) evaluatePages(urls []string, results []*htmlanalysis.Result) map[string]*rules.Verdict {
+	if h.Rules == nil {
+		return nil
+	}
+	verdicts := make(map[string]*rules.Verdict, len(urls))
+	for i, u := range urls {
+		if v := h.evaluate(u, results[i]); v != nil {
+			verdicts[u] = v
+		}
+	}
+	return verdicts
+}
+
+// newPage returns the report page of the page of u, the checks of the page are the verdict of the rules of the
+// handler when it has rules.
+func (h *HTTPHandler) newPage(u string, res *htmlanalysis.Result, errMsg string) *report.Page {
+	p := report.NewPage(u, res, errMsg)
+	if v := h.evaluate(u, res); v != nil && errMsg == "" {
+		p.Checks = v.Checks()
+	}
+	return p
+}
+
+// resultPages returns the report pages of the result of an analysis of u.
+func (h *HTTPHandler) resultPages(u string, res *htmlanalysis.Result) []*report.Page {
+	return []*report.Page{h.newPage(u, res, "")}
+}
+
+// crawlVerdicts returns the verdicts of the rules of the handler on the pages of a crawl.
+func (h *HTTPHandler) crawlVerdicts(r *crawler.Report) map[string]*rules.Verdict {
+	if r == nil {
+		return nil
+	}
+	urls := make([]string, 0, len(r.Pages))
+	results := make([]*htmlanalysis.Result, 0, len(r.Pages))
+	for _, p := range r.Pages {
+		urls = append(urls, p.URL)
+		results = append(results, p.Result)
+	}
+	return h.evaluatePages(urls, results)
+}
+
+// sitemapVerdicts returns the verdicts of the rules of the handler on the pages of a sitemap audit.
+func (h *HTTPHandler) sitemapVerdicts(r *sitemap.Report) map[string]*rules.Verdict {
+	urls := make([]string, 0, len(r.Pages))
+	results := make([]*htmlanalysis.Result, 0, len(r.Pages))
+	for _, p := range r.Pages {
+		urls = append(urls, p.URL)
+		results = append(results, p.Result)
+	}
+	return h.evaluatePages(urls, results)
+}
+
+// crawlPages returns the report pages of the pages of a crawl.
+func (h *HTTPHandler) crawlPages(r *crawler.Report) []*report.Page {
+	pages := make([]*report.Page, 0, len(r.Pages))
+	for _, p := range r.Pages {
+		errMsg := p.Error
+		if p.SkippedByRobots {
+			errMsg = "page is disallowed by robots.txt"
+		}
+		pages = append(pages, h.newPage(p.URL, p.Result, errMsg))
+	}
+	return pages
+}
+
+// sitemapPages returns the report pages of the pages of a sitemap audit.
+func (h *HTTPHandler) sitemapPages(r *sitemap.Report) []*report.Page {
+	pages := make([]*report.Page, 0, len(r.Pages))
+	for _, p := range r.Pages {
+		pages = append(pages, h.newPage(p.URL, p.Result, p.Error))
+	}
+	return pages
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveReportRequest serves a POST request of body to path of h with the Accept header accept.
+func serveReportRequest(h *HTTPHandler, path, body, accept string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/analyze-url", h.AnalyzeURL)
+	r.POST("/crawl", h.CrawlURL)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Accept", accept)
+	r.ServeHTTP(res, req)
+	return res
+}
+
+func TestHTTPHandler_Report(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, `<!DOCTYPE html><title>Home</title><h1>Home</h1><a href="/missing">missing</a>`)
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	h.CrawlOptions = crawler.Options{MaxDepth: 1, MaxPages: 10, Concurrency: 2}
+	body := `{"url": "` + server.URL + `"}`
+
+	res := serveReportRequest(h, "/analyze-url", body, "application/json")
+	assert.Equal(t, http.StatusOK, res.Code)
+	var ar Response
+	if assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &ar)) {
+		assert.Equal(t, "Home", ar.Result.PageTitle)
+	}
+
+	res = serveReportRequest(h, "/analyze-url", body, "application/junit+xml")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "application/junit+xml; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Contains(t, res.Body.String(), `<testcase name="inaccessible_links" classname="`+server.URL+`">`)
+
+	res = serveReportRequest(h, "/crawl", body, "text/csv; rows=links")
+	assert.Equal(t, http.StatusOK, res.Code)
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	if assert.NoError(t, err) {
+		assert.Equal(t, [][]string{
+			{"page_url", "link_url", "status"},
+			{server.URL + "/", server.URL + "/missing", "inaccessible"},
+		}, rows)
+	}
+
+	// Failures are always json.
+	res = serveReportRequest(h, "/analyze-url", `{"url": "invalid"}`, "text/html")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "application/json; charset=utf-8", res.Header().Get("Content-Type"))
+}
+
+func TestHTTPHandler_Rules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(res, `<!DOCTYPE html><title>Home</title><h1>Home</h1><h1>Again</h1>`)
+	}))
+	defer server.Close()
+
+	rs, err := rules.Parse([]byte(`
+rules:
+  - name: one-h1
+    metric: headings.h1
+    min: 1
+    max: 1
+  - name: title
+    metric: title
+    matches: ^Home$
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	h.CrawlOptions = crawler.Options{MaxDepth: 0, MaxPages: 1, Concurrency: 1}
+	h.Rules = rs
+	body := `{"url": "` + server.URL + `"}`
+	expectedVerdict := &rules.Verdict{Rules: []*rules.RuleResult{
+		{Name: "one-h1", Message: "headings.h1 is 2, expected between 1 and 1"},
+		{Name: "title", Passed: true, Message: "title is `Home`"},
+	}}
+
+	res := serveReportRequest(h, "/analyze-url", body, "application/json")
+	assert.Equal(t, http.StatusOK, res.Code)
+	var ar Response
+	if assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &ar)) {
+		assert.Equal(t, expectedVerdict, ar.Verdict)
+	}
+
+	res = serveReportRequest(h, "/crawl", body, "application/json")
+	assert.Equal(t, http.StatusOK, res.Code)
+	var cr CrawlResponse
+	if assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &cr)) {
+		assert.Equal(t, map[string]*rules.Verdict{server.URL + "/": expectedVerdict}, cr.Verdicts)
+	}
+
+	// The checks of the reports are the rules.
+	res = serveReportRequest(h, "/analyze-url", body, "application/junit+xml")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `<testcase name="one-h1" classname="`+server.URL+`">`)
+	assert.NotContains(t, res.Body.String(), `<testcase name="inaccessible_links"`)
+}
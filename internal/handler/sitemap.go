@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/webhook"
+	"github.com/mammadmodi/detective/pkg/rules"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+	"go.uber.org/zap"
+)
+
+// SitemapResponse is a struct which is returned to user on the sitemap request.
+// Verdicts maps the urls of the analyzed pages to the outcome of the rules of the handler on them.
+type SitemapResponse struct {
+	Report    *sitemap.Report           `json:"report"`
+	Verdicts  map[string]*rules.Verdict `json:"verdicts,omitempty"`
+	Error     string                    `json:"error"`
+	ErrorCode ErrorCode                 `json:"error_code,omitempty"`
+	Details   *ErrorDetails             `json:"details,omitempty"`
+	Code      int                       `json:"code"`
+}
+
+// AuditSitemap gets an URLRequest which points to a sitemap, analyzes every url which is listed in the sitemap
+// and reports the inconsistencies of the sitemap and the pages. The pages are reported in the format which is
+// negotiated by the Accept header, the inconsistencies are only included in the json response. Every url of the
+// sitemap is charged from the daily quota of the client.
+func (h *HTTPHandler) AuditSitemap(c *gin.Context) {
+	req := URLRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while binding request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &SitemapResponse{
+			Error:     "cannot parse request body",
+			ErrorCode: ErrorCodeInvalidRequest,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil || u.Host == "" {
+		h.requestLogger(c).With(zap.Error(err)).Error("requested url is not valid")
+		c.AbortWithStatusJSON(http.StatusBadRequest, &SitemapResponse{
+			Error:     "entered url is not valid",
+			ErrorCode: ErrorCodeInvalidURL,
+			Code:      http.StatusBadRequest,
+		})
+		return
+	}
+
+	urls, err := sitemap.Fetch(c.Request.Context(), h.HTTPClient, u, h.SitemapOptions.MaxURLs)
+	if err != nil {
+		h.requestLogger(c).With(zap.Error(err)).Error("error while fetching sitemap")
+		apiErr := sitemapAPIError(err)
+		c.AbortWithStatusJSON(apiErr.Status, &SitemapResponse{
+			Error:     apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Details:   apiErr.Details,
+			Code:      apiErr.Status,
+		})
+		return
+	}
+	h.requestLogger(c).With(zap.Int("urls_count", len(urls))).Info("sitemap fetched successfully")
+	if !h.chargePages(c, len(urls)) {
+		return
+	}
+
+	a := sitemap.NewAuditor(h.performGetRequest, sitemap.AnalyzeFunc(h.HTMLAnalyzeFunc), h.SitemapOptions.Concurrency, h.requestLogger(c).Named("sitemap"))
+	report := a.Audit(c.Request.Context(), u.String(), urls)
+	h.Notifier.Notify(webhook.EventBatchFinished, &BatchEvent{Kind: BatchKindSitemap, URL: u.String(), Report: report})
+	if r := reporter(c); r != nil {
+		h.writeReport(c, r, h.sitemapPages(report))
+		return
+	}
+
+	c.JSON(http.StatusOK, &SitemapResponse{
+		Report:   report,
+		Verdicts: h.sitemapVerdicts(report),
+		Code:     http.StatusOK,
+	})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/internal/webhook"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/sitemap"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// serveSitemapRequest serves body on the sitemap endpoint of h and decodes the SitemapResponse.
+func serveSitemapRequest(h *HTTPHandler, body string) (*httptest.ResponseRecorder, SitemapResponse) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/sitemap", h.AuditSitemap)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/sitemap", strings.NewReader(body))
+	r.ServeHTTP(res, req)
+
+	var sr SitemapResponse
+	_ = json.Unmarshal(res.Body.Bytes(), &sr)
+	return res, sr
+}
+
+func TestHTTPHandler_AuditSitemapSuccess(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/sitemap.xml":
+			_, _ = io.WriteString(res, `<urlset><url><loc>`+server.URL+`/</loc></url>`+
+				`<url><loc>`+server.URL+`/old</loc></url><url><loc>`+server.URL+`/gone</loc></url></urlset>`)
+		case "/old":
+			http.Redirect(res, req, "/", http.StatusMovedPermanently)
+		case "/":
+			res.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(res, `<a href="/about">About</a>`)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = func(_ context.Context, _ *url.URL, _ string) (*htmlanalysis.Result, error) {
+		return &htmlanalysis.Result{}, nil
+	}
+	h.SitemapOptions = sitemap.Options{MaxURLs: 10, Concurrency: 2}
+
+	events := make(chan webhook.Event, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		var e webhook.Event
+		_ = json.NewDecoder(req.Body).Decode(&e)
+		events <- e
+	}))
+	defer receiver.Close()
+	h.Notifier = webhook.NewNotifier([]webhook.Target{{URL: receiver.URL}}, http.DefaultClient, webhook.Options{}, zap.NewNop())
+
+	res, sr := serveSitemapRequest(h, `{"url": "`+server.URL+`/sitemap.xml"}`)
+	h.Notifier.Stop(context.Background())
+	assert.Equal(t, http.StatusOK, res.Code)
+	if !assert.NotNil(t, sr.Report) {
+		return
+	}
+	assert.Len(t, sr.Report.Pages, 3)
+	assert.Equal(t, []string{server.URL + "/old"}, sr.Report.RedirectedURLs)
+	assert.Equal(t, []string{server.URL + "/gone"}, sr.Report.BrokenURLs)
+	assert.Equal(t, []string{server.URL + "/about"}, sr.Report.MissingURLs)
+
+	if assert.Len(t, events, 1) {
+		e := <-events
+		assert.Equal(t, webhook.EventBatchFinished, e.Type)
+		assert.Equal(t, BatchKindSitemap, e.Data.(map[string]interface{})["kind"])
+	}
+}
+
+func TestHTTPHandler_AuditSitemapFailures(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		expectedCode    int
+		expectedErr     string
+		expectedErrCode ErrorCode
+	}{
+		{
+			name:            "incorrect body",
+			body:            "invalid_request_json",
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "cannot parse request body",
+			expectedErrCode: ErrorCodeInvalidRequest,
+		},
+		{
+			name:            "invalid url",
+			body:            `{"url": "invalid_url"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedErr:     "entered url is not valid",
+			expectedErrCode: ErrorCodeInvalidURL,
+		},
+		{
+			name:            "inaccessible sitemap",
+			body:            `{"url": "http://localhost:22222/sitemap.xml"}`,
+			expectedCode:    http.StatusBadGateway,
+			expectedErr:     "could not retrieve sitemap of url",
+			expectedErrCode: ErrorCodeUpstreamUnreachable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, sr := serveSitemapRequest(newTestHTTPHandler(), tc.body)
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedErr, sr.Error)
+			assert.Equal(t, tc.expectedErrCode, sr.ErrorCode)
+			assert.Nil(t, sr.Report)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"go.uber.org/zap"
+)
+
+// List of the server-sent event names which are emitted by AnalyzeURLStream in addition to htmlanalysis phases.
+const (
+	EventFetched = "fetched"
+	EventResult  = "result"
+	EventFailure = "failure"
+)
+
+// streamEvent is a server-sent event which is waiting to be written to the client.
+type streamEvent struct {
+	name string
+	data interface{}
+}
+
+// AnalyzeURLStream analyzes the url in the `url` query parameter and streams the outcome of each phase
+// as server-sent events, the last event is either a result or a failure event which holds a Response.
+func (h *HTTPHandler) AnalyzeURLStream(c *gin.Context) {
+	ctx := c.Request.Context()
+	events := make(chan streamEvent)
+	send := func(name string, data interface{}) {
+		select {
+		case events <- streamEvent{name: name, data: data}:
+		case <-ctx.Done():
+		}
+	}
+
+	// The url is read before starting the analysis because c is reused by other requests once the handler returns.
+	rawURL := c.Query("url")
+	go func() {
+		defer close(events)
+		analysisID, res, apiErr := h.AnalyzeRawURL(ctx, rawURL, send)
+		if apiErr != nil {
+			send(EventFailure, &Response{
+				Error:     apiErr.Message,
+				ErrorCode: apiErr.Code,
+				Details:   apiErr.Details,
+				Code:      apiErr.Status,
+			})
+			return
+		}
+		send(EventResult, &Response{
+			AnalysisID: analysisID,
+			Result:     res,
+			Verdict:    h.evaluate(rawURL, res),
+			Code:       http.StatusOK,
+		})
+	}()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			// Data of all the events is encoded to json, because SSEvent only encodes structs, slices and maps.
+			b, err := json.Marshal(e.data)
+			if err != nil {
+				h.requestLogger(c).With(zap.Error(err), zap.String("event", e.name)).Error("error while encoding event")
+				continue
+			}
+			c.SSEvent(e.name, string(b))
+			c.Writer.Flush()
+		case <-ctx.Done():
+			h.requestLogger(c).Info("client closed the stream")
+			return
+		}
+	}
+}
+
+// AnalyzeRawURL performs the analysis of rawURL like analyzeAndEmit and stores its result in the analysis history.
+// It returns the id of the stored analysis, which is empty when the history is disabled, and the result of the
+// analysis or an APIError if it fails.
+func (h *HTTPHandler) AnalyzeRawURL(
+	ctx context.Context,
+	rawURL string,
+	send func(name string, data interface{}),
+) (analysisID string, res *htmlanalysis.Result, apiErr *APIError) {
+	u, res, apiErr := h.analyzeAndEmit(ctx, rawURL, send)
+	if apiErr != nil {
+		return "", nil, apiErr
+	}
+	return h.saveAnalysis(ctx, u, res), res, nil
+}
+
+// analyzeAndEmit performs the analysis of rawURL and sends its events to send.
+// It returns the parsed url and the result of the analysis or an APIError if it fails.
+func (h *HTTPHandler) analyzeAndEmit(
+	ctx context.Context,
+	rawURL string,
+	send func(name string, data interface{}),
+) (u *url.URL, res *htmlanalysis.Result, apiErr *APIError) {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		h.logger(ctx).With(zap.Error(err)).Error("requested url is not valid")
+		return nil, nil, newAPIError(ErrorCodeInvalidURL, "entered url is not valid", nil)
+	}
+	h.logger(ctx).With(zap.Any("entered_url", u)).Info("entered url parsed successfully")
+
+	p, err := h.fetchPage(ctx, u)
+	if err != nil {
+		h.logger(ctx).With(zap.Error(err)).Error("error while performing request")
+		return nil, nil, fetchAPIError(err)
+	}
+	send(EventFetched, p.URL.String())
+
+	ctx = htmlanalysis.WithEventFunc(ctx, func(e htmlanalysis.Event) {
+		send(string(e.Phase), e.Data)
+	})
+	res, err = h.HTMLAnalyzeFunc(ctx, u, p.HTML)
+	if err != nil {
+		h.logger(ctx).With(zap.Error(err)).Error("error while parsing html")
+		return nil, nil, analysisAPIError(err)
+	}
+	if err := ctx.Err(); err != nil {
+		h.logger(ctx).With(zap.Error(err)).Warn("analysis was interrupted")
+		return nil, nil, interruptedAPIError(err)
+	}
+	res.TLS = h.tlsReport(p)
+	h.logger(ctx).With(zap.Any("result", res)).Info("html analyzed successfully")
+
+	return u, res, nil
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveStreamRequest requests the stream endpoint for rawURL and returns the names of the received events.
+func serveStreamRequest(h *HTTPHandler, rawURL string) (res *httptest.ResponseRecorder, eventNames []string) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/analyze-url/stream", h.AnalyzeURLStream)
+
+	res = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/analyze-url/stream?url="+url.QueryEscape(rawURL), nil)
+	r.ServeHTTP(res, req)
+
+	for _, m := range regexp.MustCompile(`(?m)^event:(\S+)$`).FindAllStringSubmatch(res.Body.String(), -1) {
+		eventNames = append(eventNames, m[1])
+	}
+	return res, eventNames
+}
+
+func TestHTTPHandler_AnalyzeURLStreamSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		res.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(res, "<!DOCTYPE html><title>Detective</title><h1>Detective</h1>")
+	}))
+	defer server.Close()
+
+	h := newTestHTTPHandler()
+	h.HTMLAnalyzeFunc = htmlanalysis.Analyze
+	res, eventNames := serveStreamRequest(h, server.URL)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, []string{
+		EventFetched,
+		string(htmlanalysis.PhaseParsed),
+		string(htmlanalysis.PhaseHTMLVersion),
+		string(htmlanalysis.PhaseTitle),
+		string(htmlanalysis.PhaseHeadings),
+		string(htmlanalysis.PhaseLinks),
+		string(htmlanalysis.PhaseLoginForm),
+		EventResult,
+	}, eventNames)
+	assert.Contains(t, res.Body.String(), "event:title\ndata:\"Detective\"\n")
+	assert.Contains(t, res.Body.String(), `"page_title":"Detective"`)
+}
+
+func TestHTTPHandler_AnalyzeURLStreamFailures(t *testing.T) {
+	testCases := []struct {
+		name        string
+		url         string
+		expectedErr string
+	}{
+		{
+			name:        "invalid url",
+			url:         "invalid_url",
+			expectedErr: `"error":"entered url is not valid","error_code":"INVALID_URL","code":400`,
+		},
+		{
+			name:        "inaccessible url",
+			url:         "http://localhost:22222/",
+			expectedErr: `"error":"could not retrieve html body of url","error_code":"UPSTREAM_UNREACHABLE"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, eventNames := serveStreamRequest(newTestHTTPHandler(), tc.url)
+			assert.Equal(t, []string{EventFailure}, eventNames)
+			assert.Contains(t, res.Body.String(), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// analysesBucket maps the id of records to their json encoded value.
+	analysesBucket = []byte("analyses")
+	// urlIndexBucket maps `url \x00 created_at id` keys to the id of records, so the records of a url
+	// are sorted by their creation time.
+	urlIndexBucket = []byte("url_index")
+	// monitorsBucket maps the id of monitors to their json encoded definition.
+	monitorsBucket = []byte("monitors")
+)
+
+// pruneInterval is the minimum time between two prunes of the records which are beyond the retention.
+const pruneInterval = time.Minute
+
+// Retention limits the records which are kept in a BoltStore, the records which are older than MaxAge or aren't
+// among the newest MaxRecords records are pruned. A zero MaxAge or MaxRecords disables its limit.
+type Retention struct {
+	MaxAge     time.Duration
+	MaxRecords int
+}
+
+// BoltStore is a Store which persists the records in a bbolt database file.
+// prunedAt is the last time which the records beyond the retention were pruned.
+type BoltStore struct {
+	db *bolt.DB
+
+	mu        sync.Mutex
+	retention Retention
+	prunedAt  time.Time
+}
+
+// NewBoltStore opens or creates the database file of path and returns a BoltStore on it.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error while opening history database `%s`: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{analysesBucket, urlIndexBucket, monitorsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("error while creating history buckets: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Save stores r in the database.
+func (s *BoltStore) Save(r *Record) error {
+	if r.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("error while generating record id: %w", err)
+		}
+		r.ID = id
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+
+	v, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error while encoding record: %w", err)
+	}
+
+	now := time.Now()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(analysesBucket).Put([]byte(r.ID), v); err != nil {
+			return err
+		}
+		if err := tx.Bucket(urlIndexBucket).Put(indexKey(r), []byte(r.ID)); err != nil {
+			return err
+		}
+		if rt, ok := s.pruneDue(now); ok {
+			return prune(tx, now, rt)
+		}
+		return nil
+	})
+}
+
+// SetRetention sets the limits of the records which are kept, the records beyond them are pruned by the saves
+// at most once per minute.
+func (s *BoltStore) SetRetention(rt Retention) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retention = rt
+}
+
+// pruneDue reports whether the records must be pruned at now and returns the retention which they're pruned by.
+func (s *BoltStore) pruneDue(now time.Time) (Retention, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retention == (Retention{}) || now.Sub(s.prunedAt) < pruneInterval {
+		return Retention{}, false
+	}
+	s.prunedAt = now
+	return s.retention, true
+}
+
+// prune deletes the records which are beyond rt at now in tx.
+// The baselines of monitors are kept, so the next runs of the monitors are still compared with them.
+func prune(tx *bolt.Tx, now time.Time, rt Retention) error {
+	baselines := map[string]bool{}
+	err := tx.Bucket(monitorsBucket).ForEach(func(_, v []byte) error {
+		d := &MonitorDefinition{}
+		if err := json.Unmarshal(v, d); err != nil {
+			return err
+		}
+		baselines[d.BaselineID] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		key       []byte
+		id        string
+		createdAt time.Time
+	}
+	var entries []entry
+	index := tx.Bucket(urlIndexBucket)
+	err = index.ForEach(func(k, v []byte) error {
+		i := bytes.IndexByte(k, 0)
+		if i < 0 || len(k) < i+9 {
+			return nil
+		}
+		createdAt := time.Unix(0, int64(binary.BigEndian.Uint64(k[i+1:i+9])))
+		entries = append(entries, entry{key: append([]byte(nil), k...), id: string(v), createdAt: createdAt})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sort the records the newest first, so the ones after MaxRecords are the oldest.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].createdAt.After(entries[j].createdAt) })
+	analyses := tx.Bucket(analysesBucket)
+	for n, e := range entries {
+		expired := rt.MaxAge > 0 && now.Sub(e.createdAt) > rt.MaxAge
+		if !expired && (rt.MaxRecords == 0 || n < rt.MaxRecords) || baselines[e.id] {
+			continue
+		}
+		if err := index.Delete(e.key); err != nil {
+			return err
+		}
+		if err := analyses.Delete([]byte(e.id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the record with the given id.
+func (s *BoltStore) Get(id string) (*Record, error) {
+	var r *Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(analysesBucket).Get([]byte(id))
+		if v == nil {
+			return ErrNotFound
+		}
+		r = &Record{}
+		return json.Unmarshal(v, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// List returns at most limit records of url which are visible to owner, the newest first.
+func (s *BoltStore) List(url, owner string, limit int) ([]*Record, error) {
+	records := []*Record{}
+	prefix := append([]byte(url), 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		analyses := tx.Bucket(analysesBucket)
+		c := tx.Bucket(urlIndexBucket).Cursor()
+
+		// Seek to the first key after the prefix and walk backward.
+		upperBound := append([]byte(url), 1)
+		k, id := c.Seek(upperBound)
+		if k == nil {
+			k, id = c.Last()
+		} else {
+			k, id = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix) && len(records) < limit; k, id = c.Prev() {
+			v := analyses.Get(id)
+			if v == nil {
+				continue
+			}
+			r := &Record{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return err
+			}
+			if r.VisibleTo(owner) {
+				records = append(records, r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SaveMonitor creates or replaces the definition of monitor d.
+func (s *BoltStore) SaveMonitor(d *MonitorDefinition) error {
+	v, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("error while encoding monitor: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Put([]byte(d.ID), v)
+	})
+}
+
+// DeleteMonitor removes the definition of the monitor with the given id, it's a no-op for missing monitors.
+func (s *BoltStore) DeleteMonitor(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Delete([]byte(id))
+	})
+}
+
+// ListMonitors returns the definitions of all the stored monitors.
+func (s *BoltStore) ListMonitors() ([]*MonitorDefinition, error) {
+	definitions := []*MonitorDefinition{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(monitorsBucket).ForEach(func(_, v []byte) error {
+			d := &MonitorDefinition{}
+			if err := json.Unmarshal(v, d); err != nil {
+				return err
+			}
+			definitions = append(definitions, d)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// Close closes the database file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// indexKey returns the key of r in the url index bucket.
+func indexKey(r *Record) []byte {
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(r.CreatedAt.UnixNano()))
+
+	key := make([]byte, 0, len(r.URL)+1+len(ts)+len(r.ID))
+	key = append(key, r.URL...)
+	key = append(key, 0)
+	key = append(key, ts...)
+	return append(key, r.ID...)
+}
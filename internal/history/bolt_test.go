@@ -0,0 +1,163 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBoltStore creates a BoltStore on a temporary database file which is removed after the test.
+func newTestBoltStore(t *testing.T) *BoltStore {
+	s, err := NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("error while opening bolt store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestBoltStore_SaveGet(t *testing.T) {
+	s := newTestBoltStore(t)
+
+	r := &Record{URL: "http://example.com", Result: &htmlanalysis.Result{PageTitle: "Example"}}
+	if !assert.NoError(t, s.Save(r)) {
+		return
+	}
+	assert.NotEmpty(t, r.ID)
+	assert.False(t, r.CreatedAt.IsZero())
+
+	stored, err := s.Get(r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, r.URL, stored.URL)
+	assert.Equal(t, r.Result, stored.Result)
+	assert.True(t, r.CreatedAt.Equal(stored.CreatedAt))
+
+	stored, err = s.Get("missing")
+	assert.Nil(t, stored)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBoltStore_List(t *testing.T) {
+	s := newTestBoltStore(t)
+
+	now := time.Now().UTC()
+	for i, u := range []string{
+		"http://example.com",
+		"http://example.com/about",
+		"http://example.com",
+		"http://example.com",
+		"http://example.co",
+	} {
+		r := &Record{URL: u, CreatedAt: now.Add(time.Duration(i) * time.Minute), Result: &htmlanalysis.Result{}}
+		if !assert.NoError(t, s.Save(r)) {
+			return
+		}
+	}
+
+	records, err := s.List("http://example.com", "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		assert.True(t, records[0].CreatedAt.Equal(now.Add(3*time.Minute)))
+		assert.True(t, records[1].CreatedAt.Equal(now.Add(2*time.Minute)))
+		assert.True(t, records[2].CreatedAt.Equal(now))
+	}
+
+	records, err = s.List("http://example.com", "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = s.List("http://example.org", "", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestBoltStore_ListOwner(t *testing.T) {
+	s := newTestBoltStore(t)
+
+	for _, owner := range []string{"team-a", "team-b", ""} {
+		if !assert.NoError(t, s.Save(&Record{URL: "http://example.com", Owner: owner, Result: &htmlanalysis.Result{}})) {
+			return
+		}
+	}
+
+	records, err := s.List("http://example.com", "team-a", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		for _, r := range records {
+			assert.NotEqual(t, "team-b", r.Owner)
+		}
+	}
+
+	// All the records are listed when authentication is disabled.
+	records, err = s.List("http://example.com", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestBoltStore_Retention(t *testing.T) {
+	s := newTestBoltStore(t)
+
+	now := time.Now().UTC()
+	save := func(u string, createdAt time.Time) *Record {
+		r := &Record{URL: u, CreatedAt: createdAt, Result: &htmlanalysis.Result{}}
+		assert.NoError(t, s.Save(r))
+		return r
+	}
+	baseline := save("http://example.org", now.Add(-3*time.Hour))
+	expired := save("http://example.org", now.Add(-2*time.Hour))
+	oldest := save("http://example.com", now.Add(-3*time.Minute))
+	assert.NoError(t, s.SaveMonitor(&MonitorDefinition{ID: "m1", URL: "http://example.org", BaselineID: baseline.ID}))
+	save("http://example.com", now.Add(-2*time.Minute))
+	save("http://example.com", now.Add(-time.Minute))
+
+	// Without a retention the records are kept, and the save after setting it prunes them.
+	s.SetRetention(Retention{MaxAge: time.Hour, MaxRecords: 3})
+	save("http://example.com", time.Time{})
+
+	for _, id := range []string{expired.ID, oldest.ID} {
+		_, err := s.Get(id)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	_, err := s.Get(baseline.ID)
+	assert.NoError(t, err, "baselines of monitors must be kept")
+	records, err := s.List("http://example.com", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	// The records are pruned at most once per pruneInterval.
+	save("http://example.com", time.Time{})
+	records, err = s.List("http://example.com", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+}
+
+func TestBoltStore_Monitors(t *testing.T) {
+	s := newTestBoltStore(t)
+
+	now := time.Now().UTC()
+	d := &MonitorDefinition{ID: "m1", URL: "http://example.com", Schedule: "15m", CreatedAt: now, UpdatedAt: now}
+	assert.NoError(t, s.SaveMonitor(d))
+	assert.NoError(t, s.SaveMonitor(&MonitorDefinition{ID: "m2", URL: "http://example.org", Schedule: "1h"}))
+
+	// Saving a monitor again replaces its definition.
+	d.BaselineID = "analysis"
+	assert.NoError(t, s.SaveMonitor(d))
+
+	definitions, err := s.ListMonitors()
+	assert.NoError(t, err)
+	if assert.Len(t, definitions, 2) {
+		assert.Equal(t, "m1", definitions[0].ID)
+		assert.Equal(t, "analysis", definitions[0].BaselineID)
+		assert.True(t, now.Equal(definitions[0].CreatedAt))
+		assert.Equal(t, "m2", definitions[1].ID)
+	}
+
+	assert.NoError(t, s.DeleteMonitor("m2"))
+	assert.NoError(t, s.DeleteMonitor("missing"))
+	definitions, err = s.ListMonitors()
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 1)
+}
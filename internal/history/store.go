@@ -0,0 +1,64 @@
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+)
+
+// ErrNotFound is returned when there is no record with the requested id.
+var ErrNotFound = errors.New("analysis not found")
+
+// Record is a stored analysis of a url.
+// Owner is the name of the API client which ran the analysis or owns the monitor which ran it, it's empty for the
+// analyses which run without authentication.
+type Record struct {
+	ID        string               `json:"id"`
+	URL       string               `json:"url"`
+	Owner     string               `json:"owner,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	Result    *htmlanalysis.Result `json:"result"`
+}
+
+// VisibleTo reports whether r can be seen by the client named owner, the records without an owner are visible to
+// all the clients and an empty owner, which means authentication is disabled, sees all the records.
+func (r *Record) VisibleTo(owner string) bool {
+	return owner == "" || r.Owner == "" || r.Owner == owner
+}
+
+// Store persists analysis records.
+type Store interface {
+	// Save stores r, it sets the ID and CreatedAt of r if they are empty.
+	Save(r *Record) error
+	// Get returns the record with the given id or ErrNotFound.
+	Get(id string) (*Record, error)
+	// List returns at most limit records of url which are visible to owner, the newest first.
+	List(url, owner string, limit int) ([]*Record, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// MonitorDefinition is a stored monitor of a url which is restored after restarts.
+// BaselineID is the id of the record of the last healthy run of the monitor which its next runs are compared with
+// and Owner is the name of the API client which created the monitor.
+type MonitorDefinition struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Owner      string    `json:"owner,omitempty"`
+	Schedule   string    `json:"schedule"`
+	BaselineID string    `json:"baseline_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// newID generates a random hex string which is used as identifier of records.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+)
+
+// Status is the state of a Job during its lifecycle.
+type Status string
+
+// List of available job statuses.
+const (
+	StatusQueued   Status = "queued"
+	StatusRunning  Status = "running"
+	StatusDone     Status = "done"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// Progress shows how many links of a page have been checked so far.
+type Progress struct {
+	CheckedLinks int `json:"checked_links"`
+	TotalLinks   int `json:"total_links"`
+}
+
+// Job is an asynchronous analysis of a url.
+// Result is only filled when the Status is StatusDone and Error is only filled when the Status is StatusFailed.
+// Owner is the name of the API client which submitted the job, it's empty when the job is submitted without
+// authentication.
+type Job struct {
+	ID        string               `json:"id"`
+	URL       string               `json:"url"`
+	Owner     string               `json:"owner,omitempty"`
+	Status    Status               `json:"status"`
+	Progress  Progress             `json:"progress"`
+	Result    *htmlanalysis.Result `json:"result"`
+	Error     string               `json:"error"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+
+	u      *url.URL
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// finished reports whether the job has reached one of its final statuses.
+func (j *Job) finished() bool {
+	return j.Status == StatusDone || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// visibleTo reports whether the job can be seen by the client named owner, the jobs without an owner are visible
+// to all the clients and an empty owner, which means authentication is disabled, sees all the jobs.
+func (j *Job) visibleTo(owner string) bool {
+	return owner == "" || j.Owner == "" || j.Owner == owner
+}
+
+// snapshot returns a copy of the exported fields of the job which is safe to be used by other go routines.
+func (j *Job) snapshot() *Job {
+	return &Job{
+		ID:        j.ID,
+		URL:       j.URL,
+		Owner:     j.Owner,
+		Status:    j.Status,
+		Progress:  j.Progress,
+		Result:    j.Result,
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt,
+		UpdatedAt: j.UpdatedAt,
+	}
+}
+
+// newID generates a random hex string which is used as identifier of jobs.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,288 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrNotFound is returned when there is no job with the requested id.
+	ErrNotFound = errors.New("job not found")
+	// ErrQueueFull is returned when the queue of jobs has no more capacity.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrFinished is returned when a job which has already finished is canceled.
+	ErrFinished = errors.New("job has already finished")
+	// ErrClosed is returned when a job is submitted after the manager is stopped or shut down.
+	ErrClosed = errors.New("job manager is shut down")
+)
+
+// DefaultTTL is the time which the finished jobs are kept for by default.
+const DefaultTTL = time.Hour
+
+// RunFunc is a type of function which analyzes a url and reports the progress of link checking to progress.
+type RunFunc func(ctx context.Context, u *url.URL, progress htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error)
+
+// FinishFunc is a type of function which is called with a snapshot of a job when its run finishes.
+type FinishFunc func(j *Job)
+
+// Manager keeps the jobs in memory and runs them using a pool of workers.
+// The finished jobs are evicted ttl after they finish, closed shows that the queue is closed by Stop or Shutdown.
+type Manager struct {
+	run      RunFunc
+	workers  int
+	logger   *zap.Logger
+	onFinish FinishFunc
+	ttl      time.Duration
+
+	mu     sync.RWMutex
+	jobs   map[string]*Job
+	queue  chan *Job
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a Manager which runs at most workers jobs concurrently and keeps at most queueSize
+// jobs waiting in its queue.
+func NewManager(workers, queueSize int, run RunFunc, logger *zap.Logger) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &Manager{
+		run:     run,
+		workers: workers,
+		logger:  logger,
+		ttl:     DefaultTTL,
+		jobs:    map[string]*Job{},
+		queue:   make(chan *Job, queueSize),
+	}
+}
+
+// OnFinish sets f to be called when the run of a job finishes, it must be called before Start.
+func (m *Manager) OnFinish(f FinishFunc) {
+	m.onFinish = f
+}
+
+// SetTTL sets the time which the finished jobs are kept for before they're evicted, it must be called before Start.
+func (m *Manager) SetTTL(ttl time.Duration) {
+	m.ttl = ttl
+}
+
+// Start launches the workers of the manager.
+func (m *Manager) Start() {
+	m.wg.Add(m.workers)
+	for i := 0; i < m.workers; i++ {
+		go func() {
+			defer m.wg.Done()
+			for j := range m.queue {
+				m.process(j)
+			}
+		}()
+	}
+}
+
+// Stop cancels all the unfinished jobs and waits for the workers to exit.
+// The manager must not be used after calling Stop.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	for _, j := range m.jobs {
+		j.cancel()
+	}
+	m.closeQueue()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// closeQueue closes the queue of the manager if it's not closed yet, the caller must hold the lock of m.
+func (m *Manager) closeQueue() {
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+}
+
+// Shutdown stops accepting new jobs and waits for the queued and running jobs to finish.
+// When ctx is done before that, the unfinished jobs are canceled and the error of ctx is returned after the
+// workers exit. The manager must not be used after calling Shutdown.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closeQueue()
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	for _, j := range m.jobs {
+		j.cancel()
+	}
+	m.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
+
+// Submit creates a queued job for u and returns a snapshot of it.
+// The job doesn't run in ctx, only the authenticated client of ctx is passed to the run and owns the job.
+// It returns ErrClosed after the manager is stopped or shut down, and the expired finished jobs are evicted on each
+// submit.
+func (m *Manager) Submit(ctx context.Context, u *url.URL) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("error while generating job id: %w", err)
+	}
+
+	now := time.Now()
+	ctx, cancel := context.WithCancel(auth.NewContext(context.Background(), auth.FromContext(ctx)))
+	j := &Job{
+		ID:        id,
+		URL:       u.String(),
+		Owner:     auth.Owner(ctx),
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+		u:         u,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		cancel()
+		return nil, ErrClosed
+	}
+	m.evict(now)
+	select {
+	case m.queue <- j:
+		m.jobs[id] = j
+		return j.snapshot(), nil
+	default:
+		cancel()
+		return nil, ErrQueueFull
+	}
+}
+
+// evict removes the jobs which have finished more than ttl before now, the caller must hold the lock of m.
+func (m *Manager) evict(now time.Time) {
+	for id, j := range m.jobs {
+		if j.finished() && now.Sub(j.UpdatedAt) > m.ttl {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Get returns a snapshot of the job with the given id.
+// It returns ErrNotFound when the job isn't visible to owner, see Job.Owner.
+func (m *Manager) Get(id, owner string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jobs[id]
+	if !ok || !j.visibleTo(owner) {
+		return nil, ErrNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// Cancel cancels the context of the job with the given id and returns a snapshot of it.
+// It returns ErrNotFound when the job isn't visible to owner, see Job.Owner.
+func (m *Manager) Cancel(id, owner string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok || !j.visibleTo(owner) {
+		return nil, ErrNotFound
+	}
+	if j.finished() {
+		return j.snapshot(), ErrFinished
+	}
+	j.cancel()
+	j.Status = StatusCanceled
+	j.UpdatedAt = time.Now()
+	return j.snapshot(), nil
+}
+
+// QueueLength returns the number of jobs which are waiting for a worker.
+func (m *Manager) QueueLength() int {
+	return len(m.queue)
+}
+
+// Saturated reports whether the queue of the manager is full, so new jobs are rejected with ErrQueueFull.
+// A manager without a queue is never reported as saturated.
+func (m *Manager) Saturated() bool {
+	return cap(m.queue) > 0 && len(m.queue) >= cap(m.queue)
+}
+
+// process runs a job and stores the outcome of it.
+func (m *Manager) process(j *Job) {
+	logger := m.logger.With(zap.String("job_id", j.ID), zap.String("url", j.URL))
+	if j.ctx.Err() != nil {
+		m.transit(j, func() { j.Status = StatusCanceled })
+	}
+	if !m.transit(j, func() { j.Status = StatusRunning }) {
+		logger.Info("job skipped because it has been canceled")
+		return
+	}
+	logger.Info("job started")
+
+	progress := func(checked, total int) {
+		m.transit(j, func() { j.Progress = Progress{CheckedLinks: checked, TotalLinks: total} })
+	}
+	res, err := m.run(j.ctx, j.u, progress)
+
+	snapshot := m.finish(j, res, err, logger)
+	if m.onFinish != nil {
+		m.onFinish(snapshot)
+	}
+}
+
+// finish stores the outcome of running job j and returns a snapshot of it.
+func (m *Manager) finish(j *Job, res *htmlanalysis.Result, err error, logger *zap.Logger) *Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer j.cancel()
+	j.UpdatedAt = time.Now()
+	switch {
+	case j.ctx.Err() != nil:
+		j.Status = StatusCanceled
+		logger.Info("job canceled")
+	case err != nil:
+		j.Status = StatusFailed
+		j.Error = err.Error()
+		logger.With(zap.Error(err)).Error("job failed")
+	default:
+		j.Status = StatusDone
+		j.Result = res
+		logger.Info("job finished successfully")
+	}
+	return j.snapshot()
+}
+
+// transit applies the change f on job j if it hasn't been finished yet and reports whether f applied.
+func (m *Manager) transit(j *Job, f func()) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j.finished() {
+		return false
+	}
+	f()
+	j.UpdatedAt = time.Now()
+	return true
+}
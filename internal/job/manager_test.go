@@ -0,0 +1,250 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// waitForStatus polls the manager until the job gets the expected status or the timeout exceeds.
+func waitForStatus(t *testing.T, m *Manager, id string, expected Status) *Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		j, err := m.Get(id, "")
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		if j.Status == expected {
+			return j
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s didn't reach status %s", id, expected)
+	return nil
+}
+
+func TestManager_SubmitDone(t *testing.T) {
+	expectedResult := &htmlanalysis.Result{PageTitle: "Detective"}
+	m := NewManager(1, 1, func(_ context.Context, _ *url.URL, p htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		p(0, 2)
+		p(1, 2)
+		p(2, 2)
+		return expectedResult, nil
+	}, zap.NewNop())
+	m.Start()
+	defer m.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	j, err := m.Submit(context.Background(), u)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, StatusQueued, j.Status)
+	assert.Equal(t, "http://example.com", j.URL)
+
+	j = waitForStatus(t, m, j.ID, StatusDone)
+	assert.Equal(t, expectedResult, j.Result)
+	assert.Equal(t, Progress{CheckedLinks: 2, TotalLinks: 2}, j.Progress)
+	assert.Empty(t, j.Error)
+}
+
+func TestManager_SubmitFailed(t *testing.T) {
+	m := NewManager(1, 1, func(_ context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		return nil, errors.New("could not retrieve html body of url")
+	}, zap.NewNop())
+	m.Start()
+	defer m.Stop()
+
+	j, err := m.Submit(context.Background(), &url.URL{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	j = waitForStatus(t, m, j.ID, StatusFailed)
+	assert.Nil(t, j.Result)
+	assert.Equal(t, "could not retrieve html body of url", j.Error)
+}
+
+func TestManager_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	m := NewManager(1, 1, func(ctx context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		close(started)
+		<-ctx.Done()
+		return &htmlanalysis.Result{}, nil
+	}, zap.NewNop())
+	m.Start()
+	defer m.Stop()
+
+	j, err := m.Submit(context.Background(), &url.URL{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	<-started
+
+	j, err = m.Cancel(j.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCanceled, j.Status)
+
+	j = waitForStatus(t, m, j.ID, StatusCanceled)
+	assert.Nil(t, j.Result)
+
+	_, err = m.Cancel(j.ID, "")
+	assert.True(t, errors.Is(err, ErrFinished))
+
+	_, err = m.Cancel("unknown", "")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestManager_QueueFull(t *testing.T) {
+	// Workers are not started so the queue never gets drained.
+	m := NewManager(1, 1, nil, zap.NewNop())
+	assert.False(t, m.Saturated())
+
+	_, err := m.Submit(context.Background(), &url.URL{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.QueueLength())
+	assert.True(t, m.Saturated())
+
+	_, err = m.Submit(context.Background(), &url.URL{})
+	assert.True(t, errors.Is(err, ErrQueueFull))
+}
+
+func TestManager_GetNotFound(t *testing.T) {
+	m := NewManager(1, 1, nil, zap.NewNop())
+	j, err := m.Get("unknown", "")
+	assert.Nil(t, j)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestManager_OnFinish(t *testing.T) {
+	finished := make(chan *Job, 1)
+	m := NewManager(1, 1, func(_ context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		return &htmlanalysis.Result{PageTitle: "Detective"}, nil
+	}, zap.NewNop())
+	m.OnFinish(func(j *Job) { finished <- j })
+	m.Start()
+	defer m.Stop()
+
+	j, err := m.Submit(context.Background(), &url.URL{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	select {
+	case fj := <-finished:
+		assert.Equal(t, j.ID, fj.ID)
+		assert.Equal(t, StatusDone, fj.Status)
+		assert.Equal(t, "Detective", fj.Result.PageTitle)
+	case <-time.After(5 * time.Second):
+		t.Fatal("finish func has not been called")
+	}
+}
+
+func TestManager_ShutdownDrains(t *testing.T) {
+	m := NewManager(1, 2, func(_ context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &htmlanalysis.Result{}, nil
+	}, zap.NewNop())
+	m.Start()
+
+	j1, _ := m.Submit(context.Background(), &url.URL{})
+	j2, _ := m.Submit(context.Background(), &url.URL{})
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	for _, id := range []string{j1.ID, j2.ID} {
+		j, err := m.Get(id, "")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusDone, j.Status)
+	}
+}
+
+func TestManager_ShutdownCancelsAfterDeadline(t *testing.T) {
+	m := NewManager(1, 1, func(ctx context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, zap.NewNop())
+	m.Start()
+
+	j, _ := m.Submit(context.Background(), &url.URL{})
+	waitForStatus(t, m, j.ID, StatusRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(m.Shutdown(ctx), context.DeadlineExceeded))
+
+	j, err := m.Get(j.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCanceled, j.Status)
+}
+
+func TestManager_SubmitAfterShutdown(t *testing.T) {
+	m := NewManager(1, 1, func(_ context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		return &htmlanalysis.Result{}, nil
+	}, zap.NewNop())
+	m.Start()
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	// The queue is closed only once and the later jobs are rejected instead of panicking.
+	assert.NotPanics(t, m.Stop)
+	j, err := m.Submit(context.Background(), &url.URL{})
+	assert.Nil(t, j)
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestManager_EvictsFinishedJobs(t *testing.T) {
+	m := NewManager(1, 2, func(_ context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		return &htmlanalysis.Result{}, nil
+	}, zap.NewNop())
+	m.SetTTL(10 * time.Millisecond)
+	m.Start()
+	defer m.Stop()
+
+	j1, _ := m.Submit(context.Background(), &url.URL{})
+	waitForStatus(t, m, j1.ID, StatusDone)
+	time.Sleep(20 * time.Millisecond)
+
+	// The expired job is evicted when the next job is submitted.
+	j2, err := m.Submit(context.Background(), &url.URL{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = m.Get(j1.ID, "")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = m.Get(j2.ID, "")
+	assert.NoError(t, err)
+}
+
+func TestManager_Owner(t *testing.T) {
+	owners := make(chan string, 1)
+	m := NewManager(1, 1, func(ctx context.Context, _ *url.URL, _ htmlanalysis.ProgressFunc) (*htmlanalysis.Result, error) {
+		owners <- auth.Owner(ctx)
+		return &htmlanalysis.Result{}, nil
+	}, zap.NewNop())
+	m.Start()
+	defer m.Stop()
+
+	ctx, cancel := context.WithCancel(auth.NewContext(context.Background(), &auth.Client{Name: "team-a"}))
+	j, err := m.Submit(ctx, &url.URL{})
+	// The job must not be canceled with the context which it's submitted with.
+	cancel()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "team-a", j.Owner)
+	assert.Equal(t, "team-a", <-owners)
+	waitForStatus(t, m, j.ID, StatusDone)
+
+	_, err = m.Get(j.ID, "team-b")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = m.Cancel(j.ID, "team-b")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = m.Get(j.ID, "team-a")
+	assert.NoError(t, err)
+}
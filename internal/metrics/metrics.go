@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace is the prefix of all the metrics of the application.
+const namespace = "detective"
+
+// unmatchedRoute is the route label of the requests which don't match any route.
+const unmatchedRoute = "unmatched"
+
+// List of the outcomes of page fetches.
+const (
+	FetchOutcomeSuccess = "success"
+	FetchOutcomeFailure = "failure"
+)
+
+// Metrics holds the prometheus collectors of the application on a dedicated registry.
+// A nil Metrics is valid and doesn't record anything.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	fetchDuration   *prometheus.HistogramVec
+	fetchSize       prometheus.Histogram
+}
+
+// New creates a Metrics object and registers the process, go runtime and link check collectors in it.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of handled HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of handled HTTP requests by route, method and status code.",
+			Buckets:   []float64{.005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"route", "method", "status"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "page_fetch_duration_seconds",
+			Help:      "Duration of fetching the html documents of analyzed pages by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		fetchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "page_fetch_size_bytes",
+			Help:      "Size of the fetched html documents of analyzed pages.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+		}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		prometheus.NewGoCollector(),
+		m.requests,
+		m.requestDuration,
+		m.fetchDuration,
+		m.fetchSize,
+		linkCollector{},
+	)
+	return m
+}
+
+// RegisterRobotsChecker exposes the cache statistics of c.
+func (m *Metrics) RegisterRobotsChecker(c *robots.Checker) {
+	if m == nil || c == nil {
+		return
+	}
+	m.registry.MustRegister(robotsCollector{checker: c})
+}
+
+// Handler returns the http handler which exposes the metrics in the prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware returns a gin middleware which records the count and the latency of requests by their route.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.requests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObservePageFetch records the duration d of a page fetch and the size of its document when it succeeds.
+func (m *Metrics) ObservePageFetch(d time.Duration, size int, err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.fetchDuration.WithLabelValues(FetchOutcomeFailure).Observe(d.Seconds())
+		return
+	}
+	m.fetchDuration.WithLabelValues(FetchOutcomeSuccess).Observe(d.Seconds())
+	m.fetchSize.Observe(float64(size))
+}
+
+var (
+	linkProbesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "link_probes_total"),
+		"Number of checked links by outcome.",
+		[]string{"outcome"}, nil,
+	)
+	linkChecksInFlightDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "link_checks_in_flight"),
+		"Number of link checks which are running.",
+		nil, nil,
+	)
+	robotsCacheLookupsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "robots_cache_lookups_total"),
+		"Number of robots.txt cache lookups by result.",
+		[]string{"result"}, nil,
+	)
+	robotsCacheHitRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "robots_cache_hit_ratio"),
+		"Ratio of the robots.txt cache lookups which have been served from the cache.",
+		nil, nil,
+	)
+)
+
+// linkCollector exposes the package level link check statistics of htmlanalysis.
+type linkCollector struct{}
+
+// Describe sends the descriptors of the link metrics to ch.
+func (linkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- linkProbesDesc
+	ch <- linkChecksInFlightDesc
+}
+
+// Collect sends the current link metrics to ch.
+func (linkCollector) Collect(ch chan<- prometheus.Metric) {
+	s := htmlanalysis.GetLinkStats()
+	for _, o := range htmlanalysis.LinkOutcomes {
+		ch <- prometheus.MustNewConstMetric(linkProbesDesc, prometheus.CounterValue, float64(s.Probes[o]), string(o))
+	}
+	ch <- prometheus.MustNewConstMetric(linkChecksInFlightDesc, prometheus.GaugeValue, float64(s.InFlight))
+}
+
+// robotsCollector exposes the cache statistics of a robots.Checker.
+type robotsCollector struct {
+	checker *robots.Checker
+}
+
+// Describe sends the descriptors of the robots.txt cache metrics to ch.
+func (robotsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- robotsCacheLookupsDesc
+	ch <- robotsCacheHitRatioDesc
+}
+
+// Collect sends the current robots.txt cache metrics to ch.
+func (c robotsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.checker.CacheStats()
+	ch <- prometheus.MustNewConstMetric(robotsCacheLookupsDesc, prometheus.CounterValue, float64(s.Hits), "hit")
+	ch <- prometheus.MustNewConstMetric(robotsCacheLookupsDesc, prometheus.CounterValue, float64(s.Misses), "miss")
+
+	var ratio float64
+	if total := s.Hits + s.Misses; total > 0 {
+		ratio = float64(s.Hits) / float64(total)
+	}
+	ch <- prometheus.MustNewConstMetric(robotsCacheHitRatioDesc, prometheus.GaugeValue, ratio)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// scrape returns the metrics which are exposed by the handler of m.
+func scrape(m *Metrics) string {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	m.Handler().ServeHTTP(res, req)
+	return res.Body.String()
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/jobs/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, target := range []string{"/jobs/1", "/jobs/2", "/missing"} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	body := scrape(m)
+	assert.Contains(t, body, `detective_http_requests_total{method="GET",route="/jobs/:id",status="404"} 2`)
+	assert.Contains(t, body, `detective_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `detective_http_request_duration_seconds_count{method="GET",route="/jobs/:id",status="404"} 2`)
+}
+
+func TestMetrics_ObservePageFetch(t *testing.T) {
+	m := New()
+	m.ObservePageFetch(time.Second, 2048, nil)
+	m.ObservePageFetch(time.Second, 0, errors.New("could not get a response from url"))
+
+	body := scrape(m)
+	assert.Contains(t, body, `detective_page_fetch_duration_seconds_count{outcome="success"} 1`)
+	assert.Contains(t, body, `detective_page_fetch_duration_seconds_count{outcome="failure"} 1`)
+	assert.Contains(t, body, `detective_page_fetch_size_bytes_sum 2048`)
+	assert.Contains(t, body, `detective_link_probes_total{outcome="accessible"}`)
+	assert.Contains(t, body, `detective_link_checks_in_flight 0`)
+
+	var nilMetrics *Metrics
+	nilMetrics.ObservePageFetch(time.Second, 0, nil)
+}
+
+func TestMetrics_RegisterRobotsChecker(t *testing.T) {
+	m := New()
+	m.RegisterRobotsChecker(robots.NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop()))
+
+	body := scrape(m)
+	assert.Contains(t, body, `detective_robots_cache_lookups_total{result="hit"} 0`)
+	assert.Contains(t, body, `detective_robots_cache_hit_ratio 0`)
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+)
+
+// Status is the health of a monitored url based on its last run.
+type Status string
+
+// List of available monitor statuses.
+const (
+	StatusPending  Status = "pending"
+	StatusHealthy  Status = "healthy"
+	StatusDegraded Status = "degraded"
+)
+
+// maxRuns is the number of the latest runs which are kept for each monitor.
+const maxRuns = 20
+
+// Run is a single analysis of a monitored url.
+// AnalysisID is the id of the stored analysis in the history, it's empty when the history is disabled.
+// Diff is the difference between the result of the run and the result of the last healthy run.
+type Run struct {
+	AnalysisID string                   `json:"analysis_id,omitempty"`
+	StartedAt  time.Time                `json:"started_at"`
+	FinishedAt time.Time                `json:"finished_at"`
+	Error      string                   `json:"error"`
+	Degraded   bool                     `json:"degraded"`
+	Reasons    []string                 `json:"reasons"`
+	Diff       *htmlanalysis.ResultDiff `json:"diff"`
+}
+
+// Monitor is a url which is analyzed periodically by a Scheduler.
+// Schedule is either an interval like `15m` or a cron expression like `*/15 * * * *`.
+// Reasons explains why the monitor is degraded and Runs holds the latest runs, the newest first.
+// Owner is the name of the API client which created the monitor and owns its runs, it's empty when the monitor is
+// created without authentication.
+type Monitor struct {
+	ID        string     `json:"id"`
+	URL       string     `json:"url"`
+	Owner     string     `json:"owner,omitempty"`
+	Schedule  string     `json:"schedule"`
+	Status    Status     `json:"status"`
+	Reasons   []string   `json:"reasons"`
+	NextRunAt *time.Time `json:"next_run_at"`
+	Runs      []*Run     `json:"runs"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	u        *url.URL
+	schedule Schedule
+	// lastResult is the result of the last healthy run and baselineID is the id of its stored analysis.
+	lastResult *htmlanalysis.Result
+	baselineID string
+	// latestResult is the result of the latest successful run and latestID is the id of its stored analysis.
+	latestResult *htmlanalysis.Result
+	latestID     string
+	cancel       context.CancelFunc
+}
+
+// RunFunc is a type of function which analyzes a url and stores the result in the analysis history.
+type RunFunc func(ctx context.Context, u *url.URL) (*history.Record, error)
+
+// snapshot returns a copy of the exported fields of the monitor which is safe to be used by other go routines.
+func (m *Monitor) snapshot() *Monitor {
+	s := &Monitor{
+		ID:        m.ID,
+		URL:       m.URL,
+		Owner:     m.Owner,
+		Schedule:  m.Schedule,
+		Status:    m.Status,
+		Reasons:   append([]string{}, m.Reasons...),
+		Runs:      append([]*Run{}, m.Runs...),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+	if m.NextRunAt != nil {
+		next := *m.NextRunAt
+		s.NextRunAt = &next
+	}
+	return s
+}
+
+// visibleTo reports whether the monitor can be seen by the client named owner, the monitors without an owner are
+// visible to all the clients and an empty owner, which means authentication is disabled, sees all the monitors.
+func (m *Monitor) visibleTo(owner string) bool {
+	return owner == "" || m.Owner == "" || m.Owner == owner
+}
+
+// definition returns the definition of the monitor which is persisted in the store of the scheduler.
+func (m *Monitor) definition() *history.MonitorDefinition {
+	return &history.MonitorDefinition{
+		ID:         m.ID,
+		URL:        m.URL,
+		Owner:      m.Owner,
+		Schedule:   m.Schedule,
+		BaselineID: m.baselineID,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// record adds run r to the latest runs of the monitor and updates its status.
+func (m *Monitor) record(r *Run) {
+	m.Runs = append([]*Run{r}, m.Runs...)
+	if len(m.Runs) > maxRuns {
+		m.Runs = m.Runs[:maxRuns]
+	}
+	m.Status = StatusHealthy
+	m.Reasons = []string{}
+	if r.Degraded {
+		m.Status = StatusDegraded
+		m.Reasons = r.Reasons
+	}
+	m.UpdatedAt = r.FinishedAt
+}
+
+// degradationReasons returns the regressions of d, a run is degraded when its inaccessible links increase
+// or the key fields of the page change.
+func degradationReasons(d *htmlanalysis.ResultDiff) []string {
+	reasons := []string{}
+	if d.InaccessibleLinksDelta > 0 {
+		reasons = append(reasons, fmt.Sprintf("inaccessible links increased by %d", d.InaccessibleLinksDelta))
+	}
+	if len(d.NewlyBrokenLinks) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d links are newly broken", len(d.NewlyBrokenLinks)))
+	}
+	if d.PageTitle != nil {
+		reasons = append(reasons, "page title changed")
+	}
+	if d.HTMLVersion != nil {
+		reasons = append(reasons, "html version changed")
+	}
+	if d.HeadingsDelta.H1 != 0 {
+		reasons = append(reasons, "h1 headings count changed")
+	}
+	if d.LoginFormAppeared {
+		reasons = append(reasons, "login form appeared")
+	}
+	if d.LoginFormDisappeared {
+		reasons = append(reasons, "login form disappeared")
+	}
+	return reasons
+}
+
+// newID generates a random hex string which is used as identifier of monitors.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
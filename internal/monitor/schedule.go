@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidSchedule is returned when the schedule of a monitor can not be parsed or is too frequent.
+var ErrInvalidSchedule = errors.New("schedule is not valid")
+
+// Schedule decides when the next run of a monitor happens.
+type Schedule interface {
+	// Next returns the time of the first run after t.
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule is a Schedule which runs every interval.
+type intervalSchedule time.Duration
+
+// Next returns t plus the interval.
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// ParseSchedule parses spec which is either a duration like `15m` or a standard five fields cron expression
+// like `*/15 * * * *`. Intervals which are shorter than minInterval are rejected.
+func ParseSchedule(spec string, minInterval time.Duration) (Schedule, error) {
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d < minInterval || d <= 0 {
+			return nil, fmt.Errorf("%w: interval must not be shorter than %s", ErrInvalidSchedule, minInterval)
+		}
+		return intervalSchedule(d), nil
+	}
+
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2021, 7, 1, 10, 7, 30, 0, time.UTC)
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "15m", expected: now.Add(15 * time.Minute)},
+		{spec: "2h", expected: now.Add(2 * time.Hour)},
+		{spec: "*/15 * * * *", expected: time.Date(2021, 7, 1, 10, 15, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", expected: time.Date(2021, 7, 2, 3, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", expected: time.Date(2021, 7, 1, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec, time.Minute)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.expected, s.Next(now))
+		})
+	}
+}
+
+func TestParseScheduleFailures(t *testing.T) {
+	for _, spec := range []string{"", "30s", "-5m", "every minute", "* * *"} {
+		t.Run(spec, func(t *testing.T) {
+			s, err := ParseSchedule(spec, time.Minute)
+			assert.Nil(t, s)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned when there is no monitor with the requested id.
+var ErrNotFound = errors.New("monitor not found")
+
+// DegradedFunc is a type of function which is called with a snapshot of a monitor when a run of it is degraded.
+type DegradedFunc func(m *Monitor)
+
+// Store persists the definitions of monitors and returns the stored analyses which the monitors are compared with.
+type Store interface {
+	Get(id string) (*history.Record, error)
+	SaveMonitor(d *history.MonitorDefinition) error
+	DeleteMonitor(id string) error
+	ListMonitors() ([]*history.MonitorDefinition, error)
+}
+
+// Scheduler keeps the monitors in memory and runs each of them on its schedule, the monitors are persisted in its
+// store when it has one.
+type Scheduler struct {
+	run         RunFunc
+	minInterval time.Duration
+	logger      *zap.Logger
+	sem         chan struct{}
+	onDegraded  DegradedFunc
+	store       Store
+
+	mu       sync.RWMutex
+	monitors map[string]*Monitor
+	ctx      context.Context
+	cancel   context.CancelFunc
+	started  bool
+	wg       sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler which runs at most concurrency monitors at the same time and rejects
+// the interval schedules which are shorter than minInterval.
+func NewScheduler(concurrency int, minInterval time.Duration, run RunFunc, logger *zap.Logger) *Scheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		run:         run,
+		minInterval: minInterval,
+		logger:      logger,
+		sem:         make(chan struct{}, concurrency),
+		monitors:    map[string]*Monitor{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// OnDegraded sets f to be called when a run of a monitor is degraded, it must be called before Start.
+func (s *Scheduler) OnDegraded(f DegradedFunc) {
+	s.onDegraded = f
+}
+
+// Load restores the monitors of store and persists the changes of the monitors in it from now on, it must be called
+// before Start. The stored monitors whose schedule is not accepted anymore are skipped.
+func (s *Scheduler) Load(store Store) error {
+	definitions, err := store.ListMonitors()
+	if err != nil {
+		return fmt.Errorf("error while listing stored monitors: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+	for _, d := range definitions {
+		logger := s.logger.With(zap.String("monitor_id", d.ID), zap.String("url", d.URL))
+		u, err := url.Parse(d.URL)
+		if err != nil {
+			logger.With(zap.Error(err)).Warn("stored monitor is skipped because of its url")
+			continue
+		}
+		schedule, err := ParseSchedule(d.Schedule, s.minInterval)
+		if err != nil {
+			logger.With(zap.Error(err)).Warn("stored monitor is skipped because of its schedule")
+			continue
+		}
+
+		m := &Monitor{
+			ID:        d.ID,
+			URL:       d.URL,
+			Owner:     d.Owner,
+			Schedule:  d.Schedule,
+			Status:    StatusPending,
+			Reasons:   []string{},
+			Runs:      []*Run{},
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+			u:         u,
+			schedule:  schedule,
+		}
+		if d.BaselineID != "" {
+			rec, err := store.Get(d.BaselineID)
+			if err != nil {
+				logger.With(zap.Error(err)).Warn("baseline of stored monitor is not available")
+			} else {
+				m.lastResult, m.latestResult = rec.Result, rec.Result
+				m.baselineID, m.latestID = rec.ID, rec.ID
+			}
+		}
+		s.monitors[m.ID] = m
+	}
+	return nil
+}
+
+// Start launches the schedules of the monitors, the monitors which are created after Start are launched
+// immediately.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+	for _, m := range s.monitors {
+		s.launch(m)
+	}
+}
+
+// Stop cancels the running analyses and waits for the schedules to exit.
+// The scheduler must not be used after calling Stop.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.started = false
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Create registers a monitor which analyzes u on the schedule of spec and returns a snapshot of it.
+// The authenticated client of ctx owns the monitor and the analyses of its runs.
+func (s *Scheduler) Create(ctx context.Context, u *url.URL, spec string) (*Monitor, error) {
+	schedule, err := ParseSchedule(spec, s.minInterval)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("error while generating monitor id: %w", err)
+	}
+
+	now := time.Now()
+	m := &Monitor{
+		ID:        id,
+		URL:       u.String(),
+		Owner:     auth.Owner(ctx),
+		Schedule:  spec,
+		Status:    StatusPending,
+		Reasons:   []string{},
+		Runs:      []*Run{},
+		CreatedAt: now,
+		UpdatedAt: now,
+		u:         u,
+		schedule:  schedule,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.save(m.definition()); err != nil {
+		return nil, err
+	}
+	s.monitors[id] = m
+	if s.started {
+		s.launch(m)
+	}
+	return m.snapshot(), nil
+}
+
+// Get returns a snapshot of the monitor with the given id.
+// It returns ErrNotFound when the monitor isn't visible to owner, see Monitor.Owner.
+func (s *Scheduler) Get(id, owner string) (*Monitor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.monitors[id]
+	if !ok || !m.visibleTo(owner) {
+		return nil, ErrNotFound
+	}
+	return m.snapshot(), nil
+}
+
+// List returns snapshots of the monitors which are visible to owner, the oldest first.
+func (s *Scheduler) List(owner string) []*Monitor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	monitors := make([]*Monitor, 0, len(s.monitors))
+	for _, m := range s.monitors {
+		if m.visibleTo(owner) {
+			monitors = append(monitors, m.snapshot())
+		}
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].CreatedAt.Before(monitors[j].CreatedAt) })
+	return monitors
+}
+
+// Update changes the url and the schedule of the monitor with the given id and reschedules it.
+// The status of the monitor is reset when its url changes and ErrNotFound is returned when the monitor isn't visible
+// to owner.
+func (s *Scheduler) Update(id, owner string, u *url.URL, spec string) (*Monitor, error) {
+	schedule, err := ParseSchedule(spec, s.minInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok || !m.visibleTo(owner) {
+		return nil, ErrNotFound
+	}
+	d := m.definition()
+	if d.URL != u.String() {
+		d.BaselineID = ""
+	}
+	d.URL = u.String()
+	d.Schedule = spec
+	d.UpdatedAt = time.Now()
+	if err := s.save(d); err != nil {
+		return nil, err
+	}
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	if m.URL != d.URL {
+		m.Status = StatusPending
+		m.Reasons = []string{}
+		m.lastResult, m.latestResult = nil, nil
+		m.baselineID, m.latestID = "", ""
+	}
+	m.URL = d.URL
+	m.u = u
+	m.Schedule = spec
+	m.schedule = schedule
+	m.UpdatedAt = d.UpdatedAt
+	if s.started {
+		s.launch(m)
+	}
+	return m.snapshot(), nil
+}
+
+// ResetBaseline makes the latest successful run of the monitor with the given id the run which the next runs are
+// compared with, so an intended change of the page stops degrading the monitor. The monitor becomes healthy unless
+// its latest run failed and it's not changed when it has no successful run. It returns ErrNotFound when the
+// monitor isn't visible to owner.
+func (s *Scheduler) ResetBaseline(id, owner string) (*Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok || !m.visibleTo(owner) {
+		return nil, ErrNotFound
+	}
+	if m.latestResult == nil {
+		return m.snapshot(), nil
+	}
+	d := m.definition()
+	d.BaselineID = m.latestID
+	d.UpdatedAt = time.Now()
+	if err := s.save(d); err != nil {
+		return nil, err
+	}
+
+	m.lastResult = m.latestResult
+	m.baselineID = m.latestID
+	if len(m.Runs) > 0 && m.Runs[0].Error == "" {
+		m.Status = StatusHealthy
+		m.Reasons = []string{}
+	}
+	m.UpdatedAt = d.UpdatedAt
+	return m.snapshot(), nil
+}
+
+// Delete removes the monitor with the given id and stops its schedule.
+// It returns ErrNotFound when the monitor isn't visible to owner.
+func (s *Scheduler) Delete(id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.monitors[id]
+	if !ok || !m.visibleTo(owner) {
+		return ErrNotFound
+	}
+	if s.store != nil {
+		if err := s.store.DeleteMonitor(id); err != nil {
+			return fmt.Errorf("error while deleting stored monitor: %w", err)
+		}
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	delete(s.monitors, id)
+	return nil
+}
+
+// save persists d in the store of the scheduler if it has one, s.mu must be held by the caller.
+func (s *Scheduler) save(d *history.MonitorDefinition) error {
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.SaveMonitor(d); err != nil {
+		return fmt.Errorf("error while storing monitor: %w", err)
+	}
+	return nil
+}
+
+// launch starts the schedule of m in a new go routine, s.mu must be held by the caller.
+func (s *Scheduler) launch(m *Monitor) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	m.cancel = cancel
+	schedule, u := m.schedule, m.u
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			next := schedule.Next(time.Now())
+			s.mu.Lock()
+			// A canceled schedule must not overwrite the next run of its replacement.
+			if ctx.Err() == nil {
+				m.NextRunAt = &next
+			}
+			s.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			select {
+			case s.sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			s.execute(ctx, m, u)
+			<-s.sem
+		}
+	}()
+}
+
+// execute runs a single analysis of u for monitor m and records its outcome.
+func (s *Scheduler) execute(ctx context.Context, m *Monitor, u *url.URL) {
+	logger := s.logger.With(zap.String("monitor_id", m.ID), zap.String("url", u.String()))
+	logger.Info("monitor run started")
+
+	// The analyses of the runs are owned by the owner of the monitor.
+	runCtx := ctx
+	if m.Owner != "" {
+		runCtx = auth.NewContext(ctx, &auth.Client{Name: m.Owner})
+	}
+	r := &Run{StartedAt: time.Now(), Reasons: []string{}}
+	rec, err := s.run(runCtx, u)
+	r.FinishedAt = time.Now()
+
+	s.mu.Lock()
+	// ctx is canceled while s.mu is held, so it's checked after locking to not record the runs of deleted monitors.
+	if ctx.Err() != nil {
+		s.mu.Unlock()
+		// The monitor has been updated, deleted or the scheduler has been stopped during the run.
+		logger.Info("monitor run canceled")
+		return
+	}
+	if err != nil {
+		r.Error = err.Error()
+		r.Degraded = true
+		r.Reasons = append(r.Reasons, "analysis failed")
+		logger.With(zap.Error(err)).Error("monitor run failed")
+	} else {
+		r.AnalysisID = rec.ID
+		m.latestResult, m.latestID = rec.Result, rec.ID
+		if m.lastResult != nil {
+			r.Diff = htmlanalysis.Diff(m.lastResult, rec.Result)
+			r.Reasons = degradationReasons(r.Diff)
+			r.Degraded = len(r.Reasons) > 0
+		}
+		// Only the healthy runs become the baseline of the next runs, so a regression is reported until it's fixed.
+		if !r.Degraded {
+			m.lastResult = rec.Result
+			if rec.ID != "" && rec.ID != m.baselineID {
+				m.baselineID = rec.ID
+				if err := s.save(m.definition()); err != nil {
+					logger.With(zap.Error(err)).Error("could not store the baseline of monitor")
+				}
+			}
+		}
+		logger.With(zap.Bool("degraded", r.Degraded)).Info("monitor run finished")
+	}
+	m.record(r)
+	snapshot := m.snapshot()
+	s.mu.Unlock()
+
+	if r.Degraded && s.onDegraded != nil {
+		s.onDegraded(snapshot)
+	}
+}
@@ -0,0 +1,277 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mammadmodi/detective/internal/auth"
+	"github.com/mammadmodi/detective/internal/history"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// sequenceRunFunc returns a RunFunc which returns the given results in order and repeats the last one.
+// A nil result makes the run fail.
+func sequenceRunFunc(results ...*htmlanalysis.Result) RunFunc {
+	var mu sync.Mutex
+	i := 0
+	return func(_ context.Context, u *url.URL) (*history.Record, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		res := results[i]
+		if i < len(results)-1 {
+			i++
+		}
+		if res == nil {
+			return nil, errors.New("could not retrieve html body of url")
+		}
+		return &history.Record{ID: "analysis", URL: u.String(), Result: res}, nil
+	}
+}
+
+// waitForRuns polls the scheduler until the monitor has at least n runs or the timeout exceeds.
+func waitForRuns(t *testing.T, s *Scheduler, id string, n int) *Monitor {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		m, err := s.Get(id, "")
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		if len(m.Runs) >= n {
+			return m
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("monitor %s didn't reach %d runs", id, n)
+	return nil
+}
+
+func TestScheduler_Degraded(t *testing.T) {
+	healthy := &htmlanalysis.Result{PageTitle: "Detective", HeadingsCount: &htmlanalysis.HeadingsCount{H1: 1}}
+	broken := &htmlanalysis.Result{
+		PageTitle:              "Detective",
+		HeadingsCount:          &htmlanalysis.HeadingsCount{H1: 1},
+		InaccessibleLinksCount: 1,
+		InaccessibleLinks:      []string{"http://example.com/broken"},
+	}
+	s := NewScheduler(1, 0, sequenceRunFunc(healthy, healthy, broken, nil, healthy), zap.NewNop())
+	degraded := make(chan *Monitor, 10)
+	s.OnDegraded(func(m *Monitor) { degraded <- m })
+	s.Start()
+	defer s.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	m, err := s.Create(context.Background(), u, "10ms")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, StatusPending, m.Status)
+
+	m = waitForRuns(t, s, m.ID, 2)
+	assert.Equal(t, StatusHealthy, m.Status)
+	assert.False(t, m.Runs[0].Degraded)
+	assert.NotNil(t, m.Runs[0].Diff)
+	assert.Nil(t, m.Runs[1].Diff)
+	assert.Equal(t, "analysis", m.Runs[0].AnalysisID)
+
+	m = waitForRuns(t, s, m.ID, 3)
+	assert.True(t, m.Runs[0].Degraded)
+	assert.Equal(t, []string{"inaccessible links increased by 1", "1 links are newly broken"}, m.Runs[0].Reasons)
+
+	m = waitForRuns(t, s, m.ID, 4)
+	assert.True(t, m.Runs[0].Degraded)
+	assert.Equal(t, "could not retrieve html body of url", m.Runs[0].Error)
+	for i := 0; i < 2; i++ {
+		select {
+		case dm := <-degraded:
+			assert.Equal(t, StatusDegraded, dm.Status)
+		case <-time.After(5 * time.Second):
+			t.Fatal("degraded func has not been called")
+		}
+	}
+
+	// The degraded and failed runs don't replace the result which the next run is compared with.
+	m = waitForRuns(t, s, m.ID, 5)
+	assert.False(t, m.Runs[0].Diff.Changed)
+	assert.Empty(t, m.Runs[0].Diff.NewlyFixedLinks)
+	assert.Equal(t, StatusHealthy, m.Status)
+	assert.Empty(t, m.Reasons)
+}
+
+func TestScheduler_DegradedUntilFixed(t *testing.T) {
+	healthy := &htmlanalysis.Result{PageTitle: "Detective"}
+	s := NewScheduler(1, 0, sequenceRunFunc(healthy, &htmlanalysis.Result{PageTitle: "Changed"}), zap.NewNop())
+	s.Start()
+	defer s.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	m, err := s.Create(context.Background(), u, "10ms")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The change is compared with the healthy run on every run, so the monitor stays degraded.
+	m = waitForRuns(t, s, m.ID, 3)
+	assert.Equal(t, StatusDegraded, m.Status)
+	assert.True(t, m.Runs[0].Degraded)
+	assert.True(t, m.Runs[1].Degraded)
+}
+
+func TestScheduler_ResetBaseline(t *testing.T) {
+	s := NewScheduler(1, 0, sequenceRunFunc(
+		&htmlanalysis.Result{PageTitle: "Detective"},
+		&htmlanalysis.Result{PageTitle: "Changed"},
+	), zap.NewNop())
+	s.Start()
+	defer s.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	m, err := s.Create(auth.NewContext(context.Background(), &auth.Client{Name: "team-a"}), u, "10ms")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = s.ResetBaseline(m.ID, "team-b")
+	assert.Equal(t, ErrNotFound, err)
+
+	m = waitForRuns(t, s, m.ID, 2)
+	assert.Equal(t, StatusDegraded, m.Status)
+
+	// The changed page becomes the baseline, so the next runs are healthy.
+	m, err = s.ResetBaseline(m.ID, "team-a")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, StatusHealthy, m.Status)
+	assert.Empty(t, m.Reasons)
+	m = waitForRuns(t, s, m.ID, len(m.Runs)+1)
+	assert.False(t, m.Runs[0].Degraded)
+	assert.False(t, m.Runs[0].Diff.Changed)
+}
+
+// newTestStore creates a history store on a temporary database file which is closed after the test.
+func newTestStore(t *testing.T) *history.BoltStore {
+	store, err := history.NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("error while opening history store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestScheduler_Load(t *testing.T) {
+	store := newTestStore(t)
+	baseline := &history.Record{URL: "http://example.com", Result: &htmlanalysis.Result{PageTitle: "Detective"}}
+	if !assert.NoError(t, store.Save(baseline)) {
+		return
+	}
+
+	s := NewScheduler(1, time.Minute, sequenceRunFunc(&htmlanalysis.Result{}), zap.NewNop())
+	if !assert.NoError(t, s.Load(store)) {
+		return
+	}
+	u, _ := url.Parse("http://example.com")
+	m1, err := s.Create(context.Background(), u, "1h")
+	assert.NoError(t, err)
+	m2, err := s.Create(context.Background(), u, "30m")
+	assert.NoError(t, err)
+	other, _ := url.Parse("http://example.org")
+	_, err = s.Update(m2.ID, "", other, "2h")
+	assert.NoError(t, err)
+	m3, err := s.Create(context.Background(), u, "15m")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Delete(m3.ID, ""))
+
+	// The baseline of a monitor is restored from the history and a schedule which isn't accepted anymore is skipped.
+	assert.NoError(t, store.SaveMonitor(&history.MonitorDefinition{
+		ID: m1.ID, URL: m1.URL, Schedule: m1.Schedule, BaselineID: baseline.ID, CreatedAt: m1.CreatedAt,
+	}))
+	assert.NoError(t, store.SaveMonitor(&history.MonitorDefinition{ID: "short", URL: u.String(), Schedule: "10s"}))
+
+	restored := NewScheduler(1, time.Minute, sequenceRunFunc(&htmlanalysis.Result{}), zap.NewNop())
+	if !assert.NoError(t, restored.Load(store)) {
+		return
+	}
+	monitors := restored.List("")
+	if assert.Len(t, monitors, 2) {
+		assert.Equal(t, m1.ID, monitors[0].ID)
+		assert.Equal(t, StatusPending, monitors[0].Status)
+		assert.Equal(t, m2.ID, monitors[1].ID)
+		assert.Equal(t, "http://example.org", monitors[1].URL)
+		assert.Equal(t, "2h", monitors[1].Schedule)
+	}
+	restored.mu.RLock()
+	assert.Equal(t, baseline.Result, restored.monitors[m1.ID].lastResult)
+	restored.mu.RUnlock()
+}
+
+func TestScheduler_CRUD(t *testing.T) {
+	s := NewScheduler(1, time.Minute, sequenceRunFunc(&htmlanalysis.Result{}), zap.NewNop())
+	s.Start()
+	defer s.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	_, err := s.Create(context.Background(), u, "10s")
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	m1, err := s.Create(context.Background(), u, "1h")
+	assert.NoError(t, err)
+	m2, err := s.Create(context.Background(), u, "*/5 * * * *")
+	assert.NoError(t, err)
+
+	monitors := s.List("")
+	if assert.Len(t, monitors, 2) {
+		assert.Equal(t, m1.ID, monitors[0].ID)
+		assert.Equal(t, m2.ID, monitors[1].ID)
+	}
+
+	other, _ := url.Parse("http://example.org")
+	m1, err = s.Update(m1.ID, "", other, "30m")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.org", m1.URL)
+	assert.Equal(t, "30m", m1.Schedule)
+	_, err = s.Update("missing", "", other, "30m")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, s.Delete(m2.ID, ""))
+	assert.ErrorIs(t, s.Delete(m2.ID, ""), ErrNotFound)
+	_, err = s.Get(m2.ID, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, s.List(""), 1)
+}
+
+func TestScheduler_Owner(t *testing.T) {
+	owners := make(chan string, 10)
+	s := NewScheduler(1, 0, func(ctx context.Context, u *url.URL) (*history.Record, error) {
+		owners <- auth.Owner(ctx)
+		return &history.Record{URL: u.String(), Result: &htmlanalysis.Result{}}, nil
+	}, zap.NewNop())
+	s.Start()
+	defer s.Stop()
+
+	u, _ := url.Parse("http://example.com")
+	ctx := auth.NewContext(context.Background(), &auth.Client{Name: "team-a"})
+	m, err := s.Create(ctx, u, "10ms")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "team-a", m.Owner)
+	// The runs of the monitor are owned by its owner.
+	assert.Equal(t, "team-a", <-owners)
+
+	// The monitor of team-a can't be seen or managed by team-b.
+	_, err = s.Get(m.ID, "team-b")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, s.List("team-b"))
+	_, err = s.Update(m.ID, "team-b", u, "1h")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.Delete(m.ID, "team-b"), ErrNotFound)
+
+	assert.Len(t, s.List("team-a"), 1)
+	assert.NoError(t, s.Delete(m.ID, "team-a"))
+}
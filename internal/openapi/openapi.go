@@ -0,0 +1,141 @@
+// Package openapi holds the OpenAPI document of the versioned REST API and validates requests against it.
+package openapi
+
+import (
+	"context"
+	_ "embed" // The OpenAPI document is embedded.
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// BasePath is the path prefix of the routes of the versioned REST API.
+const BasePath = "/api/v1"
+
+// ErrOperationNotFound is returned when a route is not described by the OpenAPI document.
+var ErrOperationNotFound = errors.New("operation is not described by the openapi document")
+
+//go:embed openapi.json
+var document []byte
+
+// Document returns the OpenAPI document of the versioned REST API in json.
+func Document() []byte {
+	return document
+}
+
+// ValidationError describes why a request doesn't match the OpenAPI document.
+// Field is the location of the invalid value like `body.url` or `query.limit`, it's empty when the request is not
+// valid as a whole.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+// Error returns the invalid field and the reason of e.
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// Validator validates the requests of the versioned REST API against the OpenAPI document.
+// It's safe for concurrent use.
+type Validator struct {
+	spec    *openapi3.T
+	options *openapi3filter.Options
+}
+
+// NewValidator loads the OpenAPI document and creates a Validator based on it.
+func NewValidator() (*Validator, error) {
+	spec, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading openapi document: %w", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("openapi document is not valid: %w", err)
+	}
+
+	return &Validator{
+		spec: spec,
+		// Authentication is enforced by the API key middleware, so the security requirements are not checked here.
+		options: &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}, nil
+}
+
+// Validate validates the parameters and the body of r against the operation of route, route is a path template of
+// gin like `/api/v1/jobs/:id` and params holds the values of its parameters.
+// The body of r is replaced, so it can be read again after the validation.
+// It returns a *ValidationError if r is not valid and ErrOperationNotFound if the operation of route is not described
+// by the document.
+func (v *Validator) Validate(r *http.Request, route string, params map[string]string) error {
+	path := templatePath(strings.TrimPrefix(route, BasePath))
+	pathItem := v.spec.Paths[path]
+	if pathItem == nil {
+		return ErrOperationNotFound
+	}
+	operation := pathItem.GetOperation(r.Method)
+	if operation == nil {
+		return ErrOperationNotFound
+	}
+
+	err := openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: params,
+		Route: &routers.Route{
+			Spec:      v.spec,
+			Path:      path,
+			PathItem:  pathItem,
+			Method:    r.Method,
+			Operation: operation,
+		},
+		Options: v.options,
+	})
+	if err != nil {
+		return newValidationError(err)
+	}
+	return nil
+}
+
+// newValidationError converts an error of openapi3filter to a ValidationError which points to the invalid field
+// instead of dumping the whole schema.
+func newValidationError(err error) *ValidationError {
+	var re *openapi3filter.RequestError
+	if !errors.As(err, &re) {
+		return &ValidationError{Reason: err.Error()}
+	}
+
+	ve := &ValidationError{Reason: re.Reason}
+	switch {
+	case re.Parameter != nil:
+		ve.Field = re.Parameter.In + "." + re.Parameter.Name
+	case re.RequestBody != nil:
+		ve.Field = "body"
+	}
+	var se *openapi3.SchemaError
+	if errors.As(re.Err, &se) {
+		if p := se.JSONPointer(); len(p) > 0 {
+			ve.Field += "." + strings.Join(p, ".")
+		}
+		ve.Reason = se.Reason
+	} else if re.Err != nil && ve.Reason == "" {
+		ve.Reason = re.Err.Error()
+	}
+	return ve
+}
+
+// templatePath converts the parameters of a gin path template like `/jobs/:id` to the OpenAPI form `/jobs/{id}`.
+func templatePath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
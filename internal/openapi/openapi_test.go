@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if !assert.NoError(t, json.Unmarshal(Document(), &doc)) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."))
+	assert.Contains(t, doc.Paths, "/analyze-url")
+}
+
+func TestValidator_Validate(t *testing.T) {
+	v, err := NewValidator()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		route         string
+		params        map[string]string
+		body          string
+		expectedField string
+	}{
+		{
+			name:   "valid body",
+			method: http.MethodPost,
+			target: "/api/v1/analyze-url",
+			route:  "/api/v1/analyze-url",
+			body:   `{"url": "https://example.com"}`,
+		},
+		{
+			name:          "missing body field",
+			method:        http.MethodPost,
+			target:        "/api/v1/analyze-url",
+			route:         "/api/v1/analyze-url",
+			body:          `{}`,
+			expectedField: "body.url",
+		},
+		{
+			name:          "wrong type of body field",
+			method:        http.MethodPost,
+			target:        "/api/v1/crawl",
+			route:         "/api/v1/crawl",
+			body:          `{"url": "https://example.com", "max_depth": "deep"}`,
+			expectedField: "body.max_depth",
+		},
+		{
+			name:          "nested body field",
+			method:        http.MethodPost,
+			target:        "/api/v1/diff",
+			route:         "/api/v1/diff",
+			body:          `{"before": {"url": 1}, "after": {}}`,
+			expectedField: "body.before.url",
+		},
+		{
+			name:          "malformed body",
+			method:        http.MethodPost,
+			target:        "/api/v1/jobs",
+			route:         "/api/v1/jobs",
+			body:          `{`,
+			expectedField: "body",
+		},
+		{
+			name:          "missing query parameter",
+			method:        http.MethodGet,
+			target:        "/api/v1/analyze-url/stream",
+			route:         "/api/v1/analyze-url/stream",
+			expectedField: "query.url",
+		},
+		{
+			name:          "invalid query parameter",
+			method:        http.MethodGet,
+			target:        "/api/v1/history?url=https://example.com&limit=0",
+			route:         "/api/v1/history",
+			expectedField: "query.limit",
+		},
+		{
+			name:   "path parameter",
+			method: http.MethodDelete,
+			target: "/api/v1/monitors/m1",
+			route:  "/api/v1/monitors/:id",
+			params: map[string]string{"id": "m1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := http.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			err := v.Validate(r, tc.route, tc.params)
+			if tc.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var ve *ValidationError
+			if assert.True(t, errors.As(err, &ve), "%v", err) {
+				assert.Equal(t, tc.expectedField, ve.Field)
+				assert.NotEmpty(t, ve.Reason)
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateKeepsBody(t *testing.T) {
+	v, _ := NewValidator()
+	r, _ := http.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url": "https://example.com"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if !assert.NoError(t, v.Validate(r, "/api/v1/jobs", nil)) {
+		return
+	}
+	b, _ := ioutil.ReadAll(r.Body)
+	assert.JSONEq(t, `{"url": "https://example.com"}`, string(b))
+}
+
+func TestValidator_ValidateUnknownOperation(t *testing.T) {
+	v, _ := NewValidator()
+	r, _ := http.NewRequest(http.MethodPatch, "/api/v1/jobs/j1", nil)
+	assert.True(t, errors.Is(v.Validate(r, "/api/v1/jobs/:id", nil), ErrOperationNotFound))
+	r, _ = http.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+	assert.True(t, errors.Is(v.Validate(r, "/api/v1/unknown", nil), ErrOperationNotFound))
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestNewExporter(t *testing.T) {
+	for _, name := range []string{ExporterNone, ""} {
+		e, err := NewExporter(context.Background(), name, "", false, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, e)
+	}
+
+	e, err := NewExporter(context.Background(), ExporterOTLP, "localhost:4318", true, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, e)
+
+	e, err = NewExporter(context.Background(), "jaeger", "", false, nil)
+	assert.Nil(t, e)
+	assert.Error(t, err)
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewExporter(context.Background(), ExporterStdout, "", false, &buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	tp := NewTracerProvider(e, "detective", "test", 1)
+	assert.Equal(t, tp, otel.GetTracerProvider())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	assert.NoError(t, tp.Shutdown(context.Background()))
+
+	assert.Contains(t, buf.String(), "test-span")
+	assert.Contains(t, buf.String(), "detective")
+}
@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventType is the kind of an event which is delivered to webhook targets.
+type EventType string
+
+// List of available event types.
+const (
+	EventJobCompleted    EventType = "job.completed"
+	EventMonitorDegraded EventType = "monitor.degraded"
+	EventBatchFinished   EventType = "batch.finished"
+)
+
+// List of the headers which are sent with each delivery.
+// SignatureHeader holds `sha256=` followed by the hex encoded HMAC-SHA256 of the body with the secret of the target.
+const (
+	EventHeader     = "X-Detective-Event"
+	DeliveryHeader  = "X-Detective-Delivery"
+	SignatureHeader = "X-Detective-Signature"
+)
+
+// Event is the json payload which is delivered to webhook targets.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Target is a url which receives the events.
+// Secret is the key of the HMAC signature of the payloads, and Events limits the types of delivered events,
+// an empty Events receives all the events.
+type Target struct {
+	URL    string
+	Secret string
+	Events []EventType
+}
+
+// accepts reports whether t receives events of type et.
+func (t Target) accepts(et EventType) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == et {
+			return true
+		}
+	}
+	return false
+}
+
+// Options holds the delivery policy of a Notifier.
+// A failed delivery is retried at most MaxRetries times, the n-th retry waits Backoff * 2^(n-1).
+type Options struct {
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// Notifier delivers events to the webhook targets in the background.
+// A nil Notifier is valid and drops all the events.
+type Notifier struct {
+	targets []Target
+	client  *http.Client
+	opts    Options
+	logger  *zap.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewNotifier creates a Notifier which delivers events to targets by client.
+func NewNotifier(targets []Target, client *http.Client, opts Options, logger *zap.Logger) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Notifier{
+		targets: targets,
+		client:  client,
+		opts:    opts,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Notify delivers an event of type et with data to the targets which accept it, it doesn't wait for the deliveries.
+func (n *Notifier) Notify(et EventType, data interface{}) {
+	if n == nil {
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		n.logger.With(zap.Error(err)).Error("error while generating event id")
+		return
+	}
+	e := &Event{ID: id, Type: et, CreatedAt: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(e)
+	if err != nil {
+		n.logger.With(zap.Error(err), zap.String("event", string(et))).Error("error while encoding event")
+		return
+	}
+
+	for _, t := range n.targets {
+		if !t.accepts(et) {
+			continue
+		}
+		t := t
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.deliver(t, e, body)
+		}()
+	}
+}
+
+// Stop waits for the deliveries and their retries to finish, the remaining deliveries are canceled when ctx
+// gets done. The notifier must not be used after calling Stop.
+func (n *Notifier) Stop(ctx context.Context) {
+	if n == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		n.logger.Warn("canceling the remaining deliveries")
+		n.cancel()
+		<-done
+	}
+	n.cancel()
+}
+
+// deliver posts body to target t and retries it with exponential backoff until it succeeds.
+func (n *Notifier) deliver(t Target, e *Event, body []byte) {
+	logger := n.logger.With(zap.String("event_id", e.ID), zap.String("event", string(e.Type)), zap.String("target", t.URL))
+	backoff := n.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := n.post(t, e, body)
+		if err == nil {
+			logger.With(zap.Int("attempt", attempt+1)).Info("event delivered successfully")
+			return
+		}
+		if attempt >= n.opts.MaxRetries {
+			logger.With(zap.Error(err)).Error("event delivery failed, retries exhausted")
+			return
+		}
+		logger.With(zap.Error(err), zap.Duration("backoff", backoff)).Warn("event delivery failed, retrying")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-n.ctx.Done():
+			timer.Stop()
+			logger.Warn("event delivery canceled")
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// post performs a single delivery of body to target t.
+func (n *Notifier) post(t Target, e *Event, body []byte) error {
+	req, err := http.NewRequestWithContext(n.ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error while creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(e.Type))
+	req.Header.Set(DeliveryHeader, e.ID)
+	req.Header.Set(SignatureHeader, Sign(t.Secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while performing webhook request: %w", err)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook target responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the value of the SignatureHeader of body for secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the valid SignatureHeader of body for secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// newID generates a random hex string which is used as identifier of events.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
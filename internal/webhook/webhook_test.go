@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// delivery is a request which is received by a testReceiver.
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// testReceiver is a webhook target which fails the first failures requests.
+type testReceiver struct {
+	*httptest.Server
+	failures int
+
+	mu         sync.Mutex
+	attempts   int
+	deliveries []delivery
+}
+
+func newTestReceiver(failures int) *testReceiver {
+	r := &testReceiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.attempts++
+		if r.attempts <= r.failures {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.deliveries = append(r.deliveries, delivery{header: req.Header, body: body})
+	}))
+	return r
+}
+
+func (r *testReceiver) received() (int, []delivery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts, append([]delivery{}, r.deliveries...)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	receiver := newTestReceiver(2)
+	defer receiver.Close()
+	filtered := newTestReceiver(0)
+	defer filtered.Close()
+
+	n := NewNotifier([]Target{
+		{URL: receiver.URL, Secret: "secret"},
+		{URL: filtered.URL, Secret: "secret", Events: []EventType{EventMonitorDegraded}},
+	}, http.DefaultClient, Options{MaxRetries: 3, Backoff: time.Millisecond}, zap.NewNop())
+	n.Notify(EventJobCompleted, map[string]string{"id": "job"})
+	n.Stop(context.Background())
+
+	attempts, deliveries := receiver.received()
+	assert.Equal(t, 3, attempts)
+	if !assert.Len(t, deliveries, 1) {
+		return
+	}
+	d := deliveries[0]
+	assert.Equal(t, "application/json", d.header.Get("Content-Type"))
+	assert.Equal(t, string(EventJobCompleted), d.header.Get(EventHeader))
+	assert.True(t, Verify("secret", d.body, d.header.Get(SignatureHeader)))
+	assert.False(t, Verify("other secret", d.body, d.header.Get(SignatureHeader)))
+
+	var e Event
+	assert.NoError(t, json.Unmarshal(d.body, &e))
+	assert.Equal(t, d.header.Get(DeliveryHeader), e.ID)
+	assert.Equal(t, EventJobCompleted, e.Type)
+	assert.Equal(t, map[string]interface{}{"id": "job"}, e.Data)
+
+	attempts, _ = filtered.received()
+	assert.Equal(t, 0, attempts)
+}
+
+func TestNotifier_NotifyRetriesExhausted(t *testing.T) {
+	receiver := newTestReceiver(10)
+	defer receiver.Close()
+
+	n := NewNotifier([]Target{{URL: receiver.URL}}, http.DefaultClient, Options{MaxRetries: 2, Backoff: time.Millisecond}, zap.NewNop())
+	n.Notify(EventBatchFinished, nil)
+	n.Stop(context.Background())
+
+	attempts, deliveries := receiver.received()
+	assert.Equal(t, 3, attempts)
+	assert.Empty(t, deliveries)
+}
+
+func TestNotifier_StopCancelsRetries(t *testing.T) {
+	receiver := newTestReceiver(10)
+	defer receiver.Close()
+
+	n := NewNotifier([]Target{{URL: receiver.URL}}, http.DefaultClient, Options{MaxRetries: 5, Backoff: time.Hour}, zap.NewNop())
+	n.Notify(EventBatchFinished, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n.Stop(ctx)
+
+	attempts, _ := receiver.received()
+	assert.Equal(t, 1, attempts)
+}
+
+func TestNotifier_Nil(t *testing.T) {
+	var n *Notifier
+	n.Notify(EventJobCompleted, nil)
+	n.Stop(context.Background())
+}
@@ -0,0 +1,270 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"go.uber.org/zap"
+)
+
+// FetchFunc is a type of function which retrieves the html document of a url and returns the url of the page after
+// following the redirects and the certificate health of its connection, which is nil for the http pages.
+type FetchFunc func(
+	ctx context.Context,
+	u *url.URL,
+) (finalURL *url.URL, htmlDoc string, tlsReport *htmlanalysis.TLSReport, err error)
+
+// AnalyzeFunc is a type of function which analyzes an html doc and returns a Result object.
+type AnalyzeFunc func(ctx context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error)
+
+// Options holds the limits and the scope rules of a crawl.
+// MaxDepth is the maximum number of hops from the seed url, 0 means only the seed page is analyzed.
+// MaxPages is the maximum number of pages which are analyzed.
+// Concurrency is the number of pages which are analyzed at the same time.
+// SameHost limits the crawl to the host of the seed url.
+// PathPrefix limits the crawl to the urls whose path starts with it.
+// Include limits the crawl to the urls which match at least one of its expressions if it's not empty.
+// Exclude skips the urls which match any of its expressions.
+type Options struct {
+	MaxDepth    int
+	MaxPages    int
+	Concurrency int
+	SameHost    bool
+	PathPrefix  string
+	Include     []*regexp.Regexp
+	Exclude     []*regexp.Regexp
+}
+
+// PageResult is the outcome of analyzing a single page of the site.
+// FinalURL is the url of the page after following redirects, the links of the page are resolved against it.
+// SkippedByRobots shows that the page has not been fetched because robots rules disallow it.
+type PageResult struct {
+	URL             string               `json:"url"`
+	FinalURL        string               `json:"final_url"`
+	Depth           int                  `json:"depth"`
+	Result          *htmlanalysis.Result `json:"result"`
+	Error           string               `json:"error"`
+	SkippedByRobots bool                 `json:"skipped_by_robots"`
+}
+
+// Summary is an aggregation of the results of all the crawled pages.
+type Summary struct {
+	PagesCrawled            int `json:"pages_crawled"`
+	PagesFailed             int `json:"pages_failed"`
+	PagesSkippedByRobots    int `json:"pages_skipped_by_robots"`
+	InternalLinksCount      int `json:"internal_links_count"`
+	ExternalLinksCount      int `json:"external_links_count"`
+	InaccessibleLinksCount  int `json:"inaccessible_links_count"`
+	RobotsSkippedLinksCount int `json:"robots_skipped_links_count"`
+	PagesWithLoginForm      int `json:"pages_with_login_form"`
+	PagesWithoutTitle       int `json:"pages_without_title"`
+}
+
+// Report is the outcome of a crawl.
+type Report struct {
+	SeedURL string        `json:"seed_url"`
+	Pages   []*PageResult `json:"pages"`
+	Summary *Summary      `json:"summary"`
+}
+
+// Crawler analyzes the pages of a site by following the internal links of the pages.
+type Crawler struct {
+	fetch   FetchFunc
+	analyze AnalyzeFunc
+	logger  *zap.Logger
+}
+
+// New creates a Crawler which retrieves pages by fetch and analyzes them by analyze.
+func New(fetch FetchFunc, analyze AnalyzeFunc, logger *zap.Logger) *Crawler {
+	return &Crawler{
+		fetch:   fetch,
+		analyze: analyze,
+		logger:  logger,
+	}
+}
+
+// link is a url which is waiting to be crawled.
+type link struct {
+	u     *url.URL
+	depth int
+}
+
+// Crawl analyzes the seed page and the pages which are reachable from it in breadth first order.
+func (c *Crawler) Crawl(ctx context.Context, seed *url.URL, opts Options) (*Report, error) {
+	if seed == nil || seed.Host == "" {
+		return nil, errors.New("seed url must be absolute")
+	}
+	if opts.MaxPages < 1 {
+		opts.MaxPages = 1
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	seed = Normalize(seed)
+	report := &Report{SeedURL: seed.String(), Pages: []*PageResult{}}
+	visited := map[string]bool{seed.String(): true}
+	frontier := []link{{u: seed}}
+	for len(frontier) > 0 && len(report.Pages) < opts.MaxPages && ctx.Err() == nil {
+		// Trim the frontier to the remained budget of pages.
+		if remained := opts.MaxPages - len(report.Pages); len(frontier) > remained {
+			frontier = frontier[:remained]
+		}
+
+		pages, discovered := c.crawlLevel(ctx, frontier, opts.Concurrency)
+		report.Pages = append(report.Pages, pages...)
+
+		var next []link
+		for i, links := range discovered {
+			depth := frontier[i].depth + 1
+			if depth > opts.MaxDepth {
+				continue
+			}
+			for _, u := range links {
+				u = Normalize(u)
+				if visited[u.String()] || !opts.inScope(seed, u) {
+					continue
+				}
+				visited[u.String()] = true
+				next = append(next, link{u: u, depth: depth})
+			}
+		}
+		frontier = next
+	}
+
+	report.Summary = summarize(report.Pages)
+	return report, ctx.Err()
+}
+
+// crawlLevel analyzes the pages of frontier concurrently and returns their results and the internal links
+// which are discovered in each of them in the same order as frontier.
+func (c *Crawler) crawlLevel(ctx context.Context, frontier []link, concurrency int) ([]*PageResult, [][]*url.URL) {
+	pages := make([]*PageResult, len(frontier))
+	discovered := make([][]*url.URL, len(frontier))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	wg.Add(len(frontier))
+	for i, l := range frontier {
+		i, l := i, l
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			pages[i], discovered[i] = c.crawlPage(ctx, l)
+		}()
+	}
+	wg.Wait()
+	return pages, discovered
+}
+
+// crawlPage fetches and analyzes a single page and returns its result and internal links.
+func (c *Crawler) crawlPage(ctx context.Context, l link) (*PageResult, []*url.URL) {
+	logger := c.logger.With(zap.String("url", l.u.String()), zap.Int("depth", l.depth))
+	page := &PageResult{URL: l.u.String(), Depth: l.depth}
+
+	finalURL, htmlDoc, tlsReport, err := c.fetch(ctx, l.u)
+	if errors.Is(err, robots.ErrDisallowed) {
+		logger.Info("page skipped because of robots rules")
+		page.Error = "url is disallowed by robots.txt"
+		page.SkippedByRobots = true
+		return page, nil
+	}
+	if err != nil {
+		logger.With(zap.Error(err)).Error("error while fetching page")
+		page.Error = "could not retrieve html body of url"
+		return page, nil
+	}
+	page.FinalURL = finalURL.String()
+
+	res, err := c.analyze(ctx, finalURL, htmlDoc)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("error while analyzing page")
+		page.Error = "error while parsing html"
+		return page, nil
+	}
+	res.TLS = tlsReport
+	page.Result = res
+	logger.Info("page crawled successfully")
+
+	return page, htmlanalysis.NewHTMLAnalyzer(htmlDoc, finalURL).InternalLinks()
+}
+
+// inScope reports whether u is allowed to be crawled by the scope rules of the options.
+func (o Options) inScope(seed, u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if o.SameHost && u.Host != seed.Host {
+		return false
+	}
+	if o.PathPrefix != "" && !strings.HasPrefix(u.Path, o.PathPrefix) {
+		return false
+	}
+	s := u.String()
+	for _, re := range o.Exclude {
+		if re.MatchString(s) {
+			return false
+		}
+	}
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, re := range o.Include {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns a copy of u without fragment and default port and with lower case scheme and host,
+// so the different forms of a url are deduplicated.
+func Normalize(u *url.URL) *url.URL {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if (n.Scheme == "http" && strings.HasSuffix(n.Host, ":80")) ||
+		(n.Scheme == "https" && strings.HasSuffix(n.Host, ":443")) {
+		n.Host = n.Host[:strings.LastIndex(n.Host, ":")]
+	}
+	if n.Path == "" {
+		n.Path = "/"
+	}
+	n.Fragment = ""
+	n.RawFragment = ""
+	return &n
+}
+
+// summarize aggregates the results of pages.
+func summarize(pages []*PageResult) *Summary {
+	s := &Summary{}
+	for _, p := range pages {
+		if p.SkippedByRobots {
+			s.PagesSkippedByRobots++
+			continue
+		}
+		if p.Result == nil {
+			s.PagesFailed++
+			continue
+		}
+		s.PagesCrawled++
+		if p.Result.LinksCount != nil {
+			s.InternalLinksCount += p.Result.LinksCount.Internal
+			s.ExternalLinksCount += p.Result.LinksCount.External
+		}
+		s.InaccessibleLinksCount += p.Result.InaccessibleLinksCount
+		s.RobotsSkippedLinksCount += p.Result.RobotsSkippedLinksCount
+		if p.Result.HasLoginForm {
+			s.PagesWithLoginForm++
+		}
+		if p.Result.PageTitle == "" || p.Result.PageTitle == htmlanalysis.EmptyPageTitle {
+			s.PagesWithoutTitle++
+		}
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// testSite is a map of page urls to their html documents.
+var testSite = map[string]string{
+	"http://example.com/": `<title>Home</title>
+<a href="/blog/">Blog</a><a href="/about">About</a><a href="/about#team">Team</a><a href="http://other.com/">Other</a>`,
+	"http://example.com/about":       `<title>About</title><a href="/">Home</a><form id="login"></form>`,
+	"http://example.com/blog/":       `<a href="/blog/post-1">Post 1</a><a href="/blog/post-2?draft=1">Post 2</a>`,
+	"http://example.com/blog/post-1": `<title>Post 1</title><a href="/blog/post-1/comments">Comments</a>`,
+}
+
+// testTLSReport returns the certificate health of the test pages, only the about page is served over https.
+func testTLSReport(u *url.URL) *htmlanalysis.TLSReport {
+	if u.Path != "/about" {
+		return nil
+	}
+	return &htmlanalysis.TLSReport{Version: "TLS 1.3"}
+}
+
+func newTestCrawler() *Crawler {
+	fetch := func(_ context.Context, u *url.URL) (*url.URL, string, *htmlanalysis.TLSReport, error) {
+		if u.Path == "/blog/post-1/comments" {
+			return nil, "", nil, robots.ErrDisallowed
+		}
+		htmlDoc, ok := testSite[u.String()]
+		if !ok {
+			return nil, "", nil, errors.New("not found")
+		}
+		return u, htmlDoc, testTLSReport(u), nil
+	}
+	analyze := func(ctx context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error) {
+		a := htmlanalysis.NewHTMLAnalyzer(htmlDoc, u)
+		return &htmlanalysis.Result{
+			PageTitle:    a.GetPageTitle(),
+			LinksCount:   a.GetLinksCount(),
+			HasLoginForm: a.HasLoginForm(),
+		}, nil
+	}
+	return New(fetch, analyze, zap.NewNop())
+}
+
+// crawledURLs returns the sorted urls of the pages of report.
+func crawledURLs(report *Report) []string {
+	var urls []string
+	for _, p := range report.Pages {
+		urls = append(urls, p.URL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	seed, _ := url.Parse("HTTP://Example.com:80")
+	testCases := []struct {
+		name         string
+		opts         Options
+		expectedURLs []string
+	}{
+		{
+			name:         "only seed page",
+			opts:         Options{MaxDepth: 0, MaxPages: 10, SameHost: true},
+			expectedURLs: []string{"http://example.com/"},
+		},
+		{
+			name: "first level",
+			opts: Options{MaxDepth: 1, MaxPages: 10, SameHost: true},
+			expectedURLs: []string{
+				"http://example.com/", "http://example.com/about", "http://example.com/blog/",
+			},
+		},
+		{
+			name: "whole site",
+			opts: Options{MaxDepth: 5, MaxPages: 10, Concurrency: 2, SameHost: true},
+			expectedURLs: []string{
+				"http://example.com/", "http://example.com/about", "http://example.com/blog/",
+				"http://example.com/blog/post-1", "http://example.com/blog/post-1/comments",
+				"http://example.com/blog/post-2?draft=1",
+			},
+		},
+		{
+			name:         "page budget",
+			opts:         Options{MaxDepth: 5, MaxPages: 2, SameHost: true},
+			expectedURLs: []string{"http://example.com/", "http://example.com/blog/"},
+		},
+		{
+			name: "path prefix and exclude rules",
+			opts: Options{
+				MaxDepth:   5,
+				MaxPages:   10,
+				SameHost:   true,
+				PathPrefix: "/blog/",
+				Exclude:    []*regexp.Regexp{regexp.MustCompile(`draft=`)},
+			},
+			expectedURLs: []string{
+				"http://example.com/", "http://example.com/blog/",
+				"http://example.com/blog/post-1", "http://example.com/blog/post-1/comments",
+			},
+		},
+		{
+			name: "include rules",
+			opts: Options{
+				MaxDepth: 5,
+				MaxPages: 10,
+				SameHost: true,
+				Include:  []*regexp.Regexp{regexp.MustCompile(`/about$`)},
+			},
+			expectedURLs: []string{"http://example.com/", "http://example.com/about"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := newTestCrawler().Crawl(context.Background(), seed, tc.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "http://example.com/", report.SeedURL)
+			assert.Equal(t, tc.expectedURLs, crawledURLs(report))
+		})
+	}
+}
+
+func TestCrawler_CrawlSummary(t *testing.T) {
+	seed, _ := url.Parse("http://example.com/")
+	report, err := newTestCrawler().Crawl(context.Background(), seed, Options{MaxDepth: 5, MaxPages: 10, SameHost: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Summary{
+		PagesCrawled:         4,
+		PagesFailed:          1,
+		PagesSkippedByRobots: 1,
+		InternalLinksCount:   7,
+		ExternalLinksCount:   1,
+		PagesWithLoginForm:   1,
+		PagesWithoutTitle:    1,
+	}, report.Summary)
+	for _, p := range report.Pages {
+		switch {
+		case p.SkippedByRobots:
+			assert.Equal(t, "url is disallowed by robots.txt", p.Error)
+		case p.Result == nil:
+			assert.Equal(t, "could not retrieve html body of url", p.Error)
+		default:
+			u, _ := url.Parse(p.URL)
+			assert.Equal(t, testTLSReport(u), p.Result.TLS, p.URL)
+		}
+	}
+}
+
+func TestCrawler_CrawlRedirect(t *testing.T) {
+	// The seed redirects to a directory whose relative links are resolved against it.
+	fetch := func(_ context.Context, u *url.URL) (*url.URL, string, *htmlanalysis.TLSReport, error) {
+		switch u.String() {
+		case "http://example.com/docs":
+			final, _ := url.Parse("http://example.com/docs/")
+			return final, `<a href="intro">Intro</a>`, nil, nil
+		case "http://example.com/docs/intro":
+			return u, `<title>Intro</title>`, nil, nil
+		default:
+			return nil, "", nil, errors.New("not found")
+		}
+	}
+	var analyzed []string
+	analyze := func(_ context.Context, u *url.URL, _ string) (*htmlanalysis.Result, error) {
+		analyzed = append(analyzed, u.String())
+		return &htmlanalysis.Result{}, nil
+	}
+
+	seed, _ := url.Parse("http://example.com/docs")
+	report, err := New(fetch, analyze, zap.NewNop()).Crawl(context.Background(), seed, Options{MaxDepth: 1, MaxPages: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"http://example.com/docs", "http://example.com/docs/intro"}, crawledURLs(report))
+	assert.Equal(t, "http://example.com/docs/", report.Pages[0].FinalURL)
+	assert.Equal(t, 0, report.Summary.PagesFailed)
+	assert.Equal(t, []string{"http://example.com/docs/", "http://example.com/docs/intro"}, analyzed)
+}
+
+func TestCrawler_CrawlInvalidSeed(t *testing.T) {
+	report, err := newTestCrawler().Crawl(context.Background(), &url.URL{Path: "/relative"}, Options{})
+	assert.Nil(t, report)
+	assert.Error(t, err)
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := map[string]string{
+		"HTTP://Example.COM":            "http://example.com/",
+		"https://example.com:443/a#b":   "https://example.com/a",
+		"http://example.com:8080/a?b=c": "http://example.com:8080/a?b=c",
+	}
+	for raw, expected := range testCases {
+		u, _ := url.Parse(raw)
+		assert.Equal(t, expected, Normalize(u).String())
+	}
+}
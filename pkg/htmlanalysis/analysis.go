@@ -5,13 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
-	"time"
+	"sync/atomic"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 	"golang.org/x/net/html"
 )
@@ -37,47 +38,49 @@ type LinksCount struct {
 // PageTitle is title of page in the title tag.
 // HeadingsCount is count of headings by their level.
 // InaccessibleLinksCount is count of links that doesn't return a 2xx status code
-// on a GET request.
+// on a GET request and InaccessibleLinks holds the sorted urls of these links.
+// RobotsSkippedLinksCount is count of links that are not requested because robots rules disallow them.
 // HasLoginForm shows that whether the html doc contains a login form or not.
+// CanonicalURL is the absolute url of the canonical link of the page if it has.
+// TLS is the certificate health of the page, it's only set for the https pages which are fetched by the analyzer's
+// caller.
 type Result struct {
-	HTMLVersion            string         `json:"html_version"`
-	PageTitle              string         `json:"page_title"`
-	HeadingsCount          *HeadingsCount `json:"headings_count"`
-	LinksCount             *LinksCount    `json:"links_count"`
-	InaccessibleLinksCount int            `json:"inaccessible_links_count"`
-	HasLoginForm           bool           `json:"has_login_form"`
+	HTMLVersion             string         `json:"html_version"`
+	PageTitle               string         `json:"page_title"`
+	HeadingsCount           *HeadingsCount `json:"headings_count"`
+	LinksCount              *LinksCount    `json:"links_count"`
+	InaccessibleLinksCount  int            `json:"inaccessible_links_count"`
+	InaccessibleLinks       []string       `json:"inaccessible_links"`
+	RobotsSkippedLinksCount int            `json:"robots_skipped_links_count"`
+	HasLoginForm            bool           `json:"has_login_form"`
+	CanonicalURL            string         `json:"canonical_url"`
+	TLS                     *TLSReport     `json:"tls,omitempty"`
 }
 
-var globalLogger = zap.NewNop()
+// EmptyPageTitle is the page title of the documents which have no title or an empty title tag.
+const EmptyPageTitle = "Empty Page Title"
 
-// SetGlobalLogger sets a logger for this package.
-func SetGlobalLogger(logger *zap.Logger) {
-	globalLogger = logger
-}
+// UnknownHTMLVersion is the html version of the documents which have no known doctype.
+const UnknownHTMLVersion = "Unknown HTML Version"
 
-// We should reduce the IdleConnTimeout because the requests that are being performed
-// by this HTTPClient target different hosts and there is no meaning to have idle connection
-// for a long time.
-var globalHTTPClient = &http.Client{
-	Timeout: 30 * time.Second,
-	Transport: &http.Transport{
-		IdleConnTimeout: 5 * time.Second,
-	},
-}
+// defaultAnalyzer is the Analyzer of Analyze.
+var defaultAnalyzer = NewAnalyzer()
 
-// SetGlobalHTTPClient sets an HTTPClient for this package which is used for analysis purposes.
-func SetGlobalHTTPClient(client *http.Client) {
-	globalHTTPClient = client
+// Analyze analyzes htmlDoc by an Analyzer with the default options, the analyses of the configured Analyzers
+// should be preferred in applications.
+func Analyze(ctx context.Context, hostURL *url.URL, htmlDocument string) (*Result, error) {
+	return defaultAnalyzer.Analyze(ctx, hostURL, htmlDocument)
 }
 
-// Analyze is a global wrapper function on HTMLAnalyzer.Analyze method.
-func Analyze(ctx context.Context, hostURL *url.URL, htmlDocument string) (*Result, error) {
-	return NewHTMLAnalyzer(htmlDocument, hostURL).Analyze(ctx)
+// NewHTMLAnalyzer creates a new HTMLAnalyzer object with opts, the limits of opts only apply to this HTMLAnalyzer.
+func NewHTMLAnalyzer(htmlDoc string, hostURL *url.URL, opts ...Option) *HTMLAnalyzer {
+	return newHTMLAnalyzer(htmlDoc, hostURL, newOptions(opts))
 }
 
-// NewHTMLAnalyzer creates a new HTMLAnalyzer object.
-func NewHTMLAnalyzer(htmlDoc string, hostURL *url.URL) *HTMLAnalyzer {
+// newHTMLAnalyzer creates a new HTMLAnalyzer object with o.
+func newHTMLAnalyzer(htmlDoc string, hostURL *url.URL, o *options) *HTMLAnalyzer {
 	return &HTMLAnalyzer{
+		opts:          o,
 		htmlDoc:       htmlDoc,
 		hostURL:       hostURL,
 		internalLinks: []*url.URL{},
@@ -86,32 +89,65 @@ func NewHTMLAnalyzer(htmlDoc string, hostURL *url.URL) *HTMLAnalyzer {
 }
 
 // HTMLAnalyzer is a struct which holds the states of result during the analysis.
+// An HTMLAnalyzer analyzes a single document and must not be used by different go routines at the same time.
 type HTMLAnalyzer struct {
+	opts           *options
 	htmlDoc        string
 	hostURL        *url.URL
 	result         *Result
 	internalLinks  []*url.URL
 	externalLinks  []*url.URL
 	linksAreParsed bool
+
+	inaccessibleLinks       []string
+	robotsSkippedLinksCount int
 }
 
 // Analyze starts an analysis on HTMLAnalyzer.htmlDoc field.
+// Each phase of the analysis is traced by a child span of the span of the analysis.
 func (h *HTMLAnalyzer) Analyze(ctx context.Context) (*Result, error) {
 	if h.result != nil {
 		return h.result, nil
 	}
 
-	if err := h.validate(); err != nil {
+	ctx, span := startSpan(ctx, "htmlanalysis.Analyze")
+	defer span.End()
+	if h.hostURL != nil {
+		span.SetAttributes(attribute.String("url", h.hostURL.String()))
+	}
+
+	var err error
+	tracePhase(ctx, string(PhaseParsed), func(context.Context) { err = h.validate() })
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "html document is not valid")
 		return nil, errors.New("html document is not valid")
 	}
 
+	emit := eventFuncFromContext(ctx)
+	emit(Event{Phase: PhaseParsed})
+
 	r := &Result{}
-	r.HTMLVersion = h.GetHTMLVersion()
-	r.PageTitle = h.GetPageTitle()
-	r.HeadingsCount = h.GetHeadingsCount()
-	r.LinksCount = h.GetLinksCount()
-	r.InaccessibleLinksCount = h.GetInaccessibleLinksCount(ctx)
-	r.HasLoginForm = h.HasLoginForm()
+	tracePhase(ctx, string(PhaseHTMLVersion), func(context.Context) { r.HTMLVersion = h.GetHTMLVersion() })
+	emit(Event{Phase: PhaseHTMLVersion, Data: r.HTMLVersion})
+	tracePhase(ctx, string(PhaseTitle), func(context.Context) { r.PageTitle = h.GetPageTitle() })
+	emit(Event{Phase: PhaseTitle, Data: r.PageTitle})
+	tracePhase(ctx, string(PhaseHeadings), func(context.Context) { r.HeadingsCount = h.GetHeadingsCount() })
+	emit(Event{Phase: PhaseHeadings, Data: r.HeadingsCount})
+	tracePhase(ctx, string(PhaseLinks), func(context.Context) { r.LinksCount = h.GetLinksCount() })
+	emit(Event{Phase: PhaseLinks, Data: r.LinksCount})
+	tracePhase(ctx, "inaccessible_links", func(ctx context.Context) {
+		r.InaccessibleLinksCount = h.GetInaccessibleLinksCount(ctx)
+		r.InaccessibleLinks = h.GetInaccessibleLinks()
+		r.RobotsSkippedLinksCount = h.GetRobotsSkippedLinksCount()
+	})
+	tracePhase(ctx, string(PhaseLoginForm), func(context.Context) { r.HasLoginForm = h.HasLoginForm() })
+	emit(Event{Phase: PhaseLoginForm, Data: r.HasLoginForm})
+	tracePhase(ctx, "canonical_url", func(context.Context) {
+		if u := h.GetCanonicalURL(); u != nil {
+			r.CanonicalURL = u.String()
+		}
+	})
 	h.result = r
 
 	return h.result, nil
@@ -138,8 +174,8 @@ func (h *HTMLAnalyzer) GetHTMLVersion() string {
 	for {
 		tt := tokenizer.Next()
 		if tt == html.ErrorToken {
-			globalLogger.Warn("html version didn't find")
-			return "Unknown HTML Version"
+			h.opts.logger.Warn("html version didn't find")
+			return UnknownHTMLVersion
 		}
 
 		if tt == html.DoctypeToken {
@@ -172,11 +208,11 @@ func (h *HTMLAnalyzer) GetHTMLVersion() string {
 // GetPageTitle parses html document and returns the page title.
 func (h *HTMLAnalyzer) GetPageTitle() string {
 	tokenizer := html.NewTokenizer(strings.NewReader(h.htmlDoc))
-	pageTitle := "Empty Page Title"
+	pageTitle := EmptyPageTitle
 	for {
 		tt := tokenizer.Next()
 		if tt == html.ErrorToken {
-			globalLogger.Warn("could not find page title")
+			h.opts.logger.Warn("could not find page title")
 			break
 		}
 
@@ -189,9 +225,9 @@ func (h *HTMLAnalyzer) GetPageTitle() string {
 					pageTitle = data
 					break
 				}
-				globalLogger.Warn("page title is empty")
+				h.opts.logger.Warn("page title is empty")
 			}
-			globalLogger.Warn("page title is empty")
+			h.opts.logger.Warn("page title is empty")
 			break
 		}
 	}
@@ -229,11 +265,49 @@ func (h *HTMLAnalyzer) GetHeadingsCount() *HeadingsCount {
 	return headings
 }
 
+// GetCanonicalURL parses html document and returns the absolute url of its canonical link,
+// it returns nil if the document has no canonical link.
+func (h *HTMLAnalyzer) GetCanonicalURL() *url.URL {
+	tokenizer := html.NewTokenizer(strings.NewReader(h.htmlDoc))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			return nil
+		}
+
+		t := tokenizer.Token()
+		if (tt != html.StartTagToken && tt != html.SelfClosingTagToken) || t.Data != "link" {
+			continue
+		}
+		var rel, href string
+		for _, attr := range t.Attr {
+			switch attr.Key {
+			case "rel":
+				rel = strings.ToLower(strings.TrimSpace(attr.Val))
+			case "href":
+				href = strings.TrimSpace(attr.Val)
+			}
+		}
+		if rel != "canonical" || href == "" {
+			continue
+		}
+		u, err := url.Parse(href)
+		if err != nil {
+			h.opts.logger.With(zap.String("href", href)).Warn("canonical url is not valid")
+			return nil
+		}
+		if h.hostURL != nil {
+			u = h.hostURL.ResolveReference(u)
+		}
+		return u
+	}
+}
+
 // GetLinksCount first sets all the available links in the state of HTMLAnalyzer
 // and then returns the LinksCount.
 func (h *HTMLAnalyzer) GetLinksCount() *LinksCount {
 	if !h.linksAreParsed {
-		globalLogger.Info("parsing links in html document")
+		h.opts.logger.Info("parsing links in html document")
 		h.parseAndSetLinks()
 	}
 	return &LinksCount{
@@ -260,34 +334,34 @@ func (h *HTMLAnalyzer) parseAndSetLinks() {
 				if attr.Key == "href" {
 					// Empty href.
 					if len(attr.Val) == 0 {
-						globalLogger.With(zap.String("href", attr.Val)).Debug("url ignored because it was empty")
+						h.opts.logger.With(zap.String("href", attr.Val)).Debug("url ignored because it was empty")
 						continue
 					}
 					// Pointer links.
 					if string(attr.Val[0]) == "#" {
-						globalLogger.With(zap.String("href", attr.Val)).Debug("url ignored because it was a pointer")
+						h.opts.logger.With(zap.String("href", attr.Val)).Debug("url ignored because it was a pointer")
 						continue
 					}
 
 					u, err := url.Parse(strings.TrimSpace(attr.Val))
 					if err != nil {
-						globalLogger.With(zap.String("href", attr.Val)).Debug("url ignored, could not Parse url")
+						h.opts.logger.With(zap.String("href", attr.Val)).Debug("url ignored, could not Parse url")
 						continue
 					}
 
 					if u.Scheme != "" && !strings.Contains(u.Scheme, "http") {
-						globalLogger.With(zap.String("href", attr.Val), zap.String("scheme", u.Scheme)).
+						h.opts.logger.With(zap.String("href", attr.Val), zap.String("scheme", u.Scheme)).
 							Debug("url ignored, bad scheme")
 						continue
 					}
 
 					if h.isInternalLink(u) {
-						u = u.ResolveReference(h.hostURL)
+						u = h.hostURL.ResolveReference(u)
 						h.internalLinks = append(h.internalLinks, u)
-						globalLogger.With(zap.String("url", u.String())).Info("marked as internal")
+						h.opts.logger.With(zap.String("url", u.String())).Info("marked as internal")
 					} else {
 						h.externalLinks = append(h.externalLinks, u)
-						globalLogger.With(zap.String("url", u.String())).Info("marked as external")
+						h.opts.logger.With(zap.String("url", u.String())).Info("marked as external")
 					}
 				}
 			}
@@ -295,45 +369,109 @@ func (h *HTMLAnalyzer) parseAndSetLinks() {
 	}
 }
 
+// InternalLinks returns the absolute urls of all the internal links of the html document.
+func (h *HTMLAnalyzer) InternalLinks() []*url.URL {
+	if !h.linksAreParsed {
+		h.parseAndSetLinks()
+	}
+	return h.internalLinks
+}
+
+// isInternalLink returns true if url is relative or its host contains the host of the document, the absolute links
+// of a document without a host, e.g. a local file, are all external.
 func (h *HTMLAnalyzer) isInternalLink(url *url.URL) bool {
-	return url.Host == "" || strings.Contains(strings.ToLower(url.Host), h.hostURL.Host)
+	return url.Host == "" || (h.hostURL.Host != "" && strings.Contains(strings.ToLower(url.Host), h.hostURL.Host))
 }
 
 // GetInaccessibleLinksCount loops on all of links and counts the links that doesn't return
-// an acceptable 2xx status code.
+// an acceptable 2xx status code. The links which are disallowed by the RobotsChecker of the options, or whose hosts
+// have a crawl delay longer than the max crawl delay of the options, are not requested and are counted by
+// GetRobotsSkippedLinksCount instead, the others are requested after the crawl delay of their hosts. The number of
+// links which are checked at the same time is limited by the link probe limit of the options, the links don't hold
+// a slot of the limit while they wait for the crawl delay so a slow host doesn't block the links of other hosts.
 func (h *HTMLAnalyzer) GetInaccessibleLinksCount(ctx context.Context) int {
 	if !h.linksAreParsed {
 		h.parseAndSetLinks()
 	}
 
+	totalLinks := append(h.externalLinks, h.internalLinks...)
+	progress := progressFuncFromContext(ctx)
+	emit := eventFuncFromContext(ctx)
+	progress(0, len(totalLinks))
+
 	var m sync.Mutex
-	var inaccessibleLinksCount int
-	inc := func() {
+	var skippedLinksCount, checkedLinksCount int
+	inaccessibleLinks := []string{}
+	inc := func(u *url.URL, outcome LinkOutcome) {
 		m.Lock()
-		inaccessibleLinksCount++
-		m.Unlock()
+		defer m.Unlock()
+		switch outcome {
+		case LinkOutcomeAccessible:
+		case LinkOutcomeRobotsSkipped:
+			skippedLinksCount++
+		default:
+			inaccessibleLinks = append(inaccessibleLinks, u.String())
+		}
+		checkedLinksCount++
+		progress(checkedLinksCount, len(totalLinks))
+		emit(Event{Phase: PhaseLinkChecked, Data: &LinkCheck{
+			URL:        u.String(),
+			Accessible: outcome == LinkOutcomeAccessible,
+			Skipped:    outcome == LinkOutcomeRobotsSkipped,
+			Outcome:    outcome,
+			Checked:    checkedLinksCount,
+			Total:      len(totalLinks),
+		}})
 	}
 
-	totalLinks := append(h.externalLinks, h.internalLinks...)
+	slots := h.opts.linkProbeSlots
 	wg := sync.WaitGroup{}
 	wg.Add(len(totalLinks))
 	for _, u := range totalLinks {
 		u := u
 		go func() {
 			defer wg.Done()
-			if !h.isAccessibleURL(ctx, u) {
-				globalLogger.With(zap.String("url", u.String())).Debug("url is not accessible")
-				inc()
+			if h.opts.robotsChecker != nil {
+				if err := h.opts.robotsChecker.WaitAtMost(ctx, u, h.opts.maxCrawlDelay); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					h.opts.logger.With(zap.String("url", u.String()), zap.Error(err)).
+						Debug("url is skipped because of robots rules")
+					recordLinkProbe(LinkOutcomeRobotsSkipped)
+					inc(u, LinkOutcomeRobotsSkipped)
+					return
+				}
+			}
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctx.Done():
+					return
+				}
+			}
+			atomic.AddInt64(&linkStats.inFlight, 1)
+			defer atomic.AddInt64(&linkStats.inFlight, -1)
+			start := h.opts.now()
+			accessible, outcome := h.isAccessibleURL(ctx, u)
+			recordLinkProbe(outcome)
+			l := h.opts.logger.With(zap.String("url", u.String()), zap.Duration("duration", h.opts.now().Sub(start)))
+			if !accessible {
+				l.With(zap.String("outcome", string(outcome))).Debug("url is not accessible")
+				inc(u, outcome)
 				return
 			}
-			globalLogger.With(zap.String("url", u.String())).Debug("url is accessible")
+			l.Debug("url is accessible")
+			inc(u, outcome)
 		}()
 	}
 
-	done := make(chan struct{})
+	// done is buffered so the waiting go routine doesn't leak when the context gets done first.
+	done := make(chan struct{}, 1)
 	go func() {
 		wg.Wait()
-		globalLogger.Debug("all go routines finished successfully")
+		h.opts.logger.Debug("all go routines finished successfully")
 		done <- struct{}{}
 	}()
 
@@ -341,34 +479,41 @@ func (h *HTMLAnalyzer) GetInaccessibleLinksCount(ctx context.Context) int {
 	case <-done:
 		// here we have finished process of inaccessible links before ending of context.
 	case <-ctx.Done():
-		globalLogger.Error("process stopped due to context got done")
+		h.opts.logger.Error("process stopped due to context got done")
 	}
-	return inaccessibleLinksCount
-}
 
-// isAccessibleURL checks the accessibility of a link.
-func (h *HTMLAnalyzer) isAccessibleURL(ctx context.Context, u *url.URL) bool {
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
-	if err != nil {
-		globalLogger.With(zap.String("url", u.String())).Error("could not create request")
-		return false
-	}
-
-	resp, err := globalHTTPClient.Do(req)
-	if err != nil {
-		globalLogger.With(zap.String("url", u.String())).Error("could not perform request")
-		return false
-	}
+	m.Lock()
+	defer m.Unlock()
+	sort.Strings(inaccessibleLinks)
+	h.inaccessibleLinks = inaccessibleLinks
+	h.robotsSkippedLinksCount = skippedLinksCount
+	return len(inaccessibleLinks)
+}
 
-	_, err = io.Copy(ioutil.Discard, resp.Body)
-	defer func() { _ = resp.Body.Close() }()
+// GetInaccessibleLinks returns the sorted urls of the links which were found inaccessible by the last call of
+// GetInaccessibleLinksCount.
+func (h *HTMLAnalyzer) GetInaccessibleLinks() []string {
+	return h.inaccessibleLinks
+}
 
-	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		return true
-	}
+// GetRobotsSkippedLinksCount returns the number of links which were skipped by the last call of
+// GetInaccessibleLinksCount because of robots rules.
+func (h *HTMLAnalyzer) GetRobotsSkippedLinksCount() int {
+	return h.robotsSkippedLinksCount
+}
 
-	globalLogger.With(zap.String("url", u.String())).Error("response code is not 2xx")
-	return false
+// isAccessibleURL checks the accessibility of a link by the LinkChecker of the options and returns the category
+// of the outcome.
+func (h *HTMLAnalyzer) isAccessibleURL(ctx context.Context, u *url.URL) (accessible bool, outcome LinkOutcome) {
+	ctx, span := startSpan(ctx, "htmlanalysis.isAccessibleURL", attribute.String("url", u.String()))
+	defer func() {
+		span.SetAttributes(attribute.String("outcome", string(outcome)))
+		if !accessible {
+			span.SetStatus(codes.Error, "link is not accessible")
+		}
+		span.End()
+	}()
+	return h.opts.linkChecker.Check(ctx, u)
 }
 
 // HasLoginForm parses the document and sets a flag in result field.
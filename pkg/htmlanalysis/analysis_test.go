@@ -2,27 +2,17 @@ package htmlanalysis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
-	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 )
 
-func TestSetGlobalHTTPClient(t *testing.T) {
-	httpClient := &http.Client{}
-	SetGlobalHTTPClient(httpClient)
-	assert.Equal(t, httpClient, globalHTTPClient)
-}
-
-func TestSetGlobalLogger(t *testing.T) {
-	logger := zap.NewNop()
-	SetGlobalLogger(logger)
-	assert.Equal(t, logger, globalLogger)
-}
-
 func TestNewHTMLAnalyzer(t *testing.T) {
 	htmlDoc := "htmlDoc"
 	hostURL := &url.URL{}
@@ -31,6 +21,7 @@ func TestNewHTMLAnalyzer(t *testing.T) {
 	assert.Equal(t, hostURL, ha.hostURL)
 	assert.NotNil(t, ha.internalLinks, ha.externalLinks)
 	assert.False(t, ha.linksAreParsed)
+	assert.NotNil(t, ha.opts.linkChecker)
 }
 
 func TestHTMLAnalyzer_GetHTMLVersion(t *testing.T) {
@@ -130,6 +121,150 @@ func TestHTMLAnalyzer_GetLinksCount(t *testing.T) {
 	actualInaccessibleLinksCount := a.GetInaccessibleLinksCount(context.Background())
 	assert.Equal(t, linksCount, actualLinksCount)
 	assert.Equal(t, inaccessibleLinksCount, actualInaccessibleLinksCount)
+	assert.Len(t, a.GetInaccessibleLinks(), inaccessibleLinksCount)
+}
+
+func TestHTMLAnalyzer_GetLinksCountWithoutHost(t *testing.T) {
+	htmlDoc := `<a href="/about">about</a><a href="https://example.com">example</a>`
+	a := NewHTMLAnalyzer(htmlDoc, &url.URL{Scheme: "file", Path: "/tmp/index.html"})
+	assert.Equal(t, &LinksCount{Internal: 1, External: 1}, a.GetLinksCount())
+}
+
+// testRobotsChecker is a RobotsChecker which disallows the urls of a host, waits for the crawl delays of hosts and
+// counts the waits of each host.
+type testRobotsChecker struct {
+	disallowedHost string
+	delays         map[string]time.Duration
+
+	mu    sync.Mutex
+	waits map[string]int
+}
+
+func (c *testRobotsChecker) WaitAtMost(_ context.Context, u *url.URL, maxDelay time.Duration) error {
+	c.mu.Lock()
+	if c.waits == nil {
+		c.waits = map[string]int{}
+	}
+	c.waits[u.Host]++
+	c.mu.Unlock()
+	if u.Host == c.disallowedHost {
+		return errors.New("disallowed")
+	}
+	delay := c.delays[u.Host]
+	if delay > maxDelay {
+		return errors.New("crawl delay exceeded")
+	}
+	time.Sleep(delay)
+	return nil
+}
+
+func TestHTMLAnalyzer_GetRobotsSkippedLinksCount(t *testing.T) {
+	htmlDoc, _, inaccessibleLinksCount, hostURL, shutdown := generateTestHTMLWithRealLinks()
+	defer shutdown()
+
+	// All the internal links are skipped and only the unavailable external link is inaccessible.
+	checker := &testRobotsChecker{disallowedHost: hostURL.Host}
+	a := NewHTMLAnalyzer(htmlDoc, hostURL, WithRobotsChecker(checker))
+	res, err := a.Analyze(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, inaccessibleLinksCount, res.InaccessibleLinksCount)
+	assert.Equal(t, res.LinksCount.Internal, res.RobotsSkippedLinksCount)
+	assert.Equal(t, res.RobotsSkippedLinksCount, a.GetRobotsSkippedLinksCount())
+
+	// The checker is waited for before every link.
+	waits := 0
+	for _, n := range checker.waits {
+		waits += n
+	}
+	assert.Equal(t, res.LinksCount.Internal+res.LinksCount.External, waits)
+}
+
+func TestHTMLAnalyzer_GetInaccessibleLinksCountSlowHost(t *testing.T) {
+	htmlDoc := `<a href="http://slow.com/1">1</a><a href="http://slow.com/2">2</a><a href="http://slow.com/3">3</a>
+<a href="http://fast.com/">fast</a><a href="http://delayed.com/">delayed</a>`
+	hostURL, _ := url.Parse("http://example.com/")
+	checker := &testRobotsChecker{delays: map[string]time.Duration{
+		"slow.com":    100 * time.Millisecond,
+		"delayed.com": 10 * time.Second,
+	}}
+	a := NewHTMLAnalyzer(htmlDoc, hostURL, WithRobotsChecker(checker), WithLinkChecker(&testLinkChecker{}),
+		WithLinkProbeLimit(1), WithMaxCrawlDelay(time.Second))
+
+	var mu sync.Mutex
+	var checked []string
+	ctx := WithEventFunc(context.Background(), func(e Event) {
+		if lc, ok := e.Data.(*LinkCheck); ok && !lc.Skipped {
+			mu.Lock()
+			checked = append(checked, lc.URL)
+			mu.Unlock()
+		}
+	})
+	assert.Equal(t, 0, a.GetInaccessibleLinksCount(ctx))
+
+	// The link of the fast host is checked while the links of the slow host wait for its crawl delay without
+	// holding the only probe slot, and the host whose crawl delay is too long is skipped.
+	if assert.Len(t, checked, 4) {
+		assert.Equal(t, "http://fast.com/", checked[0])
+	}
+	assert.Equal(t, 1, a.GetRobotsSkippedLinksCount())
+}
+
+func TestHTMLAnalyzer_InternalLinks(t *testing.T) {
+	hostURL, _ := url.Parse("http://example.com/blog/")
+	htmlDoc := `<a href="post-1">Post</a><a href="/about#team">About</a><a href="http://example.com/contact">Contact</a>
+<a href="http://other.com/">Other</a><a href="#top">Top</a>`
+	a := NewHTMLAnalyzer(htmlDoc, hostURL)
+
+	var actualLinks []string
+	for _, u := range a.InternalLinks() {
+		actualLinks = append(actualLinks, u.String())
+	}
+	assert.Equal(t, []string{
+		"http://example.com/blog/post-1",
+		"http://example.com/about#team",
+		"http://example.com/contact",
+	}, actualLinks)
+}
+
+func TestHTMLAnalyzer_GetCanonicalURL(t *testing.T) {
+	hostURL, _ := url.Parse("http://example.com/blog/post?utm=1")
+	testCases := []struct {
+		name         string
+		htmlDoc      string
+		expectedURL  string
+		hasCanonical bool
+	}{
+		{
+			name:         "absolute canonical",
+			htmlDoc:      `<link rel="canonical" href="http://example.com/blog/post">`,
+			expectedURL:  "http://example.com/blog/post",
+			hasCanonical: true,
+		},
+		{
+			name:         "relative canonical",
+			htmlDoc:      `<link rel="stylesheet" href="style.css"><link rel="Canonical" href="/blog/post" />`,
+			expectedURL:  "http://example.com/blog/post",
+			hasCanonical: true,
+		},
+		{
+			name:    "no canonical",
+			htmlDoc: `<link rel="stylesheet" href="style.css">`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := NewHTMLAnalyzer(tc.htmlDoc, hostURL).GetCanonicalURL()
+			if !tc.hasCanonical {
+				assert.Nil(t, u)
+				return
+			}
+			if assert.NotNil(t, u) {
+				assert.Equal(t, tc.expectedURL, u.String())
+			}
+		})
+	}
 }
 
 func TestHTMLAnalyzer_HasLoginForm(t *testing.T) {
@@ -0,0 +1,181 @@
+package htmlanalysis
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxCrawlDelay is the longest crawl delay of hosts which the links are checked with by default.
+const DefaultMaxCrawlDelay = 2 * time.Second
+
+// RobotsChecker applies the robots rules of the host of a url before it's requested. WaitAtMost blocks until the
+// crawl delay of the host has passed and returns an error if the url is disallowed or the crawl delay of the host is
+// longer than maxDelay.
+type RobotsChecker interface {
+	WaitAtMost(ctx context.Context, u *url.URL, maxDelay time.Duration) error
+}
+
+// LinkChecker checks whether a link is accessible and returns the category of the outcome.
+type LinkChecker interface {
+	Check(ctx context.Context, u *url.URL) (accessible bool, outcome LinkOutcome)
+}
+
+// options holds the dependencies and the limits of analyses.
+// linkProbeSlots limits the number of links which are checked at the same time, a nil channel doesn't limit them.
+// The links of the hosts whose crawl delay is longer than maxCrawlDelay are not checked.
+type options struct {
+	logger         *zap.Logger
+	httpClient     *http.Client
+	linkChecker    LinkChecker
+	robotsChecker  RobotsChecker
+	maxCrawlDelay  time.Duration
+	linkProbeSlots chan struct{}
+	now            func() time.Time
+}
+
+// Option configures the analyses of an Analyzer or an HTMLAnalyzer.
+type Option func(*options)
+
+// WithLogger sets the logger of analyses, analyses don't log by default.
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHTTPClient sets the HTTP client which checks the links when no LinkChecker is set.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// WithLinkChecker sets the LinkChecker of links, the links are requested by the HTTP client by default.
+func WithLinkChecker(c LinkChecker) Option {
+	return func(o *options) {
+		o.linkChecker = c
+	}
+}
+
+// WithRobotsChecker sets a RobotsChecker which is consulted before checking links, all the links are allowed and
+// requested without delay by default.
+func WithRobotsChecker(c RobotsChecker) Option {
+	return func(o *options) {
+		o.robotsChecker = c
+	}
+}
+
+// WithMaxCrawlDelay sets the longest crawl delay of hosts which the links are checked with, the links of the hosts
+// with longer crawl delays are skipped like the disallowed ones. It's DefaultMaxCrawlDelay by default and a delay
+// less than 1 accepts any crawl delay.
+func WithMaxCrawlDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.maxCrawlDelay = d
+	}
+}
+
+// WithLinkProbeLimit limits the number of links which are checked at the same time to limit, a limit less than 1
+// doesn't limit them. The limit is shared by all the analyses of an Analyzer.
+func WithLinkProbeLimit(limit int) Option {
+	return func(o *options) {
+		o.linkProbeSlots = nil
+		if limit > 0 {
+			o.linkProbeSlots = make(chan struct{}, limit)
+		}
+	}
+}
+
+// WithClock sets the function which returns the current time to analyses, time.Now is used by default.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// newOptions returns the options of opts over the defaults.
+// We should reduce the IdleConnTimeout of the default HTTP client because the requests that are being performed
+// by it target different hosts and there is no meaning to have idle connection for a long time.
+func newOptions(opts []Option) *options {
+	o := &options{logger: zap.NewNop(), now: time.Now, maxCrawlDelay: DefaultMaxCrawlDelay}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				IdleConnTimeout: 5 * time.Second,
+			},
+		}
+	}
+	if o.linkChecker == nil {
+		o.linkChecker = &httpLinkChecker{client: o.httpClient, logger: o.logger}
+	}
+	return o
+}
+
+// Analyzer analyzes html documents with its options.
+// It's safe for concurrent use and the limits of its options are shared by all of its analyses.
+type Analyzer struct {
+	opts *options
+}
+
+// NewAnalyzer creates an Analyzer with opts.
+func NewAnalyzer(opts ...Option) *Analyzer {
+	return &Analyzer{opts: newOptions(opts)}
+}
+
+// Analyze analyzes htmlDoc whose relative links are resolved against hostURL.
+// Its signature matches the analyze functions of the handler, the crawler and the sitemap auditor.
+func (a *Analyzer) Analyze(ctx context.Context, hostURL *url.URL, htmlDoc string) (*Result, error) {
+	return a.NewHTMLAnalyzer(htmlDoc, hostURL).Analyze(ctx)
+}
+
+// NewHTMLAnalyzer creates an HTMLAnalyzer for htmlDoc which shares the options of a.
+func (a *Analyzer) NewHTMLAnalyzer(htmlDoc string, hostURL *url.URL) *HTMLAnalyzer {
+	return newHTMLAnalyzer(htmlDoc, hostURL, a.opts)
+}
+
+// httpLinkChecker is the default LinkChecker which performs a GET request to links and accepts 2xx status codes.
+type httpLinkChecker struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// Check performs a GET request to u, the request is recorded on the span of ctx.
+func (c *httpLinkChecker) Check(ctx context.Context, u *url.URL) (bool, LinkOutcome) {
+	span := trace.SpanFromContext(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		c.logger.With(zap.String("url", u.String())).Error("could not create request")
+		span.RecordError(err)
+		return false, LinkOutcomeNetworkError
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.With(zap.String("url", u.String())).Error("could not perform request")
+		span.RecordError(err)
+		return false, errorOutcome(err)
+	}
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	defer func() { _ = resp.Body.Close() }()
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	outcome := statusOutcome(resp.StatusCode)
+	if outcome == LinkOutcomeAccessible {
+		return true, outcome
+	}
+
+	c.logger.With(zap.String("url", u.String())).Error("response code is not 2xx")
+	return false, outcome
+}
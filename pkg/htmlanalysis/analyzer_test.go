@@ -0,0 +1,143 @@
+package htmlanalysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testLinkChecker is a LinkChecker which finds the links of a host inaccessible.
+type testLinkChecker struct {
+	inaccessibleHost string
+	checked          int64
+}
+
+func (c *testLinkChecker) Check(_ context.Context, u *url.URL) (bool, LinkOutcome) {
+	atomic.AddInt64(&c.checked, 1)
+	if u.Host == c.inaccessibleHost {
+		return false, LinkOutcomeClientError
+	}
+	return true, LinkOutcomeAccessible
+}
+
+func TestNewAnalyzer(t *testing.T) {
+	a := NewAnalyzer()
+	assert.NotNil(t, a.opts.logger)
+	assert.NotNil(t, a.opts.httpClient)
+	assert.Nil(t, a.opts.linkProbeSlots)
+	assert.Equal(t, &httpLinkChecker{client: a.opts.httpClient, logger: a.opts.logger}, a.opts.linkChecker)
+
+	hc := &http.Client{}
+	a = NewAnalyzer(WithHTTPClient(hc), WithLinkProbeLimit(3))
+	assert.Equal(t, hc, a.opts.httpClient)
+	assert.Equal(t, 3, cap(a.opts.linkProbeSlots))
+	assert.Nil(t, NewAnalyzer(WithLinkProbeLimit(0)).opts.linkProbeSlots)
+}
+
+func TestWithLinkProbeLimit(t *testing.T) {
+	var inFlight, maxInFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			m := atomic.LoadInt64(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt64(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	htmlDoc := ""
+	for i := 0; i < 6; i++ {
+		htmlDoc += fmt.Sprintf(`<a href="%s/%d">Link</a>`, server.URL, i)
+	}
+	hostURL, _ := url.Parse(server.URL)
+
+	// The limit is shared by the analyses of the analyzer.
+	a := NewAnalyzer(WithLinkProbeLimit(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count := a.NewHTMLAnalyzer(htmlDoc, hostURL).GetInaccessibleLinksCount(context.Background())
+			assert.Equal(t, 0, count)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(2), atomic.LoadInt64(&maxInFlight))
+}
+
+func TestWithLinkChecker(t *testing.T) {
+	hostURL, _ := url.Parse("https://example.com")
+	htmlDoc := `<a href="/a">a</a><a href="/b">b</a><a href="https://other.com">other</a>`
+	checker := &testLinkChecker{inaccessibleHost: "other.com"}
+
+	res, err := NewAnalyzer(WithLinkChecker(checker)).Analyze(context.Background(), hostURL, htmlDoc)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, res.InaccessibleLinksCount)
+		assert.Equal(t, []string{"https://other.com"}, res.InaccessibleLinks)
+	}
+	assert.Equal(t, int64(3), atomic.LoadInt64(&checker.checked))
+}
+
+func TestWithClock(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	now := time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC)
+	var m sync.Mutex
+	clock := func() time.Time {
+		m.Lock()
+		defer m.Unlock()
+		now = now.Add(time.Second)
+		return now
+	}
+	hostURL, _ := url.Parse("https://example.com")
+
+	a := NewAnalyzer(WithLogger(zap.New(core)), WithLinkChecker(&testLinkChecker{}), WithClock(clock))
+	_, err := a.Analyze(context.Background(), hostURL, `<a href="/a">a</a>`)
+	assert.NoError(t, err)
+	entries := logs.FilterMessage("url is accessible").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, time.Second, entries[0].ContextMap()["duration"])
+	}
+}
+
+func TestAnalyzer_AnalyzeConcurrently(t *testing.T) {
+	hostURL, _ := url.Parse("https://example.com")
+	htmlDoc := `<!DOCTYPE html><title>Detective</title><a href="/a">a</a><a href="https://other.com">other</a>`
+
+	// Analyzers with different settings coexist and are used by different go routines at the same time.
+	analyzers := []*Analyzer{
+		NewAnalyzer(WithLinkChecker(&testLinkChecker{inaccessibleHost: "other.com"})),
+		NewAnalyzer(WithLinkChecker(&testLinkChecker{inaccessibleHost: "example.com"}), WithLinkProbeLimit(1)),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, a := range analyzers {
+			wg.Add(1)
+			go func(a *Analyzer) {
+				defer wg.Done()
+				res, err := a.Analyze(context.Background(), hostURL, htmlDoc)
+				if assert.NoError(t, err) {
+					assert.Equal(t, "Detective", res.PageTitle)
+					assert.Equal(t, 1, res.InaccessibleLinksCount)
+				}
+			}(a)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,94 @@
+package htmlanalysis
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"time"
+)
+
+// tlsVersions is the names of the TLS versions by their code.
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// Certificate is the summary of a certificate of a chain.
+// DaysRemaining is the number of the whole days until NotAfter, it's negative for the expired certificates.
+type Certificate struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"`
+	SelfSigned    bool      `json:"self_signed"`
+}
+
+// TLSReport is the certificate health of an https page.
+// Version and CipherSuite are the negotiated protocol version and cipher suite of the connection.
+// Certificate is the leaf certificate of the page and Chain is the certificate chain which is presented by the
+// server, leaf first. HostnameMismatch shows that the leaf certificate is not valid for the host of the page.
+type TLSReport struct {
+	Version          string         `json:"version"`
+	CipherSuite      string         `json:"cipher_suite"`
+	Certificate      *Certificate   `json:"certificate"`
+	Chain            []*Certificate `json:"chain"`
+	HostnameMismatch bool           `json:"hostname_mismatch"`
+}
+
+// NewTLSReport creates the TLSReport of a connection to host whose state is state, the remaining days are counted
+// from now. It returns nil if state is nil or the server presented no certificates, e.g. for http pages.
+func NewTLSReport(state *tls.ConnectionState, host string, now time.Time) *TLSReport {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	r := &TLSReport{
+		Version:     tlsVersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		Chain:       make([]*Certificate, 0, len(state.PeerCertificates)),
+	}
+	for _, c := range state.PeerCertificates {
+		r.Chain = append(r.Chain, newCertificate(c, now))
+	}
+	r.Certificate = r.Chain[0]
+	r.HostnameMismatch = state.PeerCertificates[0].VerifyHostname(host) != nil
+	return r
+}
+
+// newCertificate creates the summary of c whose remaining days are counted from now.
+func newCertificate(c *x509.Certificate, now time.Time) *Certificate {
+	sans := make([]string, 0, len(c.DNSNames)+len(c.IPAddresses))
+	sans = append(sans, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return &Certificate{
+		Subject:       c.Subject.String(),
+		Issuer:        c.Issuer.String(),
+		SANs:          sans,
+		NotBefore:     c.NotBefore.UTC(),
+		NotAfter:      c.NotAfter.UTC(),
+		DaysRemaining: int(math.Floor(c.NotAfter.Sub(now).Hours() / 24)),
+		SelfSigned:    isSelfSigned(c),
+	}
+}
+
+// isSelfSigned returns true if c is issued by itself and its signature is made by its own key.
+func isSelfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawIssuer, c.RawSubject) &&
+		c.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature) == nil
+}
+
+// tlsVersionName returns the name of the TLS version of code.
+func tlsVersionName(code uint16) string {
+	if name, ok := tlsVersions[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", code)
+}
@@ -0,0 +1,69 @@
+package htmlanalysis
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testConnectionState returns the connection state of a request to a TLS test server.
+func testConnectionState(t *testing.T) *tls.ConnectionState {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("error while performing request: %v", err)
+	}
+	_ = resp.Body.Close()
+	return resp.TLS
+}
+
+func TestNewTLSReport(t *testing.T) {
+	assert.Nil(t, NewTLSReport(nil, "example.com", time.Now()))
+	assert.Nil(t, NewTLSReport(&tls.ConnectionState{}, "example.com", time.Now()))
+
+	state := testConnectionState(t)
+	leaf := state.PeerCertificates[0]
+	now := leaf.NotAfter.Add(-36 * time.Hour)
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	r := NewTLSReport(state, "example.com", now)
+	if !assert.NotNil(t, r) {
+		return
+	}
+	assert.Equal(t, tlsVersionName(state.Version), r.Version)
+	assert.Equal(t, tls.CipherSuiteName(state.CipherSuite), r.CipherSuite)
+	assert.False(t, r.HostnameMismatch)
+	assert.Equal(t, []*Certificate{r.Certificate}, r.Chain)
+	assert.Contains(t, sans, "127.0.0.1")
+	assert.Equal(t, &Certificate{
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		SANs:          sans,
+		NotBefore:     leaf.NotBefore.UTC(),
+		NotAfter:      leaf.NotAfter.UTC(),
+		DaysRemaining: 1,
+		SelfSigned:    true,
+	}, r.Certificate)
+
+	// The certificate is not valid for other hosts and the days of the expired certificates are negative.
+	r = NewTLSReport(state, "detective.local", leaf.NotAfter.Add(12*time.Hour))
+	assert.True(t, r.HostnameMismatch)
+	assert.Equal(t, -1, r.Certificate.DaysRemaining)
+}
+
+func TestTLSVersionName(t *testing.T) {
+	assert.Equal(t, "TLS 1.2", tlsVersionName(tls.VersionTLS12))
+	assert.Equal(t, "TLS 1.3", tlsVersionName(tls.VersionTLS13))
+	assert.Equal(t, "0x0300", tlsVersionName(0x0300))
+}
@@ -0,0 +1,111 @@
+package htmlanalysis
+
+import "sort"
+
+// StringChange is the old and the new value of a string field which has been changed.
+type StringChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ResultDiff is the difference between two results of analyzing the same page, the old result is called
+// before and the new one is called after.
+// PageTitle and HTMLVersion are nil when they haven't been changed.
+// HeadingsDelta, LinksDelta and InaccessibleLinksDelta are the counts of after minus the counts of before.
+// NewlyBrokenLinks are the links which are inaccessible in after but weren't in before,
+// and NewlyFixedLinks are the links which were inaccessible in before but aren't in after,
+// either because they have been fixed or because they have been removed from the page.
+// LoginFormAppeared and LoginFormDisappeared show the changes of HasLoginForm.
+type ResultDiff struct {
+	Changed                bool          `json:"changed"`
+	PageTitle              *StringChange `json:"page_title"`
+	HTMLVersion            *StringChange `json:"html_version"`
+	HeadingsDelta          HeadingsCount `json:"headings_delta"`
+	LinksDelta             LinksCount    `json:"links_delta"`
+	InaccessibleLinksDelta int           `json:"inaccessible_links_delta"`
+	NewlyBrokenLinks       []string      `json:"newly_broken_links"`
+	NewlyFixedLinks        []string      `json:"newly_fixed_links"`
+	LoginFormAppeared      bool          `json:"login_form_appeared"`
+	LoginFormDisappeared   bool          `json:"login_form_disappeared"`
+}
+
+// Diff compares the results of analyzing a page before and after a change and returns their difference.
+func Diff(before, after *Result) *ResultDiff {
+	if before == nil {
+		before = &Result{}
+	}
+	if after == nil {
+		after = &Result{}
+	}
+
+	d := &ResultDiff{
+		NewlyBrokenLinks: subtract(after.InaccessibleLinks, before.InaccessibleLinks),
+		NewlyFixedLinks:  subtract(before.InaccessibleLinks, after.InaccessibleLinks),
+	}
+	if before.PageTitle != after.PageTitle {
+		d.PageTitle = &StringChange{Old: before.PageTitle, New: after.PageTitle}
+	}
+	if before.HTMLVersion != after.HTMLVersion {
+		d.HTMLVersion = &StringChange{Old: before.HTMLVersion, New: after.HTMLVersion}
+	}
+
+	oh, nh := headingsOrZero(before.HeadingsCount), headingsOrZero(after.HeadingsCount)
+	d.HeadingsDelta = HeadingsCount{
+		H1: nh.H1 - oh.H1,
+		H2: nh.H2 - oh.H2,
+		H3: nh.H3 - oh.H3,
+		H4: nh.H4 - oh.H4,
+		H5: nh.H5 - oh.H5,
+		H6: nh.H6 - oh.H6,
+	}
+	ol, nl := linksOrZero(before.LinksCount), linksOrZero(after.LinksCount)
+	d.LinksDelta = LinksCount{
+		Internal: nl.Internal - ol.Internal,
+		External: nl.External - ol.External,
+	}
+	d.InaccessibleLinksDelta = after.InaccessibleLinksCount - before.InaccessibleLinksCount
+	d.LoginFormAppeared = !before.HasLoginForm && after.HasLoginForm
+	d.LoginFormDisappeared = before.HasLoginForm && !after.HasLoginForm
+
+	d.Changed = d.PageTitle != nil ||
+		d.HTMLVersion != nil ||
+		d.HeadingsDelta != HeadingsCount{} ||
+		d.LinksDelta != LinksCount{} ||
+		d.InaccessibleLinksDelta != 0 ||
+		len(d.NewlyBrokenLinks) > 0 ||
+		len(d.NewlyFixedLinks) > 0 ||
+		d.LoginFormAppeared ||
+		d.LoginFormDisappeared
+
+	return d
+}
+
+// subtract returns the sorted items of a which are not in b.
+func subtract(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+	}
+	diff := []string{}
+	for _, s := range a {
+		if !inB[s] {
+			diff = append(diff, s)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+func headingsOrZero(hc *HeadingsCount) *HeadingsCount {
+	if hc == nil {
+		return &HeadingsCount{}
+	}
+	return hc
+}
+
+func linksOrZero(lc *LinksCount) *LinksCount {
+	if lc == nil {
+		return &LinksCount{}
+	}
+	return lc
+}
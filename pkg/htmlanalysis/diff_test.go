@@ -0,0 +1,66 @@
+package htmlanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := &Result{
+		HTMLVersion:            "HTML 4.01 Strict",
+		PageTitle:              "Home",
+		HeadingsCount:          &HeadingsCount{H1: 1, H2: 3},
+		LinksCount:             &LinksCount{Internal: 4, External: 2},
+		InaccessibleLinksCount: 2,
+		InaccessibleLinks:      []string{"http://example.com/a", "http://example.com/b"},
+		HasLoginForm:           false,
+	}
+	after := &Result{
+		HTMLVersion:            "HTML 5",
+		PageTitle:              "Welcome",
+		HeadingsCount:          &HeadingsCount{H2: 4},
+		LinksCount:             &LinksCount{Internal: 5, External: 2},
+		InaccessibleLinksCount: 2,
+		InaccessibleLinks:      []string{"http://example.com/c", "http://example.com/b"},
+		HasLoginForm:           true,
+	}
+
+	d := Diff(before, after)
+	assert.Equal(t, &ResultDiff{
+		Changed:                true,
+		PageTitle:              &StringChange{Old: "Home", New: "Welcome"},
+		HTMLVersion:            &StringChange{Old: "HTML 4.01 Strict", New: "HTML 5"},
+		HeadingsDelta:          HeadingsCount{H1: -1, H2: 1},
+		LinksDelta:             LinksCount{Internal: 1},
+		InaccessibleLinksDelta: 0,
+		NewlyBrokenLinks:       []string{"http://example.com/c"},
+		NewlyFixedLinks:        []string{"http://example.com/a"},
+		LoginFormAppeared:      true,
+	}, d)
+
+	d = Diff(after, before)
+	assert.True(t, d.LoginFormDisappeared)
+	assert.False(t, d.LoginFormAppeared)
+	assert.Equal(t, []string{"http://example.com/a"}, d.NewlyBrokenLinks)
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	r := &Result{
+		PageTitle:         "Home",
+		HeadingsCount:     &HeadingsCount{H1: 1},
+		LinksCount:        &LinksCount{Internal: 1},
+		InaccessibleLinks: []string{"http://example.com/a"},
+	}
+	d := Diff(r, r)
+	assert.False(t, d.Changed)
+	assert.Nil(t, d.PageTitle)
+	assert.Empty(t, d.NewlyBrokenLinks)
+	assert.Empty(t, d.NewlyFixedLinks)
+
+	// Missing counts of results are treated as zero.
+	d = Diff(&Result{PageTitle: "Home"}, r)
+	assert.True(t, d.Changed)
+	assert.Equal(t, HeadingsCount{H1: 1}, d.HeadingsDelta)
+	assert.Equal(t, []string{"http://example.com/a"}, d.NewlyBrokenLinks)
+}
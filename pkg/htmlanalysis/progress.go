@@ -0,0 +1,77 @@
+package htmlanalysis
+
+import "context"
+
+// ProgressFunc is a callback which is called during checking of links.
+// checked is the number of links that have been checked so far and total is the number of all links.
+type ProgressFunc func(checked, total int)
+
+// Phase is the name of a step of the analysis.
+type Phase string
+
+// List of analysis phases which are reported to an EventFunc.
+const (
+	PhaseParsed      Phase = "parsed"
+	PhaseHTMLVersion Phase = "html_version"
+	PhaseTitle       Phase = "title"
+	PhaseHeadings    Phase = "headings"
+	PhaseLinks       Phase = "links"
+	PhaseLinkChecked Phase = "link_checked"
+	PhaseLoginForm   Phase = "login_form"
+)
+
+// LinkCheck is the outcome of checking the accessibility of a single link.
+// Skipped shows that the link has not been requested because of robots rules and Outcome is the category of the
+// outcome of the check.
+type LinkCheck struct {
+	URL        string      `json:"url"`
+	Accessible bool        `json:"accessible"`
+	Skipped    bool        `json:"skipped"`
+	Outcome    LinkOutcome `json:"outcome"`
+	Checked    int         `json:"checked"`
+	Total      int         `json:"total"`
+}
+
+// Event is a notification which is emitted when a phase of the analysis finishes.
+// Data is the outcome of the phase, e.g. a string for PhaseTitle, a *HeadingsCount for PhaseHeadings
+// and a *LinkCheck for PhaseLinkChecked.
+type Event struct {
+	Phase Phase       `json:"phase"`
+	Data  interface{} `json:"data"`
+}
+
+// EventFunc is a callback which receives the events of an analysis.
+// It may be called from different go routines at the same time.
+type EventFunc func(e Event)
+
+type progressFuncKey struct{}
+
+type eventFuncKey struct{}
+
+// WithProgressFunc returns a copy of ctx which carries f, so the analysis that runs with the returned context
+// reports its progress to f.
+func WithProgressFunc(ctx context.Context, f ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressFuncKey{}, f)
+}
+
+// WithEventFunc returns a copy of ctx which carries f, so the analysis that runs with the returned context
+// emits an Event to f at the end of each phase.
+func WithEventFunc(ctx context.Context, f EventFunc) context.Context {
+	return context.WithValue(ctx, eventFuncKey{}, f)
+}
+
+// progressFuncFromContext returns the ProgressFunc stored in ctx or a no-op function if there is none.
+func progressFuncFromContext(ctx context.Context) ProgressFunc {
+	if f, ok := ctx.Value(progressFuncKey{}).(ProgressFunc); ok && f != nil {
+		return f
+	}
+	return func(int, int) {}
+}
+
+// eventFuncFromContext returns the EventFunc stored in ctx or a no-op function if there is none.
+func eventFuncFromContext(ctx context.Context) EventFunc {
+	if f, ok := ctx.Value(eventFuncKey{}).(EventFunc); ok && f != nil {
+		return f
+	}
+	return func(Event) {}
+}
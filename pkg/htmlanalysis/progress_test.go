@@ -0,0 +1,71 @@
+package htmlanalysis
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProgressFunc(t *testing.T) {
+	htmlDoc, linksCount, _, hostURL, shutdown := generateTestHTMLWithRealLinks()
+	defer shutdown()
+	totalLinks := linksCount.Internal + linksCount.External
+
+	var m sync.Mutex
+	var checked []int
+	ctx := WithProgressFunc(context.Background(), func(c, total int) {
+		m.Lock()
+		defer m.Unlock()
+		assert.Equal(t, totalLinks, total)
+		checked = append(checked, c)
+	})
+	a := NewHTMLAnalyzer(htmlDoc, hostURL)
+	_ = a.GetInaccessibleLinksCount(ctx)
+
+	m.Lock()
+	defer m.Unlock()
+	if assert.Len(t, checked, totalLinks+1) {
+		for i, c := range checked {
+			assert.Equal(t, i, c)
+		}
+	}
+}
+
+func TestProgressFuncFromContext(t *testing.T) {
+	f := progressFuncFromContext(context.Background())
+	assert.NotNil(t, f)
+	assert.NotPanics(t, func() { f(1, 2) })
+}
+
+func TestWithEventFunc(t *testing.T) {
+	htmlDoc, linksCount, _, hostURL, shutdown := generateTestHTMLWithRealLinks()
+	defer shutdown()
+	totalLinks := linksCount.Internal + linksCount.External
+
+	var m sync.Mutex
+	phases := map[Phase]int{}
+	ctx := WithEventFunc(context.Background(), func(e Event) {
+		m.Lock()
+		defer m.Unlock()
+		phases[e.Phase]++
+	})
+	res, err := NewHTMLAnalyzer(htmlDoc, hostURL).Analyze(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, linksCount, res.LinksCount)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, map[Phase]int{
+		PhaseParsed:      1,
+		PhaseHTMLVersion: 1,
+		PhaseTitle:       1,
+		PhaseHeadings:    1,
+		PhaseLinks:       1,
+		PhaseLinkChecked: totalLinks,
+		PhaseLoginForm:   1,
+	}, phases)
+}
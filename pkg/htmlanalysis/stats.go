@@ -0,0 +1,117 @@
+package htmlanalysis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+// LinkOutcome is the category of the outcome of checking a link.
+type LinkOutcome string
+
+// List of available link outcomes.
+// LinkOutcomeUnexpectedStatus is used for the 1xx and 3xx status codes which are not followed by the HTTP client
+// and LinkOutcomeTLSError is used for the links whose certificate is not trusted or whose TLS handshake fails.
+const (
+	LinkOutcomeAccessible       LinkOutcome = "accessible"
+	LinkOutcomeClientError      LinkOutcome = "client_error"
+	LinkOutcomeServerError      LinkOutcome = "server_error"
+	LinkOutcomeUnexpectedStatus LinkOutcome = "unexpected_status"
+	LinkOutcomeTimeout          LinkOutcome = "timeout"
+	LinkOutcomeTLSError         LinkOutcome = "tls_error"
+	LinkOutcomeNetworkError     LinkOutcome = "network_error"
+	LinkOutcomeRobotsSkipped    LinkOutcome = "robots_skipped"
+)
+
+// LinkOutcomes is the list of all the link outcomes.
+var LinkOutcomes = []LinkOutcome{
+	LinkOutcomeAccessible,
+	LinkOutcomeClientError,
+	LinkOutcomeServerError,
+	LinkOutcomeUnexpectedStatus,
+	LinkOutcomeTimeout,
+	LinkOutcomeTLSError,
+	LinkOutcomeNetworkError,
+	LinkOutcomeRobotsSkipped,
+}
+
+// LinkStats is a snapshot of the package level counters of link checks.
+// InFlight is the number of link checks which are running and Probes is the number of checked links by outcome.
+type LinkStats struct {
+	InFlight int64
+	Probes   map[LinkOutcome]uint64
+}
+
+var linkStats = struct {
+	inFlight int64
+	probes   map[LinkOutcome]*uint64
+}{
+	probes: func() map[LinkOutcome]*uint64 {
+		probes := make(map[LinkOutcome]*uint64, len(LinkOutcomes))
+		for _, o := range LinkOutcomes {
+			probes[o] = new(uint64)
+		}
+		return probes
+	}(),
+}
+
+// GetLinkStats returns a snapshot of the link checks of all the analyses of the process.
+func GetLinkStats() LinkStats {
+	s := LinkStats{
+		InFlight: atomic.LoadInt64(&linkStats.inFlight),
+		Probes:   make(map[LinkOutcome]uint64, len(linkStats.probes)),
+	}
+	for o, c := range linkStats.probes {
+		s.Probes[o] = atomic.LoadUint64(c)
+	}
+	return s
+}
+
+// recordLinkProbe counts a link check with outcome o.
+func recordLinkProbe(o LinkOutcome) {
+	atomic.AddUint64(linkStats.probes[o], 1)
+}
+
+// statusOutcome returns the outcome of a link which responded with status code.
+func statusOutcome(code int) LinkOutcome {
+	switch {
+	case code >= 200 && code <= 299:
+		return LinkOutcomeAccessible
+	case code >= 400 && code <= 499:
+		return LinkOutcomeClientError
+	case code >= 500:
+		return LinkOutcomeServerError
+	default:
+		return LinkOutcomeUnexpectedStatus
+	}
+}
+
+// errorOutcome returns the outcome of a link whose request failed with err.
+func errorOutcome(err error) LinkOutcome {
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+		return LinkOutcomeTimeout
+	}
+	if isTLSError(err) {
+		return LinkOutcomeTLSError
+	}
+	return LinkOutcomeNetworkError
+}
+
+// isTLSError returns true if err is caused by an untrusted, expired or mismatched certificate or by a server which
+// doesn't speak TLS.
+func isTLSError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		recordHeader     tls.RecordHeaderError
+	)
+	return errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &recordHeader)
+}
@@ -0,0 +1,84 @@
+package htmlanalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusOutcome(t *testing.T) {
+	for code, expected := range map[int]LinkOutcome{
+		200: LinkOutcomeAccessible,
+		204: LinkOutcomeAccessible,
+		304: LinkOutcomeUnexpectedStatus,
+		404: LinkOutcomeClientError,
+		503: LinkOutcomeServerError,
+	} {
+		assert.Equal(t, expected, statusOutcome(code), code)
+	}
+}
+
+func TestErrorOutcome(t *testing.T) {
+	assert.Equal(t, LinkOutcomeTimeout, errorOutcome(fmt.Errorf("get: %w", context.DeadlineExceeded)))
+	assert.Equal(t, LinkOutcomeNetworkError, errorOutcome(errors.New("connection refused")))
+}
+
+func TestTLSErrorOutcome(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The certificate of the test server is only trusted by its own client.
+	_, err := http.Get(server.URL)
+	assert.Equal(t, LinkOutcomeTLSError, errorOutcome(err))
+
+	var checks []*LinkCheck
+	ctx := WithEventFunc(context.Background(), func(e Event) {
+		if lc, ok := e.Data.(*LinkCheck); ok {
+			checks = append(checks, lc)
+		}
+	})
+	hostURL, _ := url.Parse(server.URL)
+	htmlDoc := fmt.Sprintf(`<a href="%s/a">a</a>`, server.URL)
+
+	_ = NewHTMLAnalyzer(htmlDoc, hostURL).GetInaccessibleLinksCount(ctx)
+	if assert.Len(t, checks, 1) {
+		assert.False(t, checks[0].Accessible)
+		assert.Equal(t, LinkOutcomeTLSError, checks[0].Outcome)
+	}
+
+	checks = nil
+	_ = NewHTMLAnalyzer(htmlDoc, hostURL, WithHTTPClient(server.Client())).GetInaccessibleLinksCount(ctx)
+	if assert.Len(t, checks, 1) {
+		assert.True(t, checks[0].Accessible)
+		assert.Equal(t, LinkOutcomeAccessible, checks[0].Outcome)
+	}
+}
+
+func TestGetLinkStats(t *testing.T) {
+	htmlDoc, linksCount, inaccessibleLinksCount, hostURL, shutdown := generateTestHTMLWithRealLinks()
+	defer shutdown()
+
+	before := GetLinkStats()
+	_ = NewHTMLAnalyzer(htmlDoc, hostURL).GetInaccessibleLinksCount(context.Background())
+	after := GetLinkStats()
+
+	var probes, failures uint64
+	for _, o := range LinkOutcomes {
+		delta := after.Probes[o] - before.Probes[o]
+		probes += delta
+		if o != LinkOutcomeAccessible {
+			failures += delta
+		}
+	}
+	assert.Equal(t, uint64(linksCount.Internal+linksCount.External), probes)
+	assert.Equal(t, uint64(inaccessibleLinksCount), failures)
+	assert.Equal(t, int64(0), after.InFlight)
+}
@@ -0,0 +1,25 @@
+package htmlanalysis
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer of this package.
+const instrumentationName = "github.com/mammadmodi/detective/pkg/htmlanalysis"
+
+// startSpan starts a span by the tracer of the global otel TracerProvider.
+// The tracer is looked up on each call, so the spans follow the provider which is registered later.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// tracePhase runs f in a span which is named after the phase of the analysis.
+func tracePhase(ctx context.Context, phase string, f func(ctx context.Context)) {
+	ctx, span := startSpan(ctx, "htmlanalysis."+phase)
+	defer span.End()
+	f(ctx)
+}
@@ -0,0 +1,65 @@
+package htmlanalysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryExporter registers a global TracerProvider which exports the spans to the returned exporter
+// synchronously, the previous provider is restored after the test.
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func TestHTMLAnalyzer_AnalyzeSpans(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	htmlDoc, linksCount, _, hostURL, shutdown := generateTestHTMLWithRealLinks()
+	defer shutdown()
+
+	_, err := NewHTMLAnalyzer(htmlDoc, hostURL).Analyze(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	spans := exporter.GetSpans()
+	names := map[string]int{}
+	var root tracetest.SpanStub
+	for _, s := range spans {
+		names[s.Name]++
+		if s.Name == "htmlanalysis.Analyze" {
+			root = s
+		}
+	}
+	for _, name := range []string{
+		"htmlanalysis.Analyze",
+		"htmlanalysis.parsed",
+		"htmlanalysis.html_version",
+		"htmlanalysis.title",
+		"htmlanalysis.headings",
+		"htmlanalysis.links",
+		"htmlanalysis.inaccessible_links",
+		"htmlanalysis.login_form",
+		"htmlanalysis.canonical_url",
+	} {
+		assert.Equal(t, 1, names[name], name)
+	}
+	assert.Equal(t, linksCount.Internal+linksCount.External, names["htmlanalysis.isAccessibleURL"])
+
+	// All the spans belong to the trace of the analysis.
+	for _, s := range spans {
+		assert.Equal(t, root.SpanContext.TraceID(), s.SpanContext.TraceID(), s.Name)
+		if s.Name != "htmlanalysis.Analyze" {
+			assert.NotEqual(t, trace.SpanID{}, s.Parent.SpanID(), s.Name)
+		}
+	}
+}
@@ -12,9 +12,18 @@ import (
 
 // NewZapLogger is a factory function which creates a zap logger based on the entry config file.
 func NewZapLogger(name string, config *Config) (*zap.Logger, error) {
+	l, _, err := NewLeveledZapLogger(name, config)
+	return l, err
+}
+
+// NewLeveledZapLogger is like NewZapLogger but it also returns the level of the logger which can be changed by
+// SetLevel while the logger is in use.
+func NewLeveledZapLogger(name string, config *Config) (*zap.Logger, zap.AtomicLevel, error) {
+	zapLvl := zap.NewAtomicLevelAt(parseLevel(config.Level))
+
 	// Return a nop logger if logger is not enabled.
 	if !config.Enabled {
-		return zap.NewNop(), nil
+		return zap.NewNop(), zapLvl, nil
 	}
 
 	// if Pretty flag in config is enabled use a ConsoleEncoder which is human readable.
@@ -25,7 +34,6 @@ func NewZapLogger(name string, config *Config) (*zap.Logger, error) {
 		encoder = zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
 	}
 
-	zapLvl := parseLevel(config.Level)
 	defaultCore := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(os.Stdout)), zapLvl)
 	cores := []zapcore.Core{defaultCore}
 
@@ -35,12 +43,12 @@ func NewZapLogger(name string, config *Config) (*zap.Logger, error) {
 		fileName := fmt.Sprintf("%s/%s.log", config.FileRedirectPath, config.FileRedirectPrefix)
 		basePath := filepath.Dir(fileName)
 		if _, err := os.Stat(basePath); os.IsNotExist(err) {
-			return nil, fmt.Errorf("base path `%s` is not exist, error: %v", basePath, err)
+			return nil, zapLvl, fmt.Errorf("base path `%s` is not exist, error: %v", basePath, err)
 		}
 		// Open the file.
 		file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0755)
 		if err != nil {
-			return nil, fmt.Errorf("error while openning file `%s` for logging, err: %v", fileName, err)
+			return nil, zapLvl, fmt.Errorf("error while openning file `%s` for logging, err: %v", fileName, err)
 		}
 
 		// Append new core to zap logger cores.
@@ -51,7 +59,12 @@ func NewZapLogger(name string, config *Config) (*zap.Logger, error) {
 	core := zapcore.NewTee(cores...)
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel)).Named(name)
 
-	return logger, nil
+	return logger, zapLvl, nil
+}
+
+// SetLevel changes the level of the loggers of level to the string based log level lvl.
+func SetLevel(level zap.AtomicLevel, lvl string) {
+	level.SetLevel(parseLevel(lvl))
 }
 
 // parseLevel will convert a string based log level to a zapcore.Level object.
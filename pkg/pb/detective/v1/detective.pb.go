@@ -0,0 +1,1154 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.17.3
+// source: detective/v1/detective.proto
+
+package detectivev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AnalyzeURLRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Absolute http or https url of the page.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+}
+
+func (x *AnalyzeURLRequest) Reset() {
+	*x = AnalyzeURLRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeURLRequest) ProtoMessage() {}
+
+func (x *AnalyzeURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeURLRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeURLRequest) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AnalyzeURLRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type AnalyzeHTMLRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The html document.
+	Html string `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	// Absolute url which the relative links of the document are resolved against.
+	BaseUrl string `protobuf:"bytes,2,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"`
+}
+
+func (x *AnalyzeHTMLRequest) Reset() {
+	*x = AnalyzeHTMLRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeHTMLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeHTMLRequest) ProtoMessage() {}
+
+func (x *AnalyzeHTMLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeHTMLRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeHTMLRequest) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AnalyzeHTMLRequest) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *AnalyzeHTMLRequest) GetBaseUrl() string {
+	if x != nil {
+		return x.BaseUrl
+	}
+	return ""
+}
+
+type AnalyzeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Id of the stored analysis when the analysis history is enabled.
+	AnalysisId string  `protobuf:"bytes,1,opt,name=analysis_id,json=analysisId,proto3" json:"analysis_id,omitempty"`
+	Result     *Result `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *AnalyzeResponse) Reset() {
+	*x = AnalyzeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeResponse) ProtoMessage() {}
+
+func (x *AnalyzeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeResponse) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AnalyzeResponse) GetAnalysisId() string {
+	if x != nil {
+		return x.AnalysisId
+	}
+	return ""
+}
+
+func (x *AnalyzeResponse) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type AnalyzeURLProgressResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*AnalyzeURLProgressResponse_FetchedUrl
+	//	*AnalyzeURLProgressResponse_LinkChecked
+	//	*AnalyzeURLProgressResponse_Result
+	Event isAnalyzeURLProgressResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *AnalyzeURLProgressResponse) Reset() {
+	*x = AnalyzeURLProgressResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeURLProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeURLProgressResponse) ProtoMessage() {}
+
+func (x *AnalyzeURLProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeURLProgressResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeURLProgressResponse) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *AnalyzeURLProgressResponse) GetEvent() isAnalyzeURLProgressResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *AnalyzeURLProgressResponse) GetFetchedUrl() string {
+	if x, ok := x.GetEvent().(*AnalyzeURLProgressResponse_FetchedUrl); ok {
+		return x.FetchedUrl
+	}
+	return ""
+}
+
+func (x *AnalyzeURLProgressResponse) GetLinkChecked() *LinkCheck {
+	if x, ok := x.GetEvent().(*AnalyzeURLProgressResponse_LinkChecked); ok {
+		return x.LinkChecked
+	}
+	return nil
+}
+
+func (x *AnalyzeURLProgressResponse) GetResult() *AnalyzeResponse {
+	if x, ok := x.GetEvent().(*AnalyzeURLProgressResponse_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+type isAnalyzeURLProgressResponse_Event interface {
+	isAnalyzeURLProgressResponse_Event()
+}
+
+type AnalyzeURLProgressResponse_FetchedUrl struct {
+	// Final url of the fetched page, it's sent once the page is fetched.
+	FetchedUrl string `protobuf:"bytes,1,opt,name=fetched_url,json=fetchedUrl,proto3,oneof"`
+}
+
+type AnalyzeURLProgressResponse_LinkChecked struct {
+	// Outcome of checking a link.
+	LinkChecked *LinkCheck `protobuf:"bytes,2,opt,name=link_checked,json=linkChecked,proto3,oneof"`
+}
+
+type AnalyzeURLProgressResponse_Result struct {
+	// Result of the analysis, it's the last message of the stream.
+	Result *AnalyzeResponse `protobuf:"bytes,3,opt,name=result,proto3,oneof"`
+}
+
+func (*AnalyzeURLProgressResponse_FetchedUrl) isAnalyzeURLProgressResponse_Event() {}
+
+func (*AnalyzeURLProgressResponse_LinkChecked) isAnalyzeURLProgressResponse_Event() {}
+
+func (*AnalyzeURLProgressResponse_Result) isAnalyzeURLProgressResponse_Event() {}
+
+type LinkCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url        string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Accessible bool   `protobuf:"varint,2,opt,name=accessible,proto3" json:"accessible,omitempty"`
+	// Skipped shows that the link has not been requested because of robots rules.
+	Skipped bool  `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Checked int32 `protobuf:"varint,4,opt,name=checked,proto3" json:"checked,omitempty"`
+	Total   int32 `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
+	// Category of the outcome of checking the link, e.g. accessible, timeout, tls_error or robots_skipped.
+	Outcome string `protobuf:"bytes,6,opt,name=outcome,proto3" json:"outcome,omitempty"`
+}
+
+func (x *LinkCheck) Reset() {
+	*x = LinkCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkCheck) ProtoMessage() {}
+
+func (x *LinkCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkCheck.ProtoReflect.Descriptor instead.
+func (*LinkCheck) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LinkCheck) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *LinkCheck) GetAccessible() bool {
+	if x != nil {
+		return x.Accessible
+	}
+	return false
+}
+
+func (x *LinkCheck) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *LinkCheck) GetChecked() int32 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *LinkCheck) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *LinkCheck) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+type HeadingsCount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	H1 int32 `protobuf:"varint,1,opt,name=h1,proto3" json:"h1,omitempty"`
+	H2 int32 `protobuf:"varint,2,opt,name=h2,proto3" json:"h2,omitempty"`
+	H3 int32 `protobuf:"varint,3,opt,name=h3,proto3" json:"h3,omitempty"`
+	H4 int32 `protobuf:"varint,4,opt,name=h4,proto3" json:"h4,omitempty"`
+	H5 int32 `protobuf:"varint,5,opt,name=h5,proto3" json:"h5,omitempty"`
+	H6 int32 `protobuf:"varint,6,opt,name=h6,proto3" json:"h6,omitempty"`
+}
+
+func (x *HeadingsCount) Reset() {
+	*x = HeadingsCount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HeadingsCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadingsCount) ProtoMessage() {}
+
+func (x *HeadingsCount) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadingsCount.ProtoReflect.Descriptor instead.
+func (*HeadingsCount) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeadingsCount) GetH1() int32 {
+	if x != nil {
+		return x.H1
+	}
+	return 0
+}
+
+func (x *HeadingsCount) GetH2() int32 {
+	if x != nil {
+		return x.H2
+	}
+	return 0
+}
+
+func (x *HeadingsCount) GetH3() int32 {
+	if x != nil {
+		return x.H3
+	}
+	return 0
+}
+
+func (x *HeadingsCount) GetH4() int32 {
+	if x != nil {
+		return x.H4
+	}
+	return 0
+}
+
+func (x *HeadingsCount) GetH5() int32 {
+	if x != nil {
+		return x.H5
+	}
+	return 0
+}
+
+func (x *HeadingsCount) GetH6() int32 {
+	if x != nil {
+		return x.H6
+	}
+	return 0
+}
+
+type LinksCount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Internal int32 `protobuf:"varint,1,opt,name=internal,proto3" json:"internal,omitempty"`
+	External int32 `protobuf:"varint,2,opt,name=external,proto3" json:"external,omitempty"`
+}
+
+func (x *LinksCount) Reset() {
+	*x = LinksCount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinksCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinksCount) ProtoMessage() {}
+
+func (x *LinksCount) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinksCount.ProtoReflect.Descriptor instead.
+func (*LinksCount) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LinksCount) GetInternal() int32 {
+	if x != nil {
+		return x.Internal
+	}
+	return 0
+}
+
+func (x *LinksCount) GetExternal() int32 {
+	if x != nil {
+		return x.External
+	}
+	return 0
+}
+
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HtmlVersion             string         `protobuf:"bytes,1,opt,name=html_version,json=htmlVersion,proto3" json:"html_version,omitempty"`
+	PageTitle               string         `protobuf:"bytes,2,opt,name=page_title,json=pageTitle,proto3" json:"page_title,omitempty"`
+	HeadingsCount           *HeadingsCount `protobuf:"bytes,3,opt,name=headings_count,json=headingsCount,proto3" json:"headings_count,omitempty"`
+	LinksCount              *LinksCount    `protobuf:"bytes,4,opt,name=links_count,json=linksCount,proto3" json:"links_count,omitempty"`
+	InaccessibleLinksCount  int32          `protobuf:"varint,5,opt,name=inaccessible_links_count,json=inaccessibleLinksCount,proto3" json:"inaccessible_links_count,omitempty"`
+	InaccessibleLinks       []string       `protobuf:"bytes,6,rep,name=inaccessible_links,json=inaccessibleLinks,proto3" json:"inaccessible_links,omitempty"`
+	RobotsSkippedLinksCount int32          `protobuf:"varint,7,opt,name=robots_skipped_links_count,json=robotsSkippedLinksCount,proto3" json:"robots_skipped_links_count,omitempty"`
+	HasLoginForm            bool           `protobuf:"varint,8,opt,name=has_login_form,json=hasLoginForm,proto3" json:"has_login_form,omitempty"`
+	CanonicalUrl            string         `protobuf:"bytes,9,opt,name=canonical_url,json=canonicalUrl,proto3" json:"canonical_url,omitempty"`
+	// Certificate health of the page, it's only set for https pages.
+	Tls *TLSReport `protobuf:"bytes,10,opt,name=tls,proto3" json:"tls,omitempty"`
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Result) GetHtmlVersion() string {
+	if x != nil {
+		return x.HtmlVersion
+	}
+	return ""
+}
+
+func (x *Result) GetPageTitle() string {
+	if x != nil {
+		return x.PageTitle
+	}
+	return ""
+}
+
+func (x *Result) GetHeadingsCount() *HeadingsCount {
+	if x != nil {
+		return x.HeadingsCount
+	}
+	return nil
+}
+
+func (x *Result) GetLinksCount() *LinksCount {
+	if x != nil {
+		return x.LinksCount
+	}
+	return nil
+}
+
+func (x *Result) GetInaccessibleLinksCount() int32 {
+	if x != nil {
+		return x.InaccessibleLinksCount
+	}
+	return 0
+}
+
+func (x *Result) GetInaccessibleLinks() []string {
+	if x != nil {
+		return x.InaccessibleLinks
+	}
+	return nil
+}
+
+func (x *Result) GetRobotsSkippedLinksCount() int32 {
+	if x != nil {
+		return x.RobotsSkippedLinksCount
+	}
+	return 0
+}
+
+func (x *Result) GetHasLoginForm() bool {
+	if x != nil {
+		return x.HasLoginForm
+	}
+	return false
+}
+
+func (x *Result) GetCanonicalUrl() string {
+	if x != nil {
+		return x.CanonicalUrl
+	}
+	return ""
+}
+
+func (x *Result) GetTls() *TLSReport {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+type Certificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Issuer  string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	// Subject alternative names of the certificate.
+	Sans      []string               `protobuf:"bytes,3,rep,name=sans,proto3" json:"sans,omitempty"`
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	// Number of the whole days until not_after, it's negative for the expired certificates.
+	DaysRemaining int32 `protobuf:"varint,6,opt,name=days_remaining,json=daysRemaining,proto3" json:"days_remaining,omitempty"`
+	SelfSigned    bool  `protobuf:"varint,7,opt,name=self_signed,json=selfSigned,proto3" json:"self_signed,omitempty"`
+}
+
+func (x *Certificate) Reset() {
+	*x = Certificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Certificate) ProtoMessage() {}
+
+func (x *Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Certificate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Certificate) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *Certificate) GetSans() []string {
+	if x != nil {
+		return x.Sans
+	}
+	return nil
+}
+
+func (x *Certificate) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *Certificate) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
+func (x *Certificate) GetDaysRemaining() int32 {
+	if x != nil {
+		return x.DaysRemaining
+	}
+	return 0
+}
+
+func (x *Certificate) GetSelfSigned() bool {
+	if x != nil {
+		return x.SelfSigned
+	}
+	return false
+}
+
+type TLSReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Negotiated protocol version of the connection, e.g. TLS 1.3.
+	Version     string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	CipherSuite string `protobuf:"bytes,2,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	// Leaf certificate of the page.
+	Certificate *Certificate `protobuf:"bytes,3,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	// Certificate chain which is presented by the server, leaf first.
+	Chain []*Certificate `protobuf:"bytes,4,rep,name=chain,proto3" json:"chain,omitempty"`
+	// Shows that the leaf certificate is not valid for the host of the page.
+	HostnameMismatch bool `protobuf:"varint,5,opt,name=hostname_mismatch,json=hostnameMismatch,proto3" json:"hostname_mismatch,omitempty"`
+}
+
+func (x *TLSReport) Reset() {
+	*x = TLSReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detective_v1_detective_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TLSReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSReport) ProtoMessage() {}
+
+func (x *TLSReport) ProtoReflect() protoreflect.Message {
+	mi := &file_detective_v1_detective_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSReport.ProtoReflect.Descriptor instead.
+func (*TLSReport) Descriptor() ([]byte, []int) {
+	return file_detective_v1_detective_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TLSReport) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TLSReport) GetCipherSuite() string {
+	if x != nil {
+		return x.CipherSuite
+	}
+	return ""
+}
+
+func (x *TLSReport) GetCertificate() *Certificate {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *TLSReport) GetChain() []*Certificate {
+	if x != nil {
+		return x.Chain
+	}
+	return nil
+}
+
+func (x *TLSReport) GetHostnameMismatch() bool {
+	if x != nil {
+		return x.HostnameMismatch
+	}
+	return false
+}
+
+var File_detective_v1_detective_proto protoreflect.FileDescriptor
+
+var file_detective_v1_detective_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x64,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x25, 0x0a,
+	0x11, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x75, 0x72, 0x6c, 0x22, 0x43, 0x0a, 0x12, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x48,
+	0x54, 0x4d, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x74,
+	0x6d, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x74, 0x6d, 0x6c, 0x12, 0x19,
+	0x0a, 0x08, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x62, 0x61, 0x73, 0x65, 0x55, 0x72, 0x6c, 0x22, 0x60, 0x0a, 0x0f, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x61, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x49, 0x64, 0x12, 0x2c, 0x0a,
+	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0xbf, 0x01, 0x0a, 0x1a,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66, 0x65,
+	0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x0a, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x12, 0x3c, 0x0a,
+	0x0c, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0b,
+	0x6c, 0x69, 0x6e, 0x6b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x37, 0x0a, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0xa1, 0x01,
+	0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x6b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x75,
+	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1e, 0x0a,
+	0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f,
+	0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d,
+	0x65, 0x22, 0x6f, 0x0a, 0x0d, 0x48, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x31, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x31, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x32, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x32, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x33, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x33, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x34, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x34, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x35, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x35, 0x12, 0x0e, 0x0a, 0x02, 0x68, 0x36, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x68, 0x36, 0x22, 0x44, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08,
+	0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x22, 0xe5, 0x03, 0x0a, 0x06, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x74, 0x6d, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x68, 0x74, 0x6d, 0x6c, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65,
+	0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x42, 0x0a, 0x0e, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x0d, 0x68, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0b, 0x6c, 0x69, 0x6e,
+	0x6b, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69,
+	0x6e, 0x6b, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x0a, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x38, 0x0a, 0x18, 0x69, 0x6e, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x69, 0x62, 0x6c, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x69, 0x6e, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x69, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2d,
+	0x0a, 0x12, 0x69, 0x6e, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x6c,
+	0x69, 0x6e, 0x6b, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x69, 0x6e, 0x61, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x3b, 0x0a,
+	0x1a, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x5f,
+	0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x17, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x68, 0x61,
+	0x73, 0x5f, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x68, 0x61, 0x73, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x46, 0x6f, 0x72, 0x6d,
+	0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x75, 0x72,
+	0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63,
+	0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x29, 0x0a, 0x03, 0x74, 0x6c, 0x73, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x4c, 0x53, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x03, 0x74, 0x6c, 0x73,
+	0x22, 0x8f, 0x02, 0x0a, 0x0b, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73,
+	0x73, 0x75, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x61, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x73, 0x61, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72,
+	0x65, 0x12, 0x37, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x08, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x61,
+	0x79, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0d, 0x64, 0x61, 0x79, 0x73, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e,
+	0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x6c, 0x66, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x65, 0x6c, 0x66, 0x53, 0x69, 0x67, 0x6e,
+	0x65, 0x64, 0x22, 0xe3, 0x01, 0x0a, 0x09, 0x54, 0x4c, 0x53, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x5f, 0x73, 0x75, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x53, 0x75, 0x69, 0x74, 0x65, 0x12, 0x3b, 0x0a,
+	0x0b, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x0b, 0x63,
+	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x05, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x64, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x68,
+	0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
+	0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x32, 0x92, 0x02, 0x0a, 0x0f, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x73, 0x69, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4c, 0x0a, 0x0a,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x12, 0x1f, 0x2e, 0x64, 0x65, 0x74,
+	0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a,
+	0x65, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0b, 0x41, 0x6e,
+	0x61, 0x6c, 0x79, 0x7a, 0x65, 0x48, 0x54, 0x4d, 0x4c, 0x12, 0x20, 0x2e, 0x64, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65,
+	0x48, 0x54, 0x4d, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x61, 0x0a, 0x12, 0x41, 0x6e,
+	0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x1f, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x28, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x55, 0x52, 0x4c, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x41, 0x5a,
+	0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x61, 0x6d, 0x6d,
+	0x61, 0x64, 0x6d, 0x6f, 0x64, 0x69, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76,
+	0x65, 0x2f, 0x76, 0x31, 0x3b, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_detective_v1_detective_proto_rawDescOnce sync.Once
+	file_detective_v1_detective_proto_rawDescData = file_detective_v1_detective_proto_rawDesc
+)
+
+func file_detective_v1_detective_proto_rawDescGZIP() []byte {
+	file_detective_v1_detective_proto_rawDescOnce.Do(func() {
+		file_detective_v1_detective_proto_rawDescData = protoimpl.X.CompressGZIP(file_detective_v1_detective_proto_rawDescData)
+	})
+	return file_detective_v1_detective_proto_rawDescData
+}
+
+var file_detective_v1_detective_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_detective_v1_detective_proto_goTypes = []interface{}{
+	(*AnalyzeURLRequest)(nil),          // 0: detective.v1.AnalyzeURLRequest
+	(*AnalyzeHTMLRequest)(nil),         // 1: detective.v1.AnalyzeHTMLRequest
+	(*AnalyzeResponse)(nil),            // 2: detective.v1.AnalyzeResponse
+	(*AnalyzeURLProgressResponse)(nil), // 3: detective.v1.AnalyzeURLProgressResponse
+	(*LinkCheck)(nil),                  // 4: detective.v1.LinkCheck
+	(*HeadingsCount)(nil),              // 5: detective.v1.HeadingsCount
+	(*LinksCount)(nil),                 // 6: detective.v1.LinksCount
+	(*Result)(nil),                     // 7: detective.v1.Result
+	(*Certificate)(nil),                // 8: detective.v1.Certificate
+	(*TLSReport)(nil),                  // 9: detective.v1.TLSReport
+	(*timestamppb.Timestamp)(nil),      // 10: google.protobuf.Timestamp
+}
+var file_detective_v1_detective_proto_depIdxs = []int32{
+	7,  // 0: detective.v1.AnalyzeResponse.result:type_name -> detective.v1.Result
+	4,  // 1: detective.v1.AnalyzeURLProgressResponse.link_checked:type_name -> detective.v1.LinkCheck
+	2,  // 2: detective.v1.AnalyzeURLProgressResponse.result:type_name -> detective.v1.AnalyzeResponse
+	5,  // 3: detective.v1.Result.headings_count:type_name -> detective.v1.HeadingsCount
+	6,  // 4: detective.v1.Result.links_count:type_name -> detective.v1.LinksCount
+	9,  // 5: detective.v1.Result.tls:type_name -> detective.v1.TLSReport
+	10, // 6: detective.v1.Certificate.not_before:type_name -> google.protobuf.Timestamp
+	10, // 7: detective.v1.Certificate.not_after:type_name -> google.protobuf.Timestamp
+	8,  // 8: detective.v1.TLSReport.certificate:type_name -> detective.v1.Certificate
+	8,  // 9: detective.v1.TLSReport.chain:type_name -> detective.v1.Certificate
+	0,  // 10: detective.v1.AnalysisService.AnalyzeURL:input_type -> detective.v1.AnalyzeURLRequest
+	1,  // 11: detective.v1.AnalysisService.AnalyzeHTML:input_type -> detective.v1.AnalyzeHTMLRequest
+	0,  // 12: detective.v1.AnalysisService.AnalyzeURLProgress:input_type -> detective.v1.AnalyzeURLRequest
+	2,  // 13: detective.v1.AnalysisService.AnalyzeURL:output_type -> detective.v1.AnalyzeResponse
+	2,  // 14: detective.v1.AnalysisService.AnalyzeHTML:output_type -> detective.v1.AnalyzeResponse
+	3,  // 15: detective.v1.AnalysisService.AnalyzeURLProgress:output_type -> detective.v1.AnalyzeURLProgressResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_detective_v1_detective_proto_init() }
+func file_detective_v1_detective_proto_init() {
+	if File_detective_v1_detective_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_detective_v1_detective_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeURLRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeHTMLRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeURLProgressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HeadingsCount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinksCount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Result); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Certificate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detective_v1_detective_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TLSReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_detective_v1_detective_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*AnalyzeURLProgressResponse_FetchedUrl)(nil),
+		(*AnalyzeURLProgressResponse_LinkChecked)(nil),
+		(*AnalyzeURLProgressResponse_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_detective_v1_detective_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_detective_v1_detective_proto_goTypes,
+		DependencyIndexes: file_detective_v1_detective_proto_depIdxs,
+		MessageInfos:      file_detective_v1_detective_proto_msgTypes,
+	}.Build()
+	File_detective_v1_detective_proto = out.File
+	file_detective_v1_detective_proto_rawDesc = nil
+	file_detective_v1_detective_proto_goTypes = nil
+	file_detective_v1_detective_proto_depIdxs = nil
+}
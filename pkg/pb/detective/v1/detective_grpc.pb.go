@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.17.3
+// source: detective/v1/detective.proto
+
+package detectivev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AnalysisServiceClient is the client API for AnalysisService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AnalysisServiceClient interface {
+	// AnalyzeURL fetches the html document of a url and analyzes it.
+	AnalyzeURL(ctx context.Context, in *AnalyzeURLRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error)
+	// AnalyzeHTML analyzes an html document which is sent by the client.
+	AnalyzeHTML(ctx context.Context, in *AnalyzeHTMLRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error)
+	// AnalyzeURLProgress analyzes a url like AnalyzeURL and streams the outcome of checking each link,
+	// the last message of the stream holds the result.
+	AnalyzeURLProgress(ctx context.Context, in *AnalyzeURLRequest, opts ...grpc.CallOption) (AnalysisService_AnalyzeURLProgressClient, error)
+}
+
+type analysisServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAnalysisServiceClient(cc grpc.ClientConnInterface) AnalysisServiceClient {
+	return &analysisServiceClient{cc}
+}
+
+func (c *analysisServiceClient) AnalyzeURL(ctx context.Context, in *AnalyzeURLRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error) {
+	out := new(AnalyzeResponse)
+	err := c.cc.Invoke(ctx, "/detective.v1.AnalysisService/AnalyzeURL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analysisServiceClient) AnalyzeHTML(ctx context.Context, in *AnalyzeHTMLRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error) {
+	out := new(AnalyzeResponse)
+	err := c.cc.Invoke(ctx, "/detective.v1.AnalysisService/AnalyzeHTML", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analysisServiceClient) AnalyzeURLProgress(ctx context.Context, in *AnalyzeURLRequest, opts ...grpc.CallOption) (AnalysisService_AnalyzeURLProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AnalysisService_ServiceDesc.Streams[0], "/detective.v1.AnalysisService/AnalyzeURLProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &analysisServiceAnalyzeURLProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AnalysisService_AnalyzeURLProgressClient interface {
+	Recv() (*AnalyzeURLProgressResponse, error)
+	grpc.ClientStream
+}
+
+type analysisServiceAnalyzeURLProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *analysisServiceAnalyzeURLProgressClient) Recv() (*AnalyzeURLProgressResponse, error) {
+	m := new(AnalyzeURLProgressResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AnalysisServiceServer is the server API for AnalysisService service.
+// All implementations must embed UnimplementedAnalysisServiceServer
+// for forward compatibility
+type AnalysisServiceServer interface {
+	// AnalyzeURL fetches the html document of a url and analyzes it.
+	AnalyzeURL(context.Context, *AnalyzeURLRequest) (*AnalyzeResponse, error)
+	// AnalyzeHTML analyzes an html document which is sent by the client.
+	AnalyzeHTML(context.Context, *AnalyzeHTMLRequest) (*AnalyzeResponse, error)
+	// AnalyzeURLProgress analyzes a url like AnalyzeURL and streams the outcome of checking each link,
+	// the last message of the stream holds the result.
+	AnalyzeURLProgress(*AnalyzeURLRequest, AnalysisService_AnalyzeURLProgressServer) error
+	mustEmbedUnimplementedAnalysisServiceServer()
+}
+
+// UnimplementedAnalysisServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAnalysisServiceServer struct {
+}
+
+func (UnimplementedAnalysisServiceServer) AnalyzeURL(context.Context, *AnalyzeURLRequest) (*AnalyzeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeURL not implemented")
+}
+func (UnimplementedAnalysisServiceServer) AnalyzeHTML(context.Context, *AnalyzeHTMLRequest) (*AnalyzeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeHTML not implemented")
+}
+func (UnimplementedAnalysisServiceServer) AnalyzeURLProgress(*AnalyzeURLRequest, AnalysisService_AnalyzeURLProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method AnalyzeURLProgress not implemented")
+}
+func (UnimplementedAnalysisServiceServer) mustEmbedUnimplementedAnalysisServiceServer() {}
+
+// UnsafeAnalysisServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AnalysisServiceServer will
+// result in compilation errors.
+type UnsafeAnalysisServiceServer interface {
+	mustEmbedUnimplementedAnalysisServiceServer()
+}
+
+func RegisterAnalysisServiceServer(s grpc.ServiceRegistrar, srv AnalysisServiceServer) {
+	s.RegisterService(&AnalysisService_ServiceDesc, srv)
+}
+
+func _AnalysisService_AnalyzeURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).AnalyzeURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/detective.v1.AnalysisService/AnalyzeURL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).AnalyzeURL(ctx, req.(*AnalyzeURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalysisService_AnalyzeHTML_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeHTMLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalysisServiceServer).AnalyzeHTML(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/detective.v1.AnalysisService/AnalyzeHTML",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalysisServiceServer).AnalyzeHTML(ctx, req.(*AnalyzeHTMLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalysisService_AnalyzeURLProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AnalyzeURLRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AnalysisServiceServer).AnalyzeURLProgress(m, &analysisServiceAnalyzeURLProgressServer{stream})
+}
+
+type AnalysisService_AnalyzeURLProgressServer interface {
+	Send(*AnalyzeURLProgressResponse) error
+	grpc.ServerStream
+}
+
+type analysisServiceAnalyzeURLProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *analysisServiceAnalyzeURLProgressServer) Send(m *AnalyzeURLProgressResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AnalysisService_ServiceDesc is the grpc.ServiceDesc for AnalysisService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AnalysisService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "detective.v1.AnalysisService",
+	HandlerType: (*AnalysisServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeURL",
+			Handler:    _AnalysisService_AnalyzeURL_Handler,
+		},
+		{
+			MethodName: "AnalyzeHTML",
+			Handler:    _AnalysisService_AnalyzeHTML_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AnalyzeURLProgress",
+			Handler:       _AnalysisService_AnalyzeURLProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "detective/v1/detective.proto",
+}
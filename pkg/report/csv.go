@@ -0,0 +1,77 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the header of the csv reports which have a row per page.
+var csvHeader = []string{
+	"url", "error", "html_version", "page_title",
+	"h1", "h2", "h3", "h4", "h5", "h6",
+	"internal_links", "external_links", "inaccessible_links", "robots_skipped_links",
+	"has_login_form", "canonical_url", "passed",
+}
+
+// csvLinksHeader is the header of the csv reports which have a row per link.
+var csvLinksHeader = []string{"page_url", "link_url", "status"}
+
+// csvReporter writes a row per page, or a row per inaccessible link of the pages if links is true.
+type csvReporter struct {
+	links bool
+}
+
+func (r csvReporter) ContentType() string {
+	if r.links {
+		return "text/csv; charset=utf-8; rows=links"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (r csvReporter) Write(w io.Writer, pages []*Page) error {
+	cw := csv.NewWriter(w)
+	if r.links {
+		_ = cw.Write(csvLinksHeader)
+		for _, p := range pages {
+			if p.Result == nil {
+				continue
+			}
+			for _, l := range p.Result.InaccessibleLinks {
+				_ = cw.Write([]string{p.URL, l, "inaccessible"})
+			}
+		}
+	} else {
+		_ = cw.Write(csvHeader)
+		for _, p := range pages {
+			_ = cw.Write(pageRow(p))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// pageRow returns the columns of p in the order of csvHeader.
+func pageRow(p *Page) []string {
+	row := make([]string, len(csvHeader))
+	row[0], row[1] = p.URL, p.Error
+	row[len(row)-1] = strconv.FormatBool(p.Passed())
+	r := p.Result
+	if r == nil {
+		return row
+	}
+	row[2], row[3] = r.HTMLVersion, r.PageTitle
+	if hc := r.HeadingsCount; hc != nil {
+		for i, n := range []int{hc.H1, hc.H2, hc.H3, hc.H4, hc.H5, hc.H6} {
+			row[4+i] = strconv.Itoa(n)
+		}
+	}
+	if lc := r.LinksCount; lc != nil {
+		row[10], row[11] = strconv.Itoa(lc.Internal), strconv.Itoa(lc.External)
+	}
+	row[12] = strconv.Itoa(r.InaccessibleLinksCount)
+	row[13] = strconv.Itoa(r.RobotsSkippedLinksCount)
+	row[14] = strconv.FormatBool(r.HasLoginForm)
+	row[15] = r.CanonicalURL
+	return row
+}
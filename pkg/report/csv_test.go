@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVReporter(t *testing.T) {
+	rows, err := csv.NewReader(strings.NewReader(write(t, FormatCSV, testPages()))).ReadAll()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, [][]string{
+		csvHeader,
+		{
+			"https://example.com/", "", "HTML 5", "Example", "1", "2", "0", "0", "0", "0",
+			"3", "1", "0", "0", "false", "https://example.com/", "true",
+		},
+		{
+			"https://example.com/blog", "", "Unknown HTML Version", "Blog | <Example>", "0", "0", "0", "0", "0", "0",
+			"2", "0", "2", "0", "true", "", "false",
+		},
+		{
+			"https://example.com/missing", "url responded with status code 404", "", "", "", "", "", "", "", "",
+			"", "", "", "", "", "", "false",
+		},
+	}, rows)
+}
+
+func TestCSVReporterLinks(t *testing.T) {
+	rows, err := csv.NewReader(strings.NewReader(write(t, FormatCSVLinks, testPages()))).ReadAll()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, [][]string{
+		csvLinksHeader,
+		{"https://example.com/blog", "https://example.com/a", "inaccessible"},
+		{"https://example.com/blog", "https://example.com/b", "inaccessible"},
+	}, rows)
+}
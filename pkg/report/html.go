@@ -0,0 +1,58 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlTemplate is the template of the html reports, the styles are inlined so the report is self-contained.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"metrics": metrics,
+	"verdict": verdict,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Detective Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+.pass { color: #1a7f37; }
+.fail { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Detective Report</h1>
+<p>{{.Passed}} of {{len .Pages}} pages passed all the checks.</p>
+{{range .Pages}}
+<section>
+<h2>{{.URL}}</h2>
+{{if .Error}}<p class="fail">Analysis failed: {{.Error}}</p>{{end}}
+{{with .Result}}
+<table>
+{{range metrics .}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}
+<ul>
+{{range .Checks}}<li class="{{if .Passed}}pass{{else}}fail{{end}}">{{verdict .Passed}} <code>{{.Name}}</code>: {{.Message}}</li>
+{{end}}</ul>
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// htmlReporter writes a self-contained html page.
+type htmlReporter struct{}
+
+func (htmlReporter) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+func (htmlReporter) Write(w io.Writer, pages []*Page) error {
+	return htmlTemplate.Execute(w, struct {
+		Pages  []*Page
+		Passed int
+	}{Pages: pages, Passed: passedPages(pages)})
+}
@@ -0,0 +1,21 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	page := write(t, FormatHTML, testPages())
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Contains(t, page, "<style>")
+	assert.Contains(t, page, "<p>1 of 3 pages passed all the checks.</p>")
+	assert.Contains(t, page, "<h2>https://example.com/blog</h2>")
+	assert.Contains(t, page, "<tr><th>Page Title</th><td>Blog | &lt;Example&gt;</td></tr>")
+	assert.Contains(t, page, `<li class="fail">FAIL <code>headings</code>: page has no h1 heading</li>`)
+	assert.Contains(t, page, `<p class="fail">Analysis failed: url responded with status code 404</p>`)
+	assert.NotContains(t, page, "<Example>")
+}
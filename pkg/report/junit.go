@@ -0,0 +1,75 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// junitTestSuites is the root element of JUnit XML reports.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of a page.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a check of a page.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure is the failure of a test case.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// junitReporter writes a test suite per page with a test case per check, so CI dashboards show the checks as tests.
+type junitReporter struct{}
+
+func (junitReporter) ContentType() string {
+	return "application/junit+xml; charset=utf-8"
+}
+
+func (junitReporter) Write(w io.Writer, pages []*Page) error {
+	root := &junitTestSuites{Name: "detective"}
+	for _, p := range pages {
+		suite := junitTestSuite{Name: p.URL, Tests: len(p.Checks)}
+		for _, c := range p.Checks {
+			tc := junitTestCase{Name: c.Name, ClassName: p.URL}
+			if c.Passed {
+				tc.SystemOut = c.Message
+			} else {
+				tc.Failure = &junitFailure{Message: c.Message, Type: c.Name}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Suites = append(root.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
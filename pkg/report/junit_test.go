@@ -0,0 +1,36 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	doc := write(t, FormatJUnit, testPages())
+	assert.True(t, strings.HasPrefix(doc, xml.Header))
+
+	var root junitTestSuites
+	if err := xml.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatalf("error while decoding report: %v", err)
+	}
+	assert.Equal(t, "detective", root.Name)
+	assert.Equal(t, 11, root.Tests)
+	assert.Equal(t, 5, root.Failures)
+	if !assert.Len(t, root.Suites, 3) {
+		return
+	}
+
+	blog := root.Suites[1]
+	assert.Equal(t, "https://example.com/blog", blog.Name)
+	assert.Equal(t, 5, blog.Tests)
+	assert.Equal(t, 4, blog.Failures)
+	assert.Equal(t, junitTestCase{
+		Name:      CheckTitle,
+		ClassName: "https://example.com/blog",
+		SystemOut: "page title is `Blog | <Example>`",
+	}, blog.Cases[1])
+	assert.Equal(t, &junitFailure{Message: "page has no h1 heading", Type: CheckHeadings}, blog.Cases[2].Failure)
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownReporter writes a section per page with a table of its result and the list of its checks.
+type markdownReporter struct{}
+
+func (markdownReporter) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+func (markdownReporter) Write(w io.Writer, pages []*Page) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Detective Report\n\n%d of %d pages passed all the checks.\n", passedPages(pages), len(pages))
+
+	for _, p := range pages {
+		fmt.Fprintf(bw, "\n## %s\n\n", markdownEscape(p.URL))
+		if p.Error != "" {
+			fmt.Fprintf(bw, "Analysis failed: %s\n\n", markdownEscape(p.Error))
+		}
+		if r := p.Result; r != nil {
+			bw.WriteString("| **Metric** | **Value** |\n| ---------- | --------- |\n")
+			for _, m := range metrics(r) {
+				fmt.Fprintf(bw, "| %s | %s |\n", m.Name, markdownEscape(m.Value))
+			}
+			bw.WriteString("\n")
+		}
+		for _, c := range p.Checks {
+			fmt.Fprintf(bw, "- %s `%s`: %s\n", verdict(c.Passed), c.Name, markdownEscape(c.Message))
+		}
+	}
+	return bw.Flush()
+}
+
+// verdict returns the label of the outcome of a check.
+func verdict(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// markdownEscaper escapes the characters which break the tables and the inline formatting of markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "\n", " ",
+)
+
+// markdownEscape escapes s to be written as markdown text.
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
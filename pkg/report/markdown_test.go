@@ -0,0 +1,19 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownReporter(t *testing.T) {
+	md := write(t, FormatMarkdown, testPages())
+
+	assert.Contains(t, md, "# Detective Report\n\n1 of 3 pages passed all the checks.\n")
+	assert.Contains(t, md, "\n## https://example.com/blog\n\n")
+	assert.Contains(t, md, "| Page Title | Blog \\| &lt;Example&gt; |\n")
+	assert.Contains(t, md, "- PASS `title`: page title is \\`Example\\`\n")
+	assert.Contains(t, md, "- FAIL `headings`: page has no h1 heading\n")
+	assert.Contains(t, md, "Analysis failed: url responded with status code 404\n")
+	assert.Contains(t, md, "- FAIL `analysis`: url responded with status code 404\n")
+}
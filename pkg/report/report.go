@@ -0,0 +1,253 @@
+// Package report writes the results of analyses in different formats, e.g. for humans, spreadsheets and CI systems.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+)
+
+// Check is the verdict of a check on a page.
+// Name identifies the check and Message describes its outcome.
+type Check struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Page is the analysis of a page which is reported.
+// Error is the reason of the failure of the analysis, Result is nil when it's not empty.
+type Page struct {
+	URL    string               `json:"url"`
+	Result *htmlanalysis.Result `json:"result"`
+	Error  string               `json:"error,omitempty"`
+	Checks []Check              `json:"checks"`
+}
+
+// NewPage creates a Page of the analysis of u with the default checks of r, errMsg is the reason of the failure of
+// the analysis if it has failed.
+func NewPage(u string, r *htmlanalysis.Result, errMsg string) *Page {
+	p := &Page{URL: u, Result: r, Error: errMsg}
+	if errMsg != "" || r == nil {
+		if errMsg == "" {
+			errMsg = "page has not been analyzed"
+		}
+		p.Checks = []Check{{Name: CheckAnalysis, Passed: false, Message: errMsg}}
+		return p
+	}
+	p.Checks = DefaultChecks(r)
+	return p
+}
+
+// Passed returns true if all the checks of p have passed.
+func (p *Page) Passed() bool {
+	for _, c := range p.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// List of the names of the default checks.
+const (
+	CheckAnalysis          = "analysis"
+	CheckHTMLVersion       = "html_version"
+	CheckTitle             = "title"
+	CheckHeadings          = "headings"
+	CheckInaccessibleLinks = "inaccessible_links"
+	CheckCanonicalURL      = "canonical_url"
+)
+
+// DefaultChecks returns the checks which every page is expected to pass: a known html version, a title, an h1
+// heading, no inaccessible links and a canonical link.
+func DefaultChecks(r *htmlanalysis.Result) []Check {
+	checks := []Check{
+		NewCheck(CheckHTMLVersion, r.HTMLVersion != htmlanalysis.UnknownHTMLVersion && r.HTMLVersion != "",
+			"page is "+r.HTMLVersion, "page has no known doctype"),
+		NewCheck(CheckTitle, r.PageTitle != htmlanalysis.EmptyPageTitle && r.PageTitle != "",
+			"page title is `"+r.PageTitle+"`", "page has no title"),
+	}
+
+	h1 := 0
+	if r.HeadingsCount != nil {
+		h1 = r.HeadingsCount.H1
+	}
+	checks = append(checks,
+		NewCheck(CheckHeadings, h1 > 0, fmt.Sprintf("page has %d h1 headings", h1), "page has no h1 heading"),
+		NewCheck(CheckInaccessibleLinks, r.InaccessibleLinksCount == 0,
+			"all the links of the page are accessible",
+			fmt.Sprintf("page has %d inaccessible links: %s", r.InaccessibleLinksCount, strings.Join(r.InaccessibleLinks, ", "))),
+		NewCheck(CheckCanonicalURL, r.CanonicalURL != "", "canonical url is "+r.CanonicalURL, "page has no canonical url"),
+	)
+	return checks
+}
+
+// NewCheck creates a Check whose message is passMsg if it has passed and failMsg otherwise.
+func NewCheck(name string, passed bool, passMsg, failMsg string) Check {
+	if passed {
+		return Check{Name: name, Passed: true, Message: passMsg}
+	}
+	return Check{Name: name, Passed: false, Message: failMsg}
+}
+
+// Reporter writes a report of pages in a format.
+type Reporter interface {
+	// ContentType returns the media type of the reports.
+	ContentType() string
+	// Write writes the report of pages to w.
+	Write(w io.Writer, pages []*Page) error
+}
+
+// Format is the name of a report format.
+type Format string
+
+// List of available report formats.
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatCSVLinks Format = "csv-links"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatJUnit    Format = "junit"
+	FormatSARIF    Format = "sarif"
+)
+
+// Formats is the list of all the report formats.
+var Formats = []Format{FormatJSON, FormatCSV, FormatCSVLinks, FormatMarkdown, FormatHTML, FormatJUnit, FormatSARIF}
+
+// New returns the Reporter of f.
+func New(f Format) (Reporter, error) {
+	switch f {
+	case FormatJSON:
+		return jsonReporter{}, nil
+	case FormatCSV:
+		return csvReporter{}, nil
+	case FormatCSVLinks:
+		return csvReporter{links: true}, nil
+	case FormatMarkdown:
+		return markdownReporter{}, nil
+	case FormatHTML:
+		return htmlReporter{}, nil
+	case FormatJUnit:
+		return junitReporter{}, nil
+	case FormatSARIF:
+		return sarifReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format `%s`", f)
+	}
+}
+
+// mediaTypes maps the media types which are accepted by Negotiate to the report formats.
+var mediaTypes = map[string]Format{
+	"application/json":       FormatJSON,
+	"text/csv":               FormatCSV,
+	"text/markdown":          FormatMarkdown,
+	"text/html":              FormatHTML,
+	"application/junit+xml":  FormatJUnit,
+	"application/xml":        FormatJUnit,
+	"text/xml":               FormatJUnit,
+	"application/sarif+json": FormatSARIF,
+}
+
+// Negotiate returns the report format which is preferred by the Accept header accept. The csv rows are links
+// instead of pages when the `rows=links` parameter is given, e.g. `text/csv; rows=links`.
+// It returns FormatJSON when accept is empty, accepts any type or doesn't accept any of the formats.
+func Negotiate(accept string) Format {
+	type candidate struct {
+		format Format
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if mt == "*/*" || mt == "application/*" {
+			candidates = append(candidates, candidate{format: FormatJSON, q: q})
+			continue
+		}
+		f, ok := mediaTypes[mt]
+		if !ok {
+			continue
+		}
+		if f == FormatCSV && params["rows"] == "links" {
+			f = FormatCSVLinks
+		}
+		candidates = append(candidates, candidate{format: f, q: q})
+	}
+	if len(candidates) == 0 {
+		return FormatJSON
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].format
+}
+
+// jsonReporter writes the pages as a json document.
+type jsonReporter struct{}
+
+func (jsonReporter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (jsonReporter) Write(w io.Writer, pages []*Page) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(struct {
+		Pages []*Page `json:"pages"`
+	}{Pages: pages})
+}
+
+// passedPages returns the number of pages which have passed all their checks.
+func passedPages(pages []*Page) int {
+	passed := 0
+	for _, p := range pages {
+		if p.Passed() {
+			passed++
+		}
+	}
+	return passed
+}
+
+// metric is a named value of a result which is shown by the human readable reports.
+type metric struct {
+	Name, Value string
+}
+
+// metrics returns the metrics of r in the order which they are shown.
+func metrics(r *htmlanalysis.Result) []metric {
+	ms := []metric{
+		{Name: "HTML Version", Value: r.HTMLVersion},
+		{Name: "Page Title", Value: r.PageTitle},
+	}
+	if hc := r.HeadingsCount; hc != nil {
+		ms = append(ms, metric{Name: "Headings", Value: fmt.Sprintf(
+			"h1: %d, h2: %d, h3: %d, h4: %d, h5: %d, h6: %d", hc.H1, hc.H2, hc.H3, hc.H4, hc.H5, hc.H6,
+		)})
+	}
+	if lc := r.LinksCount; lc != nil {
+		ms = append(ms, metric{Name: "Links", Value: fmt.Sprintf("internal: %d, external: %d", lc.Internal, lc.External)})
+	}
+	return append(ms,
+		metric{Name: "Inaccessible Links", Value: strconv.Itoa(r.InaccessibleLinksCount)},
+		metric{Name: "Robots Skipped Links", Value: strconv.Itoa(r.RobotsSkippedLinksCount)},
+		metric{Name: "Has Login Form", Value: strconv.FormatBool(r.HasLoginForm)},
+		metric{Name: "Canonical URL", Value: r.CanonicalURL},
+	)
+}
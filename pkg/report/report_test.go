@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPages returns a page which passes all the default checks, a page which fails some of them and a page whose
+// analysis has failed.
+func testPages() []*Page {
+	return []*Page{
+		NewPage("https://example.com/", &htmlanalysis.Result{
+			HTMLVersion:   "HTML 5",
+			PageTitle:     "Example",
+			HeadingsCount: &htmlanalysis.HeadingsCount{H1: 1, H2: 2},
+			LinksCount:    &htmlanalysis.LinksCount{Internal: 3, External: 1},
+			CanonicalURL:  "https://example.com/",
+		}, ""),
+		NewPage("https://example.com/blog", &htmlanalysis.Result{
+			HTMLVersion:            htmlanalysis.UnknownHTMLVersion,
+			PageTitle:              "Blog | <Example>",
+			HeadingsCount:          &htmlanalysis.HeadingsCount{},
+			LinksCount:             &htmlanalysis.LinksCount{Internal: 2},
+			InaccessibleLinksCount: 2,
+			InaccessibleLinks:      []string{"https://example.com/a", "https://example.com/b"},
+			HasLoginForm:           true,
+		}, ""),
+		NewPage("https://example.com/missing", nil, "url responded with status code 404"),
+	}
+}
+
+// write writes pages with the reporter of f.
+func write(t *testing.T, f Format, pages []*Page) string {
+	r, err := New(f)
+	if err != nil {
+		t.Fatalf("error while creating reporter: %v", err)
+	}
+	var b bytes.Buffer
+	if err := r.Write(&b, pages); err != nil {
+		t.Fatalf("error while writing report: %v", err)
+	}
+	return b.String()
+}
+
+func TestNewPage(t *testing.T) {
+	pages := testPages()
+
+	assert.True(t, pages[0].Passed())
+	assert.Len(t, pages[0].Checks, 5)
+
+	assert.False(t, pages[1].Passed())
+	failed := map[string]string{}
+	for _, c := range pages[1].Checks {
+		if !c.Passed {
+			failed[c.Name] = c.Message
+		}
+	}
+	assert.Equal(t, map[string]string{
+		CheckHTMLVersion:       "page has no known doctype",
+		CheckHeadings:          "page has no h1 heading",
+		CheckInaccessibleLinks: "page has 2 inaccessible links: https://example.com/a, https://example.com/b",
+		CheckCanonicalURL:      "page has no canonical url",
+	}, failed)
+
+	assert.Equal(t, []Check{{Name: CheckAnalysis, Message: "url responded with status code 404"}}, pages[2].Checks)
+}
+
+func TestNew(t *testing.T) {
+	for _, f := range Formats {
+		r, err := New(f)
+		if assert.NoError(t, err, f) {
+			assert.NotEmpty(t, r.ContentType(), f)
+		}
+	}
+	_, err := New("pdf")
+	assert.EqualError(t, err, "unknown report format `pdf`")
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept         string
+		expectedFormat Format
+	}{
+		{accept: "", expectedFormat: FormatJSON},
+		{accept: "*/*", expectedFormat: FormatJSON},
+		{accept: "application/json", expectedFormat: FormatJSON},
+		{accept: "text/plain", expectedFormat: FormatJSON},
+		{accept: "text/csv", expectedFormat: FormatCSV},
+		{accept: "text/csv; rows=links", expectedFormat: FormatCSVLinks},
+		{accept: "text/markdown", expectedFormat: FormatMarkdown},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expectedFormat: FormatHTML},
+		{accept: "application/junit+xml", expectedFormat: FormatJUnit},
+		{accept: "application/json;q=0.5, application/sarif+json", expectedFormat: FormatSARIF},
+		{accept: "text/markdown;q=0, text/csv;q=0.1", expectedFormat: FormatCSV},
+	}
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			assert.Equal(t, test.expectedFormat, Negotiate(test.accept))
+		})
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var decoded struct {
+		Pages []*Page `json:"pages"`
+	}
+	if err := json.Unmarshal([]byte(write(t, FormatJSON, testPages())), &decoded); err != nil {
+		t.Fatalf("error while decoding report: %v", err)
+	}
+	assert.Equal(t, testPages(), decoded.Pages)
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// List of the constants of SARIF reports.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/mammadmodi/detective"
+)
+
+// sarifLog is the root object of SARIF reports, only the properties which are used are defined.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifReporter writes a SARIF log whose results are the failed checks of the pages, each check is a rule and each
+// page is the location of its results.
+type sarifReporter struct{}
+
+func (sarifReporter) ContentType() string {
+	return "application/sarif+json; charset=utf-8"
+}
+
+func (sarifReporter) Write(w io.Writer, pages []*Page) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "detective", InformationURI: sarifToolURI, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := make(map[string]int)
+	for _, p := range pages {
+		for _, c := range p.Checks {
+			i, ok := ruleIndexes[c.Name]
+			if !ok {
+				i = len(run.Tool.Driver.Rules)
+				ruleIndexes[c.Name] = i
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: c.Name})
+			}
+			if c.Passed {
+				continue
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    c.Name,
+				RuleIndex: i,
+				Level:     "error",
+				Message:   sarifMessage{Text: c.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: p.URL}},
+				}},
+			})
+		}
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(&sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
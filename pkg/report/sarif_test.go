@@ -0,0 +1,46 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSARIFReporter(t *testing.T) {
+	var log sarifLog
+	if err := json.Unmarshal([]byte(write(t, FormatSARIF, testPages())), &log); err != nil {
+		t.Fatalf("error while decoding report: %v", err)
+	}
+	assert.Equal(t, sarifVersion, log.Version)
+	if !assert.Len(t, log.Runs, 1) {
+		return
+	}
+
+	run := log.Runs[0]
+	assert.Equal(t, "detective", run.Tool.Driver.Name)
+	assert.Equal(t, []sarifRule{
+		{ID: CheckHTMLVersion},
+		{ID: CheckTitle},
+		{ID: CheckHeadings},
+		{ID: CheckInaccessibleLinks},
+		{ID: CheckCanonicalURL},
+		{ID: CheckAnalysis},
+	}, run.Tool.Driver.Rules)
+	if !assert.Len(t, run.Results, 5) {
+		return
+	}
+	assert.Equal(t, sarifResult{
+		RuleID:    CheckHeadings,
+		RuleIndex: 2,
+		Level:     "error",
+		Message:   sarifMessage{Text: "page has no h1 heading"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "https://example.com/blog"},
+			},
+		}},
+	}, run.Results[1])
+	assert.Equal(t, CheckAnalysis, run.Results[4].RuleID)
+	assert.Equal(t, 5, run.Results[4].RuleIndex)
+}
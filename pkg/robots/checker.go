@@ -0,0 +1,271 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDisallowed is returned when a url is disallowed by the robots.txt of its host.
+	ErrDisallowed = errors.New("url is disallowed by robots.txt")
+	// ErrCrawlDelayExceeded is returned when the crawl delay of the host of a url is longer than the accepted delay.
+	ErrCrawlDelayExceeded = errors.New("crawl delay of robots.txt is longer than the accepted delay")
+)
+
+const (
+	// maxRobotsSize is the maximum size of robots.txt documents which are parsed, the rest of them are ignored.
+	maxRobotsSize = 512 * 1024
+	// errorTTL is the longest time which a robots.txt that could not be fetched is cached, so the hosts which fail
+	// temporarily are retried soon without fetching their robots.txt for each of their urls.
+	errorTTL = 10 * time.Second
+	// pruneInterval is the shortest time between two removals of the expired entries of the cache.
+	pruneInterval = time.Minute
+)
+
+// entry is a cached robots.txt of a host.
+type entry struct {
+	robots    *Robots
+	expiresAt time.Time
+	// lastAccess is the last time that a url of the host has been allowed by Wait.
+	lastAccess time.Time
+}
+
+// hostLock serializes fetching the robots.txt of a host, refs is the number of lookups which use the lock so it can
+// be removed when it's not used anymore.
+type hostLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Checker fetches and caches the robots.txt of hosts and evaluates their rules for a user agent.
+type Checker struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// hostLocks serializes fetching robots.txt of each host.
+	hostLocks map[string]*hostLock
+	lastPrune time.Time
+
+	cacheHits   uint64
+	cacheMisses uint64
+}
+
+// CacheStats is the number of lookups of the robots.txt cache which have been served from the cache (Hits)
+// or have fetched the robots.txt (Misses).
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// NewChecker creates a Checker which fetches robots.txt documents by client and caches them for ttl.
+func NewChecker(client *http.Client, userAgent string, ttl time.Duration, logger *zap.Logger) *Checker {
+	return &Checker{
+		client:    client,
+		userAgent: userAgent,
+		ttl:       ttl,
+		logger:    logger,
+		entries:   map[string]*entry{},
+		hostLocks: map[string]*hostLock{},
+	}
+}
+
+// UserAgent returns the user agent which the rules are evaluated for.
+func (c *Checker) UserAgent() string {
+	return c.userAgent
+}
+
+// CacheStats returns the number of hits and misses of the robots.txt cache so far.
+func (c *Checker) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.cacheHits),
+		Misses: atomic.LoadUint64(&c.cacheMisses),
+	}
+}
+
+// Allowed reports whether the user agent of the checker is allowed to fetch u.
+func (c *Checker) Allowed(ctx context.Context, u *url.URL) bool {
+	r := c.robots(ctx, u)
+	return r.Allowed(c.userAgent, u.RequestURI())
+}
+
+// Wait blocks until the crawl delay of the host of u has passed since the previous call for the same host.
+// It returns ErrDisallowed without waiting if u is not allowed.
+func (c *Checker) Wait(ctx context.Context, u *url.URL) error {
+	return c.WaitAtMost(ctx, u, 0)
+}
+
+// WaitAtMost is like Wait but it returns ErrCrawlDelayExceeded without waiting if the crawl delay of the host of u
+// is longer than maxDelay, a maxDelay less than 1 accepts any crawl delay.
+func (c *Checker) WaitAtMost(ctx context.Context, u *url.URL, maxDelay time.Duration) error {
+	r := c.robots(ctx, u)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !r.Allowed(c.userAgent, u.RequestURI()) {
+		return ErrDisallowed
+	}
+	delay := c.crawlDelay(r)
+	if maxDelay > 0 && delay > maxDelay {
+		return ErrCrawlDelayExceeded
+	}
+
+	// Reserve the next slot of the host and then wait for it.
+	c.mu.Lock()
+	e := c.entries[hostKey(u)]
+	now := time.Now()
+	next := now
+	if e != nil {
+		if slot := e.lastAccess.Add(delay); slot.After(now) {
+			next = slot
+		}
+		e.lastAccess = next
+	}
+	c.mu.Unlock()
+
+	if wait := next.Sub(now); wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// crawlDelay returns the crawl delay of r for the user agent of the checker.
+func (c *Checker) crawlDelay(r *Robots) time.Duration {
+	if g := r.Group(c.userAgent); g != nil {
+		return g.CrawlDelay
+	}
+	return 0
+}
+
+// robots returns the cached robots.txt of the host of u and fetches it when it's not cached or expired.
+// A robots.txt which could not be fetched is cached for errorTTL at most, and not at all when ctx is done.
+func (c *Checker) robots(ctx context.Context, u *url.URL) *Robots {
+	key := hostKey(u)
+
+	c.mu.Lock()
+	lock, ok := c.hostLocks[key]
+	if !ok {
+		lock = &hostLock{}
+		c.hostLocks[key] = lock
+	}
+	lock.refs++
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(c.hostLocks, key)
+		}
+		c.mu.Unlock()
+	}()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expiresAt) {
+		atomic.AddUint64(&c.cacheHits, 1)
+		return e.robots
+	}
+
+	atomic.AddUint64(&c.cacheMisses, 1)
+	r, err := c.fetch(ctx, u)
+	if err != nil && ctx.Err() != nil {
+		return r
+	}
+	ttl := c.ttl
+	if err != nil && ttl > errorTTL {
+		ttl = errorTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e = c.entries[key]
+	if e == nil {
+		e = &entry{}
+		c.entries[key] = e
+	}
+	e.robots = r
+	now := time.Now()
+	e.expiresAt = now.Add(ttl)
+	c.prune(now)
+
+	return r
+}
+
+// prune removes the entries which are expired and whose crawl delay has passed, it's run once per pruneInterval at
+// most. c.mu must be held by the caller.
+func (c *Checker) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < pruneInterval {
+		return
+	}
+	c.lastPrune = now
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) && now.After(e.lastAccess.Add(c.crawlDelay(e.robots))) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// fetch retrieves and parses the robots.txt of the host of u.
+// A missing robots.txt allows everything and a server error disallows everything. Network errors allow everything
+// as well, so the requests to the unreachable hosts fail on their own instead of being skipped, and are returned
+// beside the robots.txt so it's not cached for long.
+func (c *Checker) fetch(ctx context.Context, u *url.URL) (*Robots, error) {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	logger := c.logger.With(zap.String("url", robotsURL.String()))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL.String(), nil)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("could not create robots.txt request")
+		return allowAll, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.With(zap.Error(err)).Warn("could not fetch robots.txt")
+		return allowAll, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		r, err := Parse(io.LimitReader(resp.Body, maxRobotsSize))
+		if err != nil {
+			logger.With(zap.Error(err)).Warn("could not parse robots.txt")
+			return allowAll, err
+		}
+		logger.Debug("robots.txt fetched successfully")
+		return r, nil
+	case resp.StatusCode >= 500:
+		logger.With(zap.Int("status", resp.StatusCode)).Warn("robots.txt is unavailable, host is disallowed")
+		return disallowAll, nil
+	default:
+		return allowAll, nil
+	}
+}
+
+// hostKey returns the key of the host of u in the cache.
+func hostKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
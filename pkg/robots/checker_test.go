@@ -0,0 +1,163 @@
+package robots
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestRobotsServer creates a server which serves robotsTxt with status code and counts the robots.txt requests.
+func newTestRobotsServer(status int, robotsTxt string, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/robots.txt" {
+			atomic.AddInt32(hits, 1)
+			res.WriteHeader(status)
+			_, _ = io.WriteString(res, robotsTxt)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestChecker_Allowed(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "User-agent: detective\nDisallow: /private", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	allowedURL, _ := url.Parse(server.URL + "/public")
+	disallowedURL, _ := url.Parse(server.URL + "/private?a=b")
+
+	assert.True(t, c.Allowed(context.Background(), allowedURL))
+	assert.False(t, c.Allowed(context.Background(), disallowedURL))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "robots.txt must be cached")
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, c.CacheStats())
+	assert.Equal(t, "detective", c.UserAgent())
+}
+
+func TestChecker_AllowedCacheExpiration(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", 0, zap.NewNop())
+	u, _ := url.Parse(server.URL)
+	c.Allowed(context.Background(), u)
+	c.Allowed(context.Background(), u)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.Equal(t, CacheStats{Misses: 2}, c.CacheStats())
+}
+
+func TestChecker_AllowedUnavailableRobots(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		allowed bool
+	}{
+		{name: "missing robots.txt", status: http.StatusNotFound, allowed: true},
+		{name: "server error", status: http.StatusServiceUnavailable, allowed: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hits int32
+			server := newTestRobotsServer(tc.status, "", &hits)
+			defer server.Close()
+
+			c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+			u, _ := url.Parse(server.URL + "/page")
+			assert.Equal(t, tc.allowed, c.Allowed(context.Background(), u))
+		})
+	}
+
+	t.Run("unreachable host", func(t *testing.T) {
+		c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+		u, _ := url.Parse("http://localhost:22222/page")
+		assert.True(t, c.Allowed(context.Background(), u))
+		// The failure is cached for a short time only.
+		assert.True(t, c.entries[hostKey(u)].expiresAt.Before(time.Now().Add(errorTTL+time.Second)))
+	})
+}
+
+func TestChecker_AllowedCanceled(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "User-agent: *\nDisallow: /", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	u, _ := url.Parse(server.URL + "/page")
+
+	// A canceled lookup isn't cached, so the next lookup fetches the robots.txt.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, c.Allowed(ctx, u))
+	assert.Empty(t, c.entries)
+	assert.False(t, c.Allowed(context.Background(), u))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestChecker_Prune(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	c.entries["http://expired.local"] = &entry{robots: allowAll, expiresAt: time.Now().Add(-time.Minute)}
+	c.entries["http://delayed.local"] = &entry{
+		robots:     &Robots{Groups: []*Group{{UserAgents: []string{"*"}, CrawlDelay: time.Hour}}},
+		expiresAt:  time.Now().Add(-time.Minute),
+		lastAccess: time.Now(),
+	}
+	u, _ := url.Parse(server.URL + "/page")
+	c.Allowed(context.Background(), u)
+
+	// The expired entry is removed while the one whose crawl delay hasn't passed is kept, and no lock is left.
+	assert.Len(t, c.entries, 2)
+	assert.Contains(t, c.entries, hostKey(u))
+	assert.Contains(t, c.entries, "http://delayed.local")
+	assert.Empty(t, c.hostLocks)
+}
+
+func TestChecker_Wait(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "User-agent: *\nCrawl-delay: 0.2\nDisallow: /private", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	u, _ := url.Parse(server.URL + "/page")
+
+	start := time.Now()
+	assert.NoError(t, c.Wait(context.Background(), u))
+	assert.NoError(t, c.Wait(context.Background(), u))
+	assert.True(t, time.Since(start) >= 200*time.Millisecond, "second request must wait for the crawl delay")
+
+	disallowedURL, _ := url.Parse(server.URL + "/private")
+	assert.Equal(t, ErrDisallowed, c.Wait(context.Background(), disallowedURL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, c.Wait(ctx, u))
+}
+
+func TestChecker_WaitAtMost(t *testing.T) {
+	var hits int32
+	server := newTestRobotsServer(http.StatusOK, "User-agent: *\nCrawl-delay: 10", &hits)
+	defer server.Close()
+
+	c := NewChecker(http.DefaultClient, "detective", time.Hour, zap.NewNop())
+	u, _ := url.Parse(server.URL + "/page")
+
+	// The crawl delay is longer than the accepted delay, so the url is rejected without reserving a slot.
+	start := time.Now()
+	assert.Equal(t, ErrCrawlDelayExceeded, c.WaitAtMost(context.Background(), u, time.Second))
+	assert.Equal(t, ErrCrawlDelayExceeded, c.WaitAtMost(context.Background(), u, time.Second))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.True(t, c.entries[hostKey(u)].lastAccess.IsZero())
+}
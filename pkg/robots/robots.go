@@ -0,0 +1,163 @@
+package robots
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is an Allow or Disallow line of a robots.txt group.
+type Rule struct {
+	Allow bool
+	Path  string
+}
+
+// Group is a set of rules which applies to a set of user agents.
+type Group struct {
+	UserAgents []string
+	Rules      []Rule
+	CrawlDelay time.Duration
+}
+
+// Robots is a parsed robots.txt document.
+type Robots struct {
+	Groups []*Group
+}
+
+// allowAll is used for the hosts which have no robots.txt.
+var allowAll = &Robots{}
+
+// disallowAll is used for the hosts whose robots.txt is temporarily unavailable.
+var disallowAll = &Robots{Groups: []*Group{{UserAgents: []string{"*"}, Rules: []Rule{{Allow: false, Path: "/"}}}}}
+
+// Parse parses a robots.txt document, the lines which are not understood are ignored.
+func Parse(r io.Reader) (*Robots, error) {
+	robots := &Robots{}
+	var current *Group
+	// lastWasAgent shows that the previous line was a user-agent line, so consecutive user-agent lines
+	// belong to the same group.
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &Group{}
+				robots.Groups = append(robots.Groups, current)
+			}
+			current.UserAgents = append(current.UserAgents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			// An empty disallow means everything is allowed, so it doesn't add any rule.
+			if current != nil && value != "" {
+				current.Rules = append(current.Rules, Rule{Allow: key == "allow", Path: value})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.CrawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return robots, nil
+}
+
+// Group returns the group which applies to userAgent, it's the group with the longest user agent which is
+// contained in userAgent or the `*` group if there is no such group.
+func (r *Robots) Group(userAgent string) *Group {
+	userAgent = strings.ToLower(userAgent)
+	var matched, wildcard *Group
+	matchedLen := 0
+	for _, g := range r.Groups {
+		for _, ua := range g.UserAgents {
+			if ua == "*" {
+				if wildcard == nil {
+					wildcard = g
+				}
+				continue
+			}
+			if strings.Contains(userAgent, ua) && len(ua) > matchedLen {
+				matched, matchedLen = g, len(ua)
+			}
+		}
+	}
+	if matched != nil {
+		return matched
+	}
+	return wildcard
+}
+
+// Allowed reports whether userAgent is allowed to fetch path which also contains the query of the url.
+func (r *Robots) Allowed(userAgent, path string) bool {
+	g := r.Group(userAgent)
+	if g == nil {
+		return true
+	}
+	return g.Allowed(path)
+}
+
+// Allowed reports whether path is allowed by the rules of the group.
+// The rule with the longest matching path wins and allow rules win the ties.
+func (g *Group) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed, longest := true, -1
+	for _, rule := range g.Rules {
+		if !match(rule.Path, path) {
+			continue
+		}
+		if len(rule.Path) > longest || (len(rule.Path) == longest && rule.Allow) {
+			allowed, longest = rule.Allow, len(rule.Path)
+		}
+	}
+	return allowed
+}
+
+// match reports whether path matches pattern which may contain `*` wildcards and a `$` end anchor.
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i := 1; i < len(parts); i++ {
+		// The last part of an anchored pattern must match the end of the path.
+		if anchored && i == len(parts)-1 {
+			return strings.HasSuffix(rest, parts[i])
+		}
+		j := strings.Index(rest, parts[i])
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(parts[i]):]
+	}
+
+	return !anchored || rest == ""
+}
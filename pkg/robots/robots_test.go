@@ -0,0 +1,97 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRobots = `
+# Sample robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public-page
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: detective
+User-agent: other-bot
+Disallow: /no-detective
+Crawl-delay: 0.5
+
+User-agent: blocked-bot
+Disallow: /
+`
+
+func TestParse(t *testing.T) {
+	r, err := Parse(strings.NewReader(testRobots))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Len(t, r.Groups, 3) {
+		return
+	}
+	assert.Equal(t, []string{"*"}, r.Groups[0].UserAgents)
+	assert.Equal(t, []Rule{
+		{Allow: false, Path: "/private/"},
+		{Allow: true, Path: "/private/public-page"},
+		{Allow: false, Path: "/*.pdf$"},
+	}, r.Groups[0].Rules)
+	assert.Equal(t, 2*time.Second, r.Groups[0].CrawlDelay)
+	assert.Equal(t, []string{"detective", "other-bot"}, r.Groups[1].UserAgents)
+	assert.Equal(t, 500*time.Millisecond, r.Groups[1].CrawlDelay)
+}
+
+func TestRobots_Group(t *testing.T) {
+	r, _ := Parse(strings.NewReader(testRobots))
+	assert.Equal(t, r.Groups[1], r.Group("Detective/1.0"))
+	assert.Equal(t, r.Groups[2], r.Group("blocked-bot"))
+	assert.Equal(t, r.Groups[0], r.Group("unknown-bot"))
+	assert.Nil(t, (&Robots{}).Group("detective"))
+}
+
+func TestRobots_Allowed(t *testing.T) {
+	r, _ := Parse(strings.NewReader(testRobots))
+	testCases := []struct {
+		userAgent string
+		path      string
+		allowed   bool
+	}{
+		{userAgent: "unknown-bot", path: "/", allowed: true},
+		{userAgent: "unknown-bot", path: "/private/page", allowed: false},
+		{userAgent: "unknown-bot", path: "/private/public-page", allowed: true},
+		{userAgent: "unknown-bot", path: "/docs/file.pdf", allowed: false},
+		{userAgent: "unknown-bot", path: "/docs/file.pdf?download=1", allowed: true},
+		{userAgent: "detective", path: "/private/page", allowed: true},
+		{userAgent: "detective", path: "/no-detective/page", allowed: false},
+		{userAgent: "blocked-bot", path: "/anything", allowed: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.userAgent+" "+tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, r.Allowed(tc.userAgent, tc.path))
+		})
+	}
+	assert.True(t, (&Robots{}).Allowed("detective", "/"))
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		matched bool
+	}{
+		{pattern: "/a", path: "/a/b", matched: true},
+		{pattern: "/a$", path: "/a", matched: true},
+		{pattern: "/a$", path: "/a/b", matched: false},
+		{pattern: "/*/b", path: "/a/b/c", matched: true},
+		{pattern: "/*.php$", path: "/index.php", matched: true},
+		{pattern: "/*.php$", path: "/index.php5", matched: false},
+		{pattern: "/b", path: "/a/b", matched: false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.matched, match(tc.pattern, tc.path), "pattern %s on %s", tc.pattern, tc.path)
+	}
+}
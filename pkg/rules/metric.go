@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+)
+
+// Metric is the name of a value of the result of an analysis which rules check.
+type Metric string
+
+// List of available metrics.
+const (
+	MetricHTMLVersion               Metric = "html_version"
+	MetricTitle                     Metric = "title"
+	MetricTitleLength               Metric = "title_length"
+	MetricHeadingsH1                Metric = "headings.h1"
+	MetricHeadingsH2                Metric = "headings.h2"
+	MetricHeadingsH3                Metric = "headings.h3"
+	MetricHeadingsH4                Metric = "headings.h4"
+	MetricHeadingsH5                Metric = "headings.h5"
+	MetricHeadingsH6                Metric = "headings.h6"
+	MetricInternalLinks             Metric = "links.internal"
+	MetricExternalLinks             Metric = "links.external"
+	MetricInaccessibleLinks         Metric = "inaccessible_links"
+	MetricInaccessibleInternalLinks Metric = "inaccessible_links.internal"
+	MetricInaccessibleExternalLinks Metric = "inaccessible_links.external"
+	MetricRobotsSkippedLinks        Metric = "robots_skipped_links"
+	MetricHasLoginForm              Metric = "has_login_form"
+	MetricCanonicalURL              Metric = "canonical_url"
+)
+
+// metricKind is the type of the value of a metric.
+type metricKind string
+
+// List of the kinds of metrics.
+const (
+	kindNumber metricKind = "number"
+	kindString metricKind = "string"
+	kindBool   metricKind = "boolean"
+)
+
+// is returns true if v, which is decoded from a rules file, is a value of k.
+func (k metricKind) is(v interface{}) bool {
+	switch v.(type) {
+	case float64:
+		return k == kindNumber
+	case string:
+		return k == kindString
+	case bool:
+		return k == kindBool
+	default:
+		return false
+	}
+}
+
+// metricKinds maps the metrics to the types of their values.
+var metricKinds = map[Metric]metricKind{
+	MetricHTMLVersion:               kindString,
+	MetricTitle:                     kindString,
+	MetricTitleLength:               kindNumber,
+	MetricHeadingsH1:                kindNumber,
+	MetricHeadingsH2:                kindNumber,
+	MetricHeadingsH3:                kindNumber,
+	MetricHeadingsH4:                kindNumber,
+	MetricHeadingsH5:                kindNumber,
+	MetricHeadingsH6:                kindNumber,
+	MetricInternalLinks:             kindNumber,
+	MetricExternalLinks:             kindNumber,
+	MetricInaccessibleLinks:         kindNumber,
+	MetricInaccessibleInternalLinks: kindNumber,
+	MetricInaccessibleExternalLinks: kindNumber,
+	MetricRobotsSkippedLinks:        kindNumber,
+	MetricHasLoginForm:              kindBool,
+	MetricCanonicalURL:              kindString,
+}
+
+// metricValue returns the value of m in the result r of the analysis of the page of pageURL.
+// The title of the pages without a title is empty and the inaccessible links whose host is the host of the page are
+// internal.
+func metricValue(m Metric, pageURL string, r *htmlanalysis.Result) interface{} {
+	hc := r.HeadingsCount
+	if hc == nil {
+		hc = &htmlanalysis.HeadingsCount{}
+	}
+	lc := r.LinksCount
+	if lc == nil {
+		lc = &htmlanalysis.LinksCount{}
+	}
+	title := r.PageTitle
+	if title == htmlanalysis.EmptyPageTitle {
+		title = ""
+	}
+
+	switch m {
+	case MetricHTMLVersion:
+		return r.HTMLVersion
+	case MetricTitle:
+		return title
+	case MetricTitleLength:
+		return float64(len([]rune(title)))
+	case MetricHeadingsH1:
+		return float64(hc.H1)
+	case MetricHeadingsH2:
+		return float64(hc.H2)
+	case MetricHeadingsH3:
+		return float64(hc.H3)
+	case MetricHeadingsH4:
+		return float64(hc.H4)
+	case MetricHeadingsH5:
+		return float64(hc.H5)
+	case MetricHeadingsH6:
+		return float64(hc.H6)
+	case MetricInternalLinks:
+		return float64(lc.Internal)
+	case MetricExternalLinks:
+		return float64(lc.External)
+	case MetricInaccessibleLinks:
+		return float64(r.InaccessibleLinksCount)
+	case MetricInaccessibleInternalLinks:
+		return float64(internalLinksCount(pageURL, r.InaccessibleLinks))
+	case MetricInaccessibleExternalLinks:
+		return float64(len(r.InaccessibleLinks) - internalLinksCount(pageURL, r.InaccessibleLinks))
+	case MetricRobotsSkippedLinks:
+		return float64(r.RobotsSkippedLinksCount)
+	case MetricHasLoginForm:
+		return r.HasLoginForm
+	case MetricCanonicalURL:
+		return r.CanonicalURL
+	default:
+		return nil
+	}
+}
+
+// internalLinksCount returns the number of links whose host is the host of pageURL.
+func internalLinksCount(pageURL string, links []string) int {
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Host == "" {
+		return 0
+	}
+	n := 0
+	for _, l := range links {
+		if u, err := url.Parse(l); err == nil && strings.EqualFold(u.Host, page.Host) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricValue(t *testing.T) {
+	r := &htmlanalysis.Result{
+		HTMLVersion:            "HTML 5",
+		PageTitle:              "Détective",
+		HeadingsCount:          &htmlanalysis.HeadingsCount{H1: 1, H6: 6},
+		LinksCount:             &htmlanalysis.LinksCount{Internal: 4, External: 2},
+		InaccessibleLinksCount: 3,
+		InaccessibleLinks:      []string{"https://Example.com/a", "/b", "https://other.com/c"},
+		CanonicalURL:           "https://example.com/",
+	}
+	pageURL := "https://example.com/"
+
+	for m, expected := range map[Metric]interface{}{
+		MetricHTMLVersion:               "HTML 5",
+		MetricTitle:                     "Détective",
+		MetricTitleLength:               float64(9),
+		MetricHeadingsH1:                float64(1),
+		MetricHeadingsH6:                float64(6),
+		MetricInternalLinks:             float64(4),
+		MetricExternalLinks:             float64(2),
+		MetricInaccessibleLinks:         float64(3),
+		MetricInaccessibleInternalLinks: float64(1),
+		MetricInaccessibleExternalLinks: float64(2),
+		MetricRobotsSkippedLinks:        float64(0),
+		MetricHasLoginForm:              false,
+		MetricCanonicalURL:              "https://example.com/",
+	} {
+		assert.Equal(t, expected, metricValue(m, pageURL, r), m)
+	}
+
+	// Every metric has a value of its kind even if the result is empty.
+	empty := &htmlanalysis.Result{PageTitle: htmlanalysis.EmptyPageTitle}
+	for m, kind := range metricKinds {
+		assert.True(t, kind.is(metricValue(m, pageURL, empty)), m)
+	}
+	assert.Equal(t, "", metricValue(MetricTitle, pageURL, empty))
+}
@@ -0,0 +1,202 @@
+// Package rules evaluates the results of analyses against a set of rules which encode the standards of pages,
+// e.g. "exactly one h1 heading" or "no broken internal links", and returns a pass or fail verdict for each of them.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+)
+
+// Rule is a condition which the metric of a page must satisfy.
+// Min and Max bound the numeric metrics, Equals is the expected value of the metric and Matches is a regular
+// expression which the string metrics must match. URLPattern is a regular expression which limits the rule to the
+// pages whose url matches it, the rule applies to all the pages when it's empty. Message replaces the generated
+// message of the failures of the rule.
+type Rule struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Metric      Metric      `json:"metric"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+	Equals      interface{} `json:"equals,omitempty"`
+	Matches     string      `json:"matches,omitempty"`
+	URLPattern  string      `json:"url_pattern,omitempty"`
+	Message     string      `json:"message,omitempty"`
+
+	matches    *regexp.Regexp
+	urlPattern *regexp.Regexp
+}
+
+// RuleSet is a set of rules which are evaluated together.
+type RuleSet struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Parse parses a rule set from a yaml or json document and validates its rules.
+func Parse(b []byte) (*RuleSet, error) {
+	s := &RuleSet{}
+	if err := yaml.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("error while parsing rules: %w", err)
+	}
+	if len(s.Rules) == 0 {
+		return nil, errors.New("rules file has no rules")
+	}
+	names := make(map[string]bool, len(s.Rules))
+	for i, r := range s.Rules {
+		if r == nil || r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("rule `%s` is defined more than once", r.Name)
+		}
+		names[r.Name] = true
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule `%s` is not valid: %w", r.Name, err)
+		}
+	}
+	return s, nil
+}
+
+// Load reads and parses the rule set of the file of path.
+func Load(path string) (*RuleSet, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading rules file: %w", err)
+	}
+	return Parse(b)
+}
+
+// validate checks that the metric of r is known, its conditions suit the type of the metric and compiles its
+// regular expressions.
+func (r *Rule) validate() error {
+	kind, ok := metricKinds[r.Metric]
+	if !ok {
+		return fmt.Errorf("unknown metric `%s`", r.Metric)
+	}
+	if r.Min == nil && r.Max == nil && r.Equals == nil && r.Matches == "" {
+		return errors.New("rule has no condition")
+	}
+	if (r.Min != nil || r.Max != nil) && kind != kindNumber {
+		return errors.New("min and max only apply to numeric metrics")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return errors.New("min is greater than max")
+	}
+	if r.Matches != "" && kind != kindString {
+		return errors.New("matches only applies to string metrics")
+	}
+	if r.Equals != nil && !kind.is(r.Equals) {
+		return fmt.Errorf("equals must be a %s", kind)
+	}
+
+	var err error
+	if r.Matches != "" {
+		if r.matches, err = regexp.Compile(r.Matches); err != nil {
+			return fmt.Errorf("matches expression is not valid: %w", err)
+		}
+	}
+	if r.URLPattern != "" {
+		if r.urlPattern, err = regexp.Compile(r.URLPattern); err != nil {
+			return fmt.Errorf("url pattern is not valid: %w", err)
+		}
+	}
+	return nil
+}
+
+// RuleResult is the outcome of evaluating a rule on a page.
+type RuleResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Passed      bool   `json:"passed"`
+	Message     string `json:"message"`
+}
+
+// Verdict is the outcome of evaluating a rule set on a page, it has passed if all of its rules have passed.
+// The rules whose url pattern doesn't match the page are not evaluated.
+type Verdict struct {
+	Passed bool          `json:"passed"`
+	Rules  []*RuleResult `json:"rules"`
+}
+
+// Evaluate evaluates the rules of s on the result r of the analysis of the page of pageURL.
+func (s *RuleSet) Evaluate(pageURL string, r *htmlanalysis.Result) *Verdict {
+	v := &Verdict{Passed: true, Rules: []*RuleResult{}}
+	for _, rule := range s.Rules {
+		if rule.urlPattern != nil && !rule.urlPattern.MatchString(pageURL) {
+			continue
+		}
+		res := rule.evaluate(metricValue(rule.Metric, pageURL, r))
+		v.Passed = v.Passed && res.Passed
+		v.Rules = append(v.Rules, res)
+	}
+	return v
+}
+
+// Checks returns the rules of v as report checks.
+func (v *Verdict) Checks() []report.Check {
+	checks := make([]report.Check, 0, len(v.Rules))
+	for _, r := range v.Rules {
+		checks = append(checks, report.Check{Name: r.Name, Passed: r.Passed, Message: r.Message})
+	}
+	return checks
+}
+
+// evaluate evaluates r on the value of its metric.
+func (r *Rule) evaluate(value interface{}) *RuleResult {
+	res := &RuleResult{Name: r.Name, Description: r.Description, Passed: true}
+	actual := fmt.Sprintf("%s is %s", r.Metric, format(value))
+	var expected string
+
+	switch v := value.(type) {
+	case float64:
+		switch {
+		case r.Min != nil && r.Max != nil:
+			res.Passed = v >= *r.Min && v <= *r.Max
+			expected = fmt.Sprintf("expected between %s and %s", format(*r.Min), format(*r.Max))
+		case r.Min != nil:
+			res.Passed = v >= *r.Min
+			expected = "expected at least " + format(*r.Min)
+		case r.Max != nil:
+			res.Passed = v <= *r.Max
+			expected = "expected at most " + format(*r.Max)
+		}
+	case string:
+		if r.matches != nil {
+			res.Passed = r.matches.MatchString(v)
+			expected = fmt.Sprintf("expected to match `%s`", r.Matches)
+		}
+	}
+	if r.Equals != nil && res.Passed {
+		res.Passed = format(value) == format(r.Equals)
+		expected = "expected " + format(r.Equals)
+	}
+
+	switch {
+	case res.Passed:
+		res.Message = actual
+	case r.Message != "":
+		res.Message = r.Message
+	default:
+		res.Message = actual + ", " + expected
+	}
+	return res
+}
+
+// format formats the value of a metric or a condition for messages.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return "`" + v + "`"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRules is a rules file with the standards of a team.
+const testRules = `
+rules:
+  - name: one-h1
+    description: Pages have exactly one h1 heading.
+    metric: headings.h1
+    min: 1
+    max: 1
+  - name: title-length
+    metric: title_length
+    min: 10
+    max: 60
+  - name: no-broken-internal-links
+    metric: inaccessible_links.internal
+    max: 0
+    message: Page has broken internal links.
+  - name: no-login-form-on-marketing-pages
+    metric: has_login_form
+    equals: false
+    url_pattern: ^https://example\.com/marketing/
+  - name: html5
+    metric: html_version
+    matches: ^HTML 5$
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testRules))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, s.Rules, 5)
+	assert.Equal(t, "Pages have exactly one h1 heading.", s.Rules[0].Description)
+
+	// Json is valid yaml.
+	s, err = Parse([]byte(`{"rules": [{"name": "title", "metric": "title", "matches": "."}]}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, MetricTitle, s.Rules[0].Metric)
+	}
+}
+
+func TestParseInvalidRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         string
+		expectedError string
+	}{
+		{name: "invalid document", rules: "rules: [", expectedError: "error while parsing rules"},
+		{name: "no rules", rules: "rules: []", expectedError: "rules file has no rules"},
+		{name: "no name", rules: "rules: [{metric: title, matches: x}]", expectedError: "rule 1 has no name"},
+		{
+			name:          "duplicate name",
+			rules:         "rules: [{name: a, metric: title, matches: x}, {name: a, metric: title, matches: y}]",
+			expectedError: "rule `a` is defined more than once",
+		},
+		{name: "unknown metric", rules: "rules: [{name: a, metric: speed, max: 1}]", expectedError: "unknown metric `speed`"},
+		{name: "no condition", rules: "rules: [{name: a, metric: title}]", expectedError: "rule has no condition"},
+		{
+			name:          "min of string",
+			rules:         "rules: [{name: a, metric: title, min: 1}]",
+			expectedError: "min and max only apply to numeric metrics",
+		},
+		{
+			name:          "min greater than max",
+			rules:         "rules: [{name: a, metric: headings.h1, min: 2, max: 1}]",
+			expectedError: "min is greater than max",
+		},
+		{
+			name:          "matches of number",
+			rules:         "rules: [{name: a, metric: headings.h1, matches: x}]",
+			expectedError: "matches only applies to string metrics",
+		},
+		{
+			name:          "equals of another type",
+			rules:         "rules: [{name: a, metric: has_login_form, equals: 1}]",
+			expectedError: "equals must be a boolean",
+		},
+		{
+			name:          "invalid expression",
+			rules:         "rules: [{name: a, metric: title, matches: '('}]",
+			expectedError: "matches expression is not valid",
+		},
+		{
+			name:          "invalid url pattern",
+			rules:         "rules: [{name: a, metric: title, matches: x, url_pattern: '('}]",
+			expectedError: "url pattern is not valid",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse([]byte(test.rules))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.expectedError)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := ioutil.WriteFile(path, []byte(testRules), 0o600); err != nil {
+		t.Fatalf("error while writing rules file: %v", err)
+	}
+	s, err := Load(path)
+	if assert.NoError(t, err) {
+		assert.Len(t, s.Rules, 5)
+	}
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRuleSet_Evaluate(t *testing.T) {
+	s, err := Parse([]byte(testRules))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	v := s.Evaluate("https://example.com/marketing/launch", &htmlanalysis.Result{
+		HTMLVersion:            "HTML 5",
+		PageTitle:              "Launch",
+		HeadingsCount:          &htmlanalysis.HeadingsCount{H1: 2},
+		InaccessibleLinksCount: 2,
+		InaccessibleLinks:      []string{"https://example.com/a", "https://other.com/b"},
+		HasLoginForm:           true,
+	})
+	assert.False(t, v.Passed)
+	assert.Equal(t, []*RuleResult{
+		{
+			Name:        "one-h1",
+			Description: "Pages have exactly one h1 heading.",
+			Message:     "headings.h1 is 2, expected between 1 and 1",
+		},
+		{Name: "title-length", Message: "title_length is 6, expected between 10 and 60"},
+		{Name: "no-broken-internal-links", Message: "Page has broken internal links."},
+		{Name: "no-login-form-on-marketing-pages", Message: "has_login_form is true, expected false"},
+		{Name: "html5", Passed: true, Message: "html_version is `HTML 5`"},
+	}, v.Rules)
+
+	// The marketing rule doesn't apply to the other pages.
+	v = s.Evaluate("https://example.com/login", &htmlanalysis.Result{
+		HTMLVersion:            "HTML 5",
+		PageTitle:              "Sign in to Example",
+		HeadingsCount:          &htmlanalysis.HeadingsCount{H1: 1},
+		InaccessibleLinksCount: 1,
+		InaccessibleLinks:      []string{"https://other.com/b"},
+		HasLoginForm:           true,
+	})
+	assert.True(t, v.Passed)
+	assert.Len(t, v.Rules, 4)
+	assert.Equal(t, []report.Check{
+		{Name: "one-h1", Passed: true, Message: "headings.h1 is 1"},
+		{Name: "title-length", Passed: true, Message: "title_length is 18"},
+		{Name: "no-broken-internal-links", Passed: true, Message: "inaccessible_links.internal is 0"},
+		{Name: "html5", Passed: true, Message: "html_version is `HTML 5`"},
+	}, v.Checks())
+}
@@ -0,0 +1,200 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/mammadmodi/detective/pkg/crawler"
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"go.uber.org/zap"
+)
+
+// FetchFunc is a type of function which retrieves the html document of a url and returns the url of the
+// page after following the redirects and the certificate health of its connection, which is nil for the http pages.
+type FetchFunc func(
+	ctx context.Context,
+	u *url.URL,
+) (finalURL *url.URL, htmlDoc string, tlsReport *htmlanalysis.TLSReport, err error)
+
+// AnalyzeFunc is a type of function which analyzes an html doc and returns a Result object.
+type AnalyzeFunc func(ctx context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error)
+
+// PageStatus is the consistency status of a page which is listed in a sitemap.
+type PageStatus string
+
+// List of available page statuses.
+const (
+	PageStatusOK           PageStatus = "ok"
+	PageStatusBroken       PageStatus = "broken"
+	PageStatusRedirected   PageStatus = "redirected"
+	PageStatusNonCanonical PageStatus = "non_canonical"
+	PageStatusSkipped      PageStatus = "skipped"
+)
+
+// PageReport is the outcome of auditing a page which is listed in a sitemap.
+// FinalURL is the url of the page after following redirects and CanonicalURL is its canonical link.
+type PageReport struct {
+	URL          string               `json:"url"`
+	Status       PageStatus           `json:"status"`
+	FinalURL     string               `json:"final_url"`
+	CanonicalURL string               `json:"canonical_url"`
+	Result       *htmlanalysis.Result `json:"result"`
+	Error        string               `json:"error"`
+}
+
+// Report is the outcome of auditing a sitemap.
+// SkippedCount is the number of the listed pages which are not fetched because robots.txt disallows them and
+// MissingURLs holds the internal pages which are linked from the listed pages but are not listed in the sitemap.
+type Report struct {
+	SitemapURL       string        `json:"sitemap_url"`
+	Pages            []*PageReport `json:"pages"`
+	BrokenURLs       []string      `json:"broken_urls"`
+	RedirectedURLs   []string      `json:"redirected_urls"`
+	NonCanonicalURLs []string      `json:"non_canonical_urls"`
+	SkippedCount     int           `json:"skipped_count"`
+	MissingURLs      []string      `json:"missing_urls"`
+}
+
+// Auditor analyzes the pages of a sitemap and checks the consistency of the sitemap with the pages.
+type Auditor struct {
+	fetch       FetchFunc
+	analyze     AnalyzeFunc
+	concurrency int
+	logger      *zap.Logger
+}
+
+// NewAuditor creates an Auditor which analyzes at most concurrency pages at the same time.
+func NewAuditor(fetch FetchFunc, analyze AnalyzeFunc, concurrency int, logger *zap.Logger) *Auditor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Auditor{
+		fetch:       fetch,
+		analyze:     analyze,
+		concurrency: concurrency,
+		logger:      logger,
+	}
+}
+
+// Audit analyzes every url of the sitemap and reports the broken, redirected and non-canonical pages
+// and the internal pages which are missing from the sitemap. The pages which are disallowed by robots.txt are
+// skipped and are not reported as broken.
+func (a *Auditor) Audit(ctx context.Context, sitemapURL string, urls []string) *Report {
+	report := &Report{
+		SitemapURL:       sitemapURL,
+		Pages:            make([]*PageReport, len(urls)),
+		BrokenURLs:       []string{},
+		RedirectedURLs:   []string{},
+		NonCanonicalURLs: []string{},
+		MissingURLs:      []string{},
+	}
+	discovered := make([][]*url.URL, len(urls))
+
+	sem := make(chan struct{}, a.concurrency)
+	wg := sync.WaitGroup{}
+	wg.Add(len(urls))
+	for i, rawURL := range urls {
+		i, rawURL := i, rawURL
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			report.Pages[i], discovered[i] = a.auditPage(ctx, rawURL)
+		}()
+	}
+	wg.Wait()
+
+	listed := map[string]bool{}
+	for _, p := range report.Pages {
+		if u, err := url.Parse(p.URL); err == nil {
+			listed[crawler.Normalize(u).String()] = true
+		}
+		switch p.Status {
+		case PageStatusBroken:
+			report.BrokenURLs = append(report.BrokenURLs, p.URL)
+		case PageStatusRedirected:
+			report.RedirectedURLs = append(report.RedirectedURLs, p.URL)
+		case PageStatusNonCanonical:
+			report.NonCanonicalURLs = append(report.NonCanonicalURLs, p.URL)
+		case PageStatusSkipped:
+			report.SkippedCount++
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, links := range discovered {
+		for _, u := range links {
+			if n := crawler.Normalize(u).String(); !listed[n] {
+				missing[n] = true
+			}
+		}
+	}
+	for u := range missing {
+		report.MissingURLs = append(report.MissingURLs, u)
+	}
+	sort.Strings(report.MissingURLs)
+
+	return report
+}
+
+// auditPage fetches and analyzes a single page of the sitemap and returns its report and internal links.
+func (a *Auditor) auditPage(ctx context.Context, rawURL string) (*PageReport, []*url.URL) {
+	logger := a.logger.With(zap.String("url", rawURL))
+	p := &PageReport{URL: rawURL, Status: PageStatusBroken}
+
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("url of sitemap is not valid")
+		p.Error = "url is not valid"
+		return p, nil
+	}
+
+	finalURL, htmlDoc, tlsReport, err := a.fetch(ctx, u)
+	if errors.Is(err, robots.ErrDisallowed) {
+		logger.Info("page of sitemap is disallowed by robots.txt")
+		p.Status = PageStatusSkipped
+		p.Error = "url is disallowed by robots.txt"
+		return p, nil
+	}
+	if err != nil {
+		logger.With(zap.Error(err)).Error("error while fetching page")
+		p.Error = "could not retrieve html body of url"
+		return p, nil
+	}
+	p.FinalURL = finalURL.String()
+
+	res, err := a.analyze(ctx, finalURL, htmlDoc)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("error while analyzing page")
+		p.Error = "error while parsing html"
+		return p, nil
+	}
+	res.TLS = tlsReport
+	p.Result = res
+	p.CanonicalURL = res.CanonicalURL
+
+	normalizedURL := crawler.Normalize(u).String()
+	switch {
+	case crawler.Normalize(finalURL).String() != normalizedURL:
+		p.Status = PageStatusRedirected
+	case res.CanonicalURL != "" && normalize(res.CanonicalURL) != normalizedURL:
+		p.Status = PageStatusNonCanonical
+	default:
+		p.Status = PageStatusOK
+	}
+	logger.With(zap.String("status", string(p.Status))).Info("page audited successfully")
+
+	return p, htmlanalysis.NewHTMLAnalyzer(htmlDoc, finalURL).InternalLinks()
+}
+
+// normalize normalizes rawURL by crawler.Normalize, it returns rawURL itself if it's not a valid url.
+func normalize(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return crawler.Normalize(u).String()
+}
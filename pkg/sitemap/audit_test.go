@@ -0,0 +1,97 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/mammadmodi/detective/pkg/htmlanalysis"
+	"github.com/mammadmodi/detective/pkg/robots"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// testPage is a page of the test site, redirect is the url which the page redirects to and tls is the certificate
+// health of the https pages.
+type testPage struct {
+	htmlDoc  string
+	redirect string
+	tls      *htmlanalysis.TLSReport
+}
+
+var testPages = map[string]testPage{
+	"http://example.com/":         {htmlDoc: `<a href="/about">About</a><a href="/contact">Contact</a>`},
+	"http://example.com/about":    {htmlDoc: `<link rel="canonical" href="http://example.com/about"><a href="/">Home</a>`},
+	"http://example.com/old":      {redirect: "http://example.com/new", htmlDoc: `<a href="/new">New</a>`},
+	"http://example.com/print":    {htmlDoc: `<link rel="canonical" href="/about">`, tls: &htmlanalysis.TLSReport{}},
+	"http://example.com/contact/": {htmlDoc: ``},
+}
+
+func newTestAuditor() *Auditor {
+	fetch := func(_ context.Context, u *url.URL) (*url.URL, string, *htmlanalysis.TLSReport, error) {
+		if u.Path == "/private" {
+			return nil, "", nil, robots.ErrDisallowed
+		}
+		p, ok := testPages[u.String()]
+		if !ok {
+			return nil, "", nil, errors.New("not found")
+		}
+		if p.redirect != "" {
+			u, _ = url.Parse(p.redirect)
+		}
+		return u, p.htmlDoc, p.tls, nil
+	}
+	// Links are not checked, so the test doesn't perform any request.
+	analyze := func(_ context.Context, u *url.URL, htmlDoc string) (*htmlanalysis.Result, error) {
+		res := &htmlanalysis.Result{}
+		if canonicalURL := htmlanalysis.NewHTMLAnalyzer(htmlDoc, u).GetCanonicalURL(); canonicalURL != nil {
+			res.CanonicalURL = canonicalURL.String()
+		}
+		return res, nil
+	}
+	return NewAuditor(fetch, analyze, 2, zap.NewNop())
+}
+
+func TestAuditor_Audit(t *testing.T) {
+	urls := []string{
+		"http://example.com/",
+		"http://example.com/about",
+		"http://example.com/old",
+		"http://example.com/print",
+		"http://example.com/missing",
+		"invalid url",
+		"http://example.com/private",
+	}
+	report := newTestAuditor().Audit(context.Background(), "http://example.com/sitemap.xml", urls)
+
+	assert.Equal(t, "http://example.com/sitemap.xml", report.SitemapURL)
+	if !assert.Len(t, report.Pages, len(urls)) {
+		return
+	}
+	for i, expectedStatus := range []PageStatus{
+		PageStatusOK,
+		PageStatusOK,
+		PageStatusRedirected,
+		PageStatusNonCanonical,
+		PageStatusBroken,
+		PageStatusBroken,
+		PageStatusSkipped,
+	} {
+		assert.Equal(t, urls[i], report.Pages[i].URL)
+		assert.Equal(t, expectedStatus, report.Pages[i].Status, urls[i])
+	}
+	assert.Equal(t, "http://example.com/new", report.Pages[2].FinalURL)
+	assert.Equal(t, "http://example.com/about", report.Pages[3].CanonicalURL)
+	assert.Equal(t, testPages["http://example.com/print"].tls, report.Pages[3].Result.TLS)
+	assert.Nil(t, report.Pages[0].Result.TLS)
+	assert.Equal(t, "could not retrieve html body of url", report.Pages[4].Error)
+	assert.Equal(t, "url is not valid", report.Pages[5].Error)
+	assert.Equal(t, "url is disallowed by robots.txt", report.Pages[6].Error)
+
+	assert.Equal(t, []string{"http://example.com/missing", "invalid url"}, report.BrokenURLs)
+	assert.Equal(t, []string{"http://example.com/old"}, report.RedirectedURLs)
+	assert.Equal(t, []string{"http://example.com/print"}, report.NonCanonicalURLs)
+	assert.Equal(t, 1, report.SkippedCount)
+	assert.Equal(t, []string{"http://example.com/contact", "http://example.com/new"}, report.MissingURLs)
+}
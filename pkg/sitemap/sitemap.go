@@ -0,0 +1,171 @@
+package sitemap
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxSitemapSize is the maximum size of an uncompressed sitemap which is accepted by the sitemaps protocol.
+const maxSitemapSize = 50 * 1024 * 1024
+
+// maxIndexDepth is the maximum depth of nested sitemap indexes which are followed.
+const maxIndexDepth = 3
+
+// StatusError is returned when a sitemap responds with a status code other than 200.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error returns the message of e.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sitemap `%s` responded with status code %d", e.URL, e.StatusCode)
+}
+
+// Options holds the limits of sitemap audits.
+// MaxURLs is the maximum number of urls of a sitemap which are analyzed.
+// Concurrency is the number of pages which are analyzed at the same time.
+type Options struct {
+	MaxURLs     int
+	Concurrency int
+}
+
+// Sitemap is a parsed sitemap document which is either a url set or a sitemap index.
+// URLs holds the page urls of a url set and Sitemaps holds the sitemap urls of a sitemap index.
+type Sitemap struct {
+	URLs     []string
+	Sitemaps []string
+}
+
+// xmlDocument is the common structure of urlset and sitemapindex documents.
+type xmlDocument struct {
+	XMLName  xml.Name
+	URLs     []xmlLocation `xml:"url"`
+	Sitemaps []xmlLocation `xml:"sitemap"`
+}
+
+type xmlLocation struct {
+	Loc string `xml:"loc"`
+}
+
+// Parse parses a sitemap document which may be gzipped.
+func Parse(r io.Reader) (*Sitemap, error) {
+	br := bufio.NewReader(r)
+	// Gzipped sitemaps are detected by the magic number of gzip format instead of their content type,
+	// because servers serve them with different content types.
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("error while decompressing sitemap: %w", err)
+		}
+		defer func() { _ = gr.Close() }()
+		r = gr
+	} else {
+		r = br
+	}
+
+	doc := xmlDocument{}
+	if err := xml.NewDecoder(io.LimitReader(r, maxSitemapSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error while decoding sitemap: %w", err)
+	}
+
+	s := &Sitemap{}
+	switch doc.XMLName.Local {
+	case "urlset":
+		for _, l := range doc.URLs {
+			if loc := strings.TrimSpace(l.Loc); loc != "" {
+				s.URLs = append(s.URLs, loc)
+			}
+		}
+	case "sitemapindex":
+		for _, l := range doc.Sitemaps {
+			if loc := strings.TrimSpace(l.Loc); loc != "" {
+				s.Sitemaps = append(s.Sitemaps, loc)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown sitemap root element `%s`", doc.XMLName.Local)
+	}
+
+	return s, nil
+}
+
+// Fetch retrieves the sitemap of u and returns the page urls which are listed in it, the sitemaps of
+// sitemap indexes are followed recursively. At most maxURLs urls are returned, a non-positive maxURLs
+// means no limit.
+func Fetch(ctx context.Context, client *http.Client, u *url.URL, maxURLs int) ([]string, error) {
+	if maxURLs < 1 {
+		maxURLs = math.MaxInt32
+	}
+	var urls []string
+	visited := map[string]bool{}
+	var fetch func(u *url.URL, depth int) error
+	fetch = func(u *url.URL, depth int) error {
+		if visited[u.String()] || len(urls) >= maxURLs {
+			return nil
+		}
+		visited[u.String()] = true
+
+		s, err := fetchSitemap(ctx, client, u)
+		if err != nil {
+			return err
+		}
+		for _, loc := range s.URLs {
+			if len(urls) >= maxURLs {
+				break
+			}
+			urls = append(urls, loc)
+		}
+		if len(s.Sitemaps) > 0 && depth >= maxIndexDepth {
+			return errors.New("sitemap indexes are nested too deeply")
+		}
+		for _, loc := range s.Sitemaps {
+			child, err := u.Parse(loc)
+			if err != nil {
+				return fmt.Errorf("sitemap url `%s` is not valid: %w", loc, err)
+			}
+			if err := fetch(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := fetch(u, 0); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// fetchSitemap retrieves and parses a single sitemap document.
+func fetchSitemap(ctx context.Context, client *http.Client, u *url.URL) (*Sitemap, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sitemap request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching sitemap `%s`: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: u.String(), StatusCode: resp.StatusCode}
+	}
+
+	s, err := Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("sitemap `%s` is not valid: %w", u, err)
+	}
+	return s, nil
+}
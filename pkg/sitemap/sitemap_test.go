@@ -0,0 +1,117 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testURLSet = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://example.com/</loc></url>
+  <url><loc> http://example.com/about </loc><lastmod>2021-01-01</lastmod></url>
+  <url><loc></loc></url>
+</urlset>`
+
+const testSitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>/sitemap-pages.xml.gz</loc></sitemap>
+  <sitemap><loc>/sitemap-blog.xml</loc></sitemap>
+</sitemapindex>`
+
+// gzipped compresses s by gzip.
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = io.WriteString(w, s)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document []byte
+		expected *Sitemap
+	}{
+		{
+			name:     "url set",
+			document: []byte(testURLSet),
+			expected: &Sitemap{URLs: []string{"http://example.com/", "http://example.com/about"}},
+		},
+		{
+			name:     "gzipped url set",
+			document: gzipped(testURLSet),
+			expected: &Sitemap{URLs: []string{"http://example.com/", "http://example.com/about"}},
+		},
+		{
+			name:     "sitemap index",
+			document: []byte(testSitemapIndex),
+			expected: &Sitemap{Sitemaps: []string{"/sitemap-pages.xml.gz", "/sitemap-blog.xml"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(bytes.NewReader(tc.document))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestParseFailures(t *testing.T) {
+	for name, document := range map[string]string{
+		"not xml":      "not xml",
+		"unknown root": "<rss></rss>",
+	} {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(strings.NewReader(document))
+			assert.Nil(t, s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/sitemap.xml":
+			_, _ = io.WriteString(res, testSitemapIndex)
+		case "/sitemap-pages.xml.gz":
+			res.Header().Set("Content-Type", "application/x-gzip")
+			_, _ = res.Write(gzipped(testURLSet))
+		case "/sitemap-blog.xml":
+			_, _ = io.WriteString(res, `<urlset><url><loc>http://example.com/blog</loc></url></urlset>`)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/sitemap.xml")
+	urls, err := Fetch(context.Background(), http.DefaultClient, u, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://example.com/", "http://example.com/about", "http://example.com/blog"}, urls)
+
+	urls, err = Fetch(context.Background(), http.DefaultClient, u, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://example.com/", "http://example.com/about"}, urls)
+
+	u, _ = url.Parse(server.URL + "/missing.xml")
+	urls, err = Fetch(context.Background(), http.DefaultClient, u, 10)
+	assert.Nil(t, urls)
+	var se *StatusError
+	if assert.True(t, errors.As(err, &se)) {
+		assert.Equal(t, http.StatusNotFound, se.StatusCode)
+		assert.Equal(t, u.String(), se.URL)
+	}
+}
@@ -10,56 +10,110 @@ function stopWaitingModal() {
     });
 }
 
-function ajax() {
+function renderHeadings(headings) {
+    $('#headings-count-h1').html(headings.h1)
+    $('#headings-count-h2').html(headings.h2)
+    $('#headings-count-h3').html(headings.h3)
+    $('#headings-count-h4').html(headings.h4)
+    $('#headings-count-h5').html(headings.h5)
+    $('#headings-count-h6').html(headings.h6)
+}
+
+function renderLoginForm(hasLoginForm) {
+    let hasLoginFormMsg = "No"
+    if (hasLoginForm === true) {
+        hasLoginFormMsg = "Yes"
+    }
+    $('#has-login').html(hasLoginFormMsg)
+}
+
+function renderResult(result) {
+    $('#html-version').html(result.html_version)
+    $('#page-title').html(result.page_title)
+    renderHeadings(result.headings_count)
+    $('#external-links').html(result.links_count.external)
+    $('#internal-links').html(result.links_count.internal)
+    $('#inaccessible-links').html(result.inaccessible_links_count)
+    $('#robots-skipped-links').html(result.robots_skipped_links_count)
+    renderLoginForm(result.has_login_form)
+}
+
+function showAlert(success, message) {
+    $('#result_box').show()
+    let alert = $('#alert')
+    alert.show()
+    alert.removeClass(success ? "alert-danger" : "alert-success");
+    alert.addClass(success ? "alert-success" : "alert-danger");
+    $('#alert_message').html(message)
+}
+
+function clearResult() {
+    $('#result_table td strong').html("")
+}
+
+// stream analyzes the url by listening to the server-sent events of the analyze stream endpoint and renders
+// the outcome of each phase as soon as it's received.
+function stream() {
     let url = document.getElementById('url').value
-    let data = {};
-    data["url"] = url
-    $.ajax({
-        type: "POST",
-        url: "analyze-url",
-        data: JSON.stringify(data),
-        success: function (data) {
-            $('#html-version').html(data.result.html_version)
-            $('#page-title').html(data.result.page_title)
-            $('#headings-count-h1').html(data.result.headings_count.h1)
-            $('#headings-count-h2').html(data.result.headings_count.h2)
-            $('#headings-count-h3').html(data.result.headings_count.h3)
-            $('#headings-count-h4').html(data.result.headings_count.h4)
-            $('#headings-count-h5').html(data.result.headings_count.h5)
-            $('#headings-count-h6').html(data.result.headings_count.h6)
-            $('#external-links').html(data.result.links_count.external)
-            $('#internal-links').html(data.result.links_count.internal)
-            $('#inaccessible-links').html(data.result.inaccessible_links_count)
-            let hasLoginForm = data.result.has_login_form
-            let hasLoginFormMsg = "No"
-            if (hasLoginForm === true) {
-                hasLoginFormMsg = "Yes"
-            }
-            $('#has-login').html(hasLoginFormMsg)
-            $('#result_box').show();
-            let alert = $('#alert')
-            alert.show()
-            alert.removeClass("alert-danger");
-            alert.addClass("alert-success");
-            $('#alert_message').html("Success! Result for url: " + url)
-            $('#heading_result_table').show()
-            $('#result_table').show()
-            stopWaitingModal()
-        },
-        error: function (xhr, status, error) {
-            $('#result_box').show()
-            let alert = $('#alert')
-            alert.show()
-            alert.removeClass("alert-success");
-            alert.addClass("alert-danger");
-            $('#alert_message').html("Failed! " + xhr.responseJSON.error)
-            $('#heading_result_table').hide()
-            $('#result_table').hide()
-            stopWaitingModal()
-        },
-        dataType: "json",
-        contentType: "application/json"
-    });
+    let inaccessibleLinks = 0
+    let robotsSkippedLinks = 0
+    let source = new EventSource("api/v1/analyze-url/stream?url=" + encodeURIComponent(url))
+
+    clearResult()
+    showAlert(true, "Analyzing url: " + url)
+    $('#heading_result_table').show()
+    $('#result_table').show()
+
+    let on = function (name, handler) {
+        source.addEventListener(name, function (e) {
+            handler(JSON.parse(e.data))
+        })
+    }
+    on("fetched", function () {
+        showAlert(true, "Page fetched, analyzing url: " + url)
+    })
+    on("html_version", function (version) {
+        $('#html-version').html(version)
+    })
+    on("title", function (title) {
+        $('#page-title').html(title)
+    })
+    on("headings", renderHeadings)
+    on("links", function (links) {
+        $('#external-links').html(links.external)
+        $('#internal-links').html(links.internal)
+        $('#inaccessible-links').html("0 (checked 0 of " + (links.external + links.internal) + ")")
+        $('#robots-skipped-links').html(0)
+    })
+    on("link_checked", function (check) {
+        if (check.skipped === true) {
+            robotsSkippedLinks++
+            $('#robots-skipped-links').html(robotsSkippedLinks)
+        } else if (check.accessible !== true) {
+            inaccessibleLinks++
+        }
+        $('#inaccessible-links').html(inaccessibleLinks + " (checked " + check.checked + " of " + check.total + ")")
+    })
+    on("login_form", renderLoginForm)
+    on("result", function (data) {
+        source.close()
+        renderResult(data.result)
+        showAlert(true, "Success! Result for url: " + url)
+        stopWaitingModal()
+    })
+    on("failure", function (data) {
+        source.close()
+        showAlert(false, "Failed! " + data.error)
+        $('#heading_result_table').hide()
+        $('#result_table').hide()
+        stopWaitingModal()
+    })
+    // error is the built-in event of EventSource which is dispatched when the connection fails.
+    source.onerror = function () {
+        source.close()
+        showAlert(false, "Failed! connection to server was lost")
+        stopWaitingModal()
+    }
 }
 
 $(document).ready(function () {
@@ -77,7 +131,7 @@ $(document).ready(function () {
         form.children("input").each(function () {
             $(this).attr("readonly", true);
         });
-        ajax()
+        stream()
         setTimeout(stopWaitingModal, 30000);
     });
 });